@@ -1,9 +1,26 @@
 package bootstrap
 
 import (
+	"context"
+	"media-service/constants"
+	domain_blob "media-service/domain/blob"
+	domain_document "media-service/domain/document"
+	domain_heif "media-service/domain/heif"
+	"media-service/domain/pipeline"
+	domain_progress "media-service/domain/progress"
+	domain_svg "media-service/domain/svg"
 	"media-service/domain/usecase"
+	domain_video "media-service/domain/video"
+	"media-service/infrastructure/blob_store"
+	"media-service/infrastructure/document"
 	"media-service/infrastructure/grpc_service"
+	"media-service/infrastructure/heif"
+	"media-service/infrastructure/progress"
 	"media-service/infrastructure/repo"
+	"media-service/infrastructure/s3"
+	"media-service/infrastructure/svg"
+	"media-service/infrastructure/task_queue"
+	"media-service/infrastructure/video"
 
 	"github.com/anhvanhoa/sf-proto/gen/media/v1"
 
@@ -28,8 +45,10 @@ type App struct {
 	Logger        *log.LogGRPCImpl
 	GRPCServer    *grpc.Server
 	QueueClient   queue.QueueClient
+	TaskRedis     task_queue.RedisConfig
 	MediaUsecases usecase.MediaUsecaseInterfaces
 	Storage       storage.StorageI
+	Backends      *domain_blob.Registry
 	MediaServer   media.MediaServiceServer
 	Helper        utils.Helper
 	Cache         cache.CacheI
@@ -38,6 +57,7 @@ type App struct {
 func NewApp() *App {
 	env := &Env{}
 	NewEnv(env)
+	env.normalizeKeyReferences()
 
 	logConfig := log.NewConfig()
 	logger := log.InitLogGRPC(logConfig, zapcore.DebugLevel, env.IsProduction())
@@ -56,23 +76,65 @@ func NewApp() *App {
 		env.Queue.Retry,
 	))
 	mediaRepo := repo.NewMediaRepository(db)
+	variantRepo := repo.NewMediaVariantRepository(db)
+	reprocessJobRepo := repo.NewReprocessJobRepository(db)
+	pipelineRunRepo := repo.NewPipelineRunRepository(db)
+	trackRepo := repo.NewMediaTrackRepository(db)
+	clipJobRepo := repo.NewClipJobRepository(db)
+	processingJobRepo := repo.NewProcessingJobRepository(db)
+	taskRedis := task_queue.RedisConfig{
+		Addr:     env.Queue.Addr,
+		Network:  env.Queue.Network,
+		Password: env.Queue.Password,
+		DB:       env.Queue.Db,
+	}
 
-	storageService := storage.NewLocalStorageService(
-		env.StorageLocal.UploadDir,
-		logger,
-	)
+	backends := newStorage(env, logger)
+	lifecycleRules, rehydrate := newLifecycle(env, backends)
 
 	processingService := processing.NewMediaProcessingService(
-		storageService,
+		backends.Default().Storage,
 		queueClient,
 		logger,
 	)
 
+	// Custom pipeline steps are registered here; a step registered under the
+	// name of a built-in one replaces it
+	pipelineRegistry := pipeline.NewRegistry()
+
+	documentInspector, documentRenderer := newDocumentTools(env.Document)
+	svgRasterizer := newSvgRasterizer(env.Svg)
+	heifDecoder := newHeifDecoder(env.Heif)
+	videoProber, frameExtractor, videoClipper := newVideoTools(env.Video)
+
 	mediaUsecases := usecase.NewMediaUsecases(
 		mediaRepo,
+		variantRepo,
+		reprocessJobRepo,
+		pipelineRunRepo,
+		trackRepo,
+		clipJobRepo,
+		processingJobRepo,
+		repo.NewStorageMigrationRepository(db),
 		logger,
 		processingService,
-		storageService,
+		backends,
+		documentInspector,
+		documentRenderer,
+		svgRasterizer,
+		heifDecoder,
+		videoProber,
+		frameExtractor,
+		videoClipper,
+		env.Watermarks(),
+		task_queue.NewEnqueuer(taskRedis),
+		task_queue.NewInspector(taskRedis),
+		newProgressBroker(env),
+		pipelineRegistry,
+		env.PipelineConfigs(),
+		newSignedURLs(env),
+		lifecycleRules,
+		rehydrate,
 	)
 
 	helper := utils.NewHelper()
@@ -87,21 +149,164 @@ func NewApp() *App {
 	)
 	cache := cache.NewCache(configRedis)
 
-	mediaServiceServer := grpc_service.NewMediaServiceServer(mediaUsecases, logger)
+	mediaServiceServer := grpc_service.NewMediaServiceServer(mediaUsecases, logger, env.AdminUsers, authenticatedUser)
 
 	return &App{
 		Env:           env,
 		DB:            db,
 		Logger:        logger,
 		QueueClient:   queueClient,
+		TaskRedis:     taskRedis,
 		MediaUsecases: mediaUsecases,
-		Storage:       storageService,
+		Storage:       backends.Default().Storage,
+		Backends:      backends,
 		MediaServer:   mediaServiceServer,
 		Helper:        helper,
 		Cache:         cache,
 	}
 }
 
+// newStorage creates the registry of the configured storage backends. Without
+// storage.backends, the only backend is named after storage.provider and
+// keeps the local files in storage_local.upload_dir.
+func newStorage(env *Env, logger *log.LogGRPCImpl) *domain_blob.Registry {
+	config := env.Storage
+	if config == nil {
+		config = &Storage{}
+	}
+	backends := config.Backends
+	defaultName := config.Default
+	if len(backends) == 0 {
+		provider := config.Provider
+		if provider == "" {
+			provider = "local"
+		}
+		backend := &StorageBackend{Provider: provider, S3: config.S3}
+		if env.StorageLocal != nil {
+			backend.UploadDir = env.StorageLocal.UploadDir
+		}
+		backends = map[string]*StorageBackend{provider: backend}
+		defaultName = provider
+	}
+	if defaultName == "" && len(backends) == 1 {
+		for name := range backends {
+			defaultName = name
+		}
+	}
+
+	registered := make([]*domain_blob.Backend, 0, len(backends))
+	for name, backend := range backends {
+		registered = append(registered, newStorageBackend(name, backend, logger))
+	}
+	registry, err := domain_blob.NewRegistry(defaultName, registered...)
+	if err != nil {
+		panic("invalid storage config: " + err.Error())
+	}
+	return registry
+}
+
+// newStorageBackend creates the storage service of a backend, with the reader
+// and lister of the files it stores
+func newStorageBackend(name string, backend *StorageBackend, logger *log.LogGRPCImpl) *domain_blob.Backend {
+	tier := backend.Tier
+	if tier == "" {
+		tier = constants.DefaultStorageTier
+	}
+
+	switch backend.Provider {
+	case "", "local":
+		if backend.UploadDir == "" {
+			panic("upload_dir is required with the local provider of storage backend " + name)
+		}
+		return &domain_blob.Backend{
+			Name:    name,
+			Tier:    tier,
+			Storage: storage.NewLocalStorageService(backend.UploadDir, logger),
+			Reader:  blob_store.NewLocalReader(backend.UploadDir),
+			Lister:  blob_store.NewLocalLister(backend.UploadDir),
+			Files:   blob_store.NewLocalFileOpener(backend.UploadDir),
+		}
+	case "s3":
+	default:
+		panic("unsupported provider of storage backend " + name + ": " + backend.Provider)
+	}
+
+	config := backend.S3
+	if config == nil {
+		panic("s3 is required with the s3 provider of storage backend " + name)
+	}
+	client, err := s3.NewClient(s3.Config{
+		Endpoint:    config.Endpoint,
+		Region:      config.Region,
+		Bucket:      config.Bucket,
+		AccessKey:   config.AccessKey,
+		SecretKey:   config.SecretKey,
+		PathStyle:   config.PathStyle,
+		PublicURL:   config.PublicURL,
+		Prefix:      config.Prefix,
+		PartSize:    int64(config.PartSizeMB) * 1024 * 1024,
+		SSE:         config.SSE,
+		SSEKMSKeyID: config.SSEKMSKeyID,
+		MaxRetries:  config.MaxRetries,
+	})
+	if err != nil {
+		panic("invalid s3 config of storage backend " + name + ": " + err.Error())
+	}
+	return &domain_blob.Backend{
+		Name:    name,
+		Tier:    tier,
+		Storage: s3.NewStorageService(client),
+		Reader:  blob_store.NewS3Reader(client),
+		Lister:  blob_store.NewS3Lister(client),
+		Files:   blob_store.NewS3FileOpener(client),
+	}
+}
+
+func newDocumentTools(config *Document) (domain_document.Inspector, domain_document.Renderer) {
+	inspector := document.NewPopplerInspector()
+	if config == nil {
+		return inspector, document.NewPopplerRenderer(constants.DefaultPreviewDPI)
+	}
+	if config.Renderer == "none" {
+		return inspector, document.NewNoopRenderer()
+	}
+	dpi := config.PreviewDPI
+	if dpi <= 0 {
+		dpi = constants.DefaultPreviewDPI
+	}
+	return inspector, document.NewPopplerRenderer(dpi)
+}
+
+func newSvgRasterizer(config *Svg) domain_svg.Rasterizer {
+	if config != nil && config.Rasterizer == "none" {
+		return svg.NewNoopRasterizer()
+	}
+	return svg.NewRsvgRasterizer()
+}
+
+func newHeifDecoder(config *Heif) domain_heif.Decoder {
+	if config != nil && config.Decoder == "none" {
+		return heif.NewNoopDecoder()
+	}
+	return heif.NewLibheifDecoder()
+}
+
+func newVideoTools(config *Video) (domain_video.Prober, domain_video.FrameExtractor, domain_video.Clipper) {
+	if config != nil && config.Processor == "none" {
+		return video.NewNoopProber(), video.NewNoopExtractor(), video.NewNoopClipper()
+	}
+	return video.NewFFmpegProber(), video.NewFFmpegExtractor(), video.NewFFmpegClipper()
+}
+
+// newProgressBroker shares progress events through the queue Redis unless
+// the API and the worker run in one process
+func newProgressBroker(env *Env) domain_progress.Broker {
+	if env.Progress != nil && env.Progress.Broker == "local" {
+		return progress.NewLocalBroker()
+	}
+	return progress.NewRedisBroker(env.Queue.Addr, env.Queue.Network, env.Queue.Password, env.Queue.Db)
+}
+
 func (app *App) Start() *grpc_server.GRPCServer {
 	config := &grpc_server.GRPCServerConfig{
 		IsProduction: app.Env.IsProduction(),
@@ -138,3 +343,12 @@ func (app *App) Start() *grpc_server.GRPCServer {
 		),
 	)
 }
+
+// authenticatedUser is the ID of the user the authorization interceptor
+// loaded into the context of a request
+func authenticatedUser(ctx context.Context) string {
+	if uCtx := user_context.GetUserContext(ctx); uCtx != nil {
+		return uCtx.UserID
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package bootstrap
+
+import (
+	"media-service/constants"
+	"media-service/domain/urlsign"
+	"media-service/domain/usecase"
+	"media-service/infrastructure/delivery"
+	"time"
+)
+
+// NewDeliveryServer creates the HTTP server of the stored files, nil when
+// delivery.addr is not configured
+func (app *App) NewDeliveryServer() *delivery.Server {
+	config := app.Env.Delivery
+	if config == nil || config.Addr == "" {
+		return nil
+	}
+	return delivery.NewServer(app.MediaUsecases, app.Logger, delivery.Config{
+		Addr:            config.Addr,
+		PublicOriginals: config.PublicOriginals,
+		CacheControl:    config.CacheControl,
+		DefaultCache:    constants.DefaultDeliveryCacheControl,
+		RequireSigned:   config.RequireSigned,
+		TrustProxy:      config.TrustProxy,
+	})
+}
+
+// newSignedURLs builds the signer of the delivery URLs, nil when no signing
+// keys are configured
+func newSignedURLs(env *Env) *usecase.SignedURLConfig {
+	config := env.Delivery
+	if config == nil || config.Signing == nil || len(config.Signing.Keys) == 0 {
+		if config != nil && config.RequireSigned {
+			panic("delivery.require_signed_urls needs delivery.signing keys")
+		}
+		return nil
+	}
+	if config.PublicURL == "" {
+		panic("delivery.public_url is required to sign delivery URLs")
+	}
+
+	signing := config.Signing
+	keys := make([]urlsign.Key, 0, len(signing.Keys))
+	for id, secret := range signing.Keys {
+		keys = append(keys, urlsign.Key{ID: id, Secret: []byte(secret)})
+	}
+	signer, err := urlsign.NewSigner(signing.CurrentKey, keys...)
+	if err != nil {
+		panic("invalid delivery.signing: " + err.Error())
+	}
+
+	defaultTTL, maxTTL := signing.DefaultTTL, signing.MaxTTL
+	if defaultTTL <= 0 {
+		defaultTTL = constants.DefaultSignedURLTTL
+	}
+	if maxTTL <= 0 {
+		maxTTL = constants.MaxSignedURLTTL
+	}
+	if defaultTTL > maxTTL {
+		panic("delivery.signing.default_ttl exceeds max_ttl")
+	}
+	return &usecase.SignedURLConfig{
+		Signer:     signer,
+		BaseURL:    config.PublicURL,
+		DefaultTTL: time.Duration(defaultTTL) * time.Second,
+		MaxTTL:     time.Duration(maxTTL) * time.Second,
+	}
+}
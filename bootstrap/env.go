@@ -1,6 +1,8 @@
 package bootstrap
 
 import (
+	"media-service/domain/entity"
+	"media-service/domain/pipeline"
 	"strings"
 
 	"github.com/anhvanhoa/service-core/bootstrap/config"
@@ -11,6 +13,116 @@ type StorageLocal struct {
 	UploadDir string `mapstructure:"upload_dir"`
 }
 
+// Storage configures either the named backends files are stored in, or a
+// single backend named after its provider
+type Storage struct {
+	Provider string                     `mapstructure:"provider"` // local, s3; ignored when backends are configured
+	S3       *StorageS3                 `mapstructure:"s3"`
+	Default  string                     `mapstructure:"default"` // Backend new files are written to
+	Backends map[string]*StorageBackend `mapstructure:"backends"`
+}
+
+type StorageBackend struct {
+	Provider  string     `mapstructure:"provider"`   // local, s3
+	Tier      string     `mapstructure:"tier"`       // Storage class recorded on the media, standard by default
+	UploadDir string     `mapstructure:"upload_dir"` // Root of the local provider
+	S3        *StorageS3 `mapstructure:"s3"`
+}
+
+type StorageS3 struct {
+	Endpoint    string `mapstructure:"endpoint"` // Empty is AWS in region
+	Region      string `mapstructure:"region"`
+	Bucket      string `mapstructure:"bucket"`
+	AccessKey   string `mapstructure:"access_key"`
+	SecretKey   string `mapstructure:"secret_key"`
+	PathStyle   bool   `mapstructure:"path_style"` // Required by MinIO
+	PublicURL   string `mapstructure:"public_url"` // Base of media URLs, defaults to the bucket URL
+	Prefix      string `mapstructure:"prefix"`
+	PartSizeMB  int    `mapstructure:"part_size_mb"` // Multipart upload part size, 8 by default
+	SSE         string `mapstructure:"sse"`          // AES256, aws:kms
+	SSEKMSKeyID string `mapstructure:"sse_kms_key_id"`
+	MaxRetries  int    `mapstructure:"max_retries"`
+}
+
+type Document struct {
+	Renderer   string `mapstructure:"renderer"` // pdftoppm, none
+	PreviewDPI int    `mapstructure:"preview_dpi"`
+}
+
+type Svg struct {
+	Rasterizer string `mapstructure:"rasterizer"` // rsvg-convert, none
+}
+
+type Heif struct {
+	Decoder string `mapstructure:"decoder"` // heif-dec, none
+}
+
+type Video struct {
+	Processor string `mapstructure:"processor"` // ffmpeg, none
+}
+
+type Progress struct {
+	Broker string `mapstructure:"broker"` // redis, local
+}
+
+type Delivery struct {
+	Addr            string            `mapstructure:"addr"`                // Serves media over HTTP when set
+	PublicOriginals bool              `mapstructure:"public_originals"`    // Serve originals, not only their renditions
+	CacheControl    map[string]string `mapstructure:"cache_control"`       // By media type, track or default
+	RequireSigned   bool              `mapstructure:"require_signed_urls"` // Refuse requests without a signed URL
+	TrustProxy      bool              `mapstructure:"trust_proxy"`         // Bind signed URLs to the X-Forwarded-For client
+	PublicURL       string            `mapstructure:"public_url"`          // Base of the signed URLs
+	Signing         *Signing          `mapstructure:"signing"`
+}
+
+// Signing holds the keys of the signed delivery URLs by ID; URLs are signed
+// with the current key and verified with any listed one
+type Signing struct {
+	CurrentKey string            `mapstructure:"current_key"`
+	Keys       map[string]string `mapstructure:"keys"`
+	DefaultTTL int               `mapstructure:"default_ttl"` // Seconds
+	MaxTTL     int               `mapstructure:"max_ttl"`     // Seconds
+}
+
+type Lifecycle struct {
+	Interval  int              `mapstructure:"interval"`  // Seconds between sweeps, negative disables them
+	Rehydrate string           `mapstructure:"rehydrate"` // off, async: delivering media of a colder tier queues their move back
+	Rules     []*LifecycleRule `mapstructure:"rules"`
+}
+
+type LifecycleRule struct {
+	Name   string   `mapstructure:"name"`
+	From   []string `mapstructure:"from"` // Backends media leave, the default one when empty
+	To     string   `mapstructure:"to"`
+	Types  []string `mapstructure:"types"`   // Media types, any when empty
+	MinAge int      `mapstructure:"min_age"` // Seconds since the upload
+	Idle   int      `mapstructure:"idle"`    // Seconds since the last delivery
+}
+
+type Worker struct {
+	MetricsAddr    string `mapstructure:"metrics_addr"`    // Serves expvar metrics on /debug/vars when set
+	ReaperInterval int    `mapstructure:"reaper_interval"` // Seconds between stuck media sweeps, negative disables them
+	ReaperTimeout  int    `mapstructure:"reaper_timeout"`  // Seconds in pending or processing before media are stuck
+}
+
+type WatermarkProfile struct {
+	Name           string   `mapstructure:"name"`
+	OverlayMediaID string   `mapstructure:"overlay_media_id"`
+	Position       string   `mapstructure:"position"`
+	Opacity        float64  `mapstructure:"opacity"`
+	Scale          float64  `mapstructure:"scale"`
+	Tile           bool     `mapstructure:"tile"`
+	Margin         int      `mapstructure:"margin"`
+	Variants       []string `mapstructure:"variants"`
+}
+
+type PipelineStep struct {
+	Step     string            `mapstructure:"step"`
+	After    []string          `mapstructure:"after"`
+	Optional bool              `mapstructure:"optional"`
+	Params   map[string]string `mapstructure:"params"`
+}
+
 type QueueRedis struct {
 	Addr     string `mapstructure:"addr"`
 	Db       int    `mapstructure:"db"`
@@ -19,6 +131,16 @@ type QueueRedis struct {
 	Timeout  int    `mapstructure:"timeout"`
 	Tls      bool   `mapstructure:"tls"`
 	Retry    int    `mapstructure:"retry"`
+	// Concurrency is the number of tasks processed at once by this instance
+	Concurrency int `mapstructure:"concurrency"`
+	// Workers overrides Concurrency per queue name; media_bulk shares the
+	// concurrency of media_processing
+	Workers map[string]int `mapstructure:"workers"`
+	// Weights sets how often media_processing and media_bulk are polled
+	Weights map[string]int `mapstructure:"weights"`
+	// OwnerShare is the fraction of the processing concurrency one owner can
+	// use; 1 disables the limit
+	OwnerShare float64 `mapstructure:"owner_share"`
 }
 type dbCache struct {
 	Addr        string `mapstructure:"addr"`
@@ -31,20 +153,32 @@ type dbCache struct {
 }
 
 type Env struct {
-	NodeEnv               string                    `mapstructure:"node_env"`
-	AccessSecret          string                    `mapstructure:"access_secret"`
-	SecretService         string                    `mapstructure:"secret_service"`
-	UrlDb                 string                    `mapstructure:"url_db"`
-	NameService           string                    `mapstructure:"name_service"`
-	PortGrpc              int                       `mapstructure:"port_grpc"`
-	HostGrpc              string                    `mapstructure:"host_grpc"`
-	IntervalCheck         string                    `mapstructure:"interval_check"`
-	TimeoutCheck          string                    `mapstructure:"timeout_check"`
-	Queue                 *QueueRedis               `mapstructure:"queue"`
-	StorageLocal          *StorageLocal             `mapstructure:"storage_local"`
-	GrpcClients           []*grpc_client.ConfigGrpc `mapstructure:"grpc_clients"`
-	PermissionServiceAddr string                    `mapstructure:"permission_service_addr"`
-	DbCache               *dbCache                  `mapstructure:"db_cache"`
+	NodeEnv               string                     `mapstructure:"node_env"`
+	AccessSecret          string                     `mapstructure:"access_secret"`
+	SecretService         string                     `mapstructure:"secret_service"`
+	UrlDb                 string                     `mapstructure:"url_db"`
+	NameService           string                     `mapstructure:"name_service"`
+	PortGrpc              int                        `mapstructure:"port_grpc"`
+	HostGrpc              string                     `mapstructure:"host_grpc"`
+	IntervalCheck         string                     `mapstructure:"interval_check"`
+	TimeoutCheck          string                     `mapstructure:"timeout_check"`
+	Queue                 *QueueRedis                `mapstructure:"queue"`
+	StorageLocal          *StorageLocal              `mapstructure:"storage_local"`
+	Storage               *Storage                   `mapstructure:"storage"`
+	GrpcClients           []*grpc_client.ConfigGrpc  `mapstructure:"grpc_clients"`
+	PermissionServiceAddr string                     `mapstructure:"permission_service_addr"`
+	DbCache               *dbCache                   `mapstructure:"db_cache"`
+	AdminUsers            []string                   `mapstructure:"admin_users"`
+	WatermarkProfiles     []*WatermarkProfile        `mapstructure:"watermark_profiles"`
+	Document              *Document                  `mapstructure:"document"`
+	Svg                   *Svg                       `mapstructure:"svg"`
+	Heif                  *Heif                      `mapstructure:"heif"`
+	Video                 *Video                     `mapstructure:"video"`
+	Worker                *Worker                    `mapstructure:"worker"`
+	Progress              *Progress                  `mapstructure:"progress"`
+	Delivery              *Delivery                  `mapstructure:"delivery"`
+	Lifecycle             *Lifecycle                 `mapstructure:"lifecycle"`
+	Pipelines             map[string][]*PipelineStep `mapstructure:"pipelines"`
 }
 
 func NewEnv(env any) {
@@ -58,6 +192,58 @@ func NewEnv(env any) {
 	config.NewConfig(setting, env)
 }
 
+// normalizeKeyReferences lowercases the settings naming a key of another
+// setting's map: viper lowercases map keys, so storage backends and signing
+// keys are only found under their lowercased names
+func (env *Env) normalizeKeyReferences() {
+	if env.Storage != nil {
+		env.Storage.Default = strings.ToLower(env.Storage.Default)
+	}
+	if env.Lifecycle != nil {
+		for _, rule := range env.Lifecycle.Rules {
+			rule.To = strings.ToLower(rule.To)
+			for i, from := range rule.From {
+				rule.From[i] = strings.ToLower(from)
+			}
+		}
+	}
+	if env.Delivery != nil && env.Delivery.Signing != nil {
+		env.Delivery.Signing.CurrentKey = strings.ToLower(env.Delivery.Signing.CurrentKey)
+	}
+}
+
 func (env *Env) IsProduction() bool {
 	return strings.ToLower(env.NodeEnv) == "production"
 }
+
+func (env *Env) Watermarks() []*entity.WatermarkProfile {
+	profiles := make([]*entity.WatermarkProfile, 0, len(env.WatermarkProfiles))
+	for _, p := range env.WatermarkProfiles {
+		profiles = append(profiles, &entity.WatermarkProfile{
+			Name:           p.Name,
+			OverlayMediaID: p.OverlayMediaID,
+			Position:       p.Position,
+			Opacity:        p.Opacity,
+			Scale:          p.Scale,
+			Tile:           p.Tile,
+			Margin:         p.Margin,
+			Variants:       p.Variants,
+		})
+	}
+	return profiles
+}
+
+func (env *Env) PipelineConfigs() map[string][]pipeline.StepConfig {
+	configs := make(map[string][]pipeline.StepConfig, len(env.Pipelines))
+	for family, steps := range env.Pipelines {
+		for _, s := range steps {
+			configs[family] = append(configs[family], pipeline.StepConfig{
+				Name:     s.Step,
+				After:    s.After,
+				Optional: s.Optional,
+				Params:   s.Params,
+			})
+		}
+	}
+	return configs
+}
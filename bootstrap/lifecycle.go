@@ -0,0 +1,108 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	domain_blob "media-service/domain/blob"
+	"media-service/domain/entity"
+	"slices"
+	"time"
+)
+
+// newLifecycle validates the lifecycle rules against the storage backends.
+// Rehydrated media are back in the default backend, which rules leave by
+// default, so rules need an idle time when media are rehydrated or they
+// would be moved out again by the next sweep.
+func newLifecycle(env *Env, backends *domain_blob.Registry) ([]*entity.LifecycleRule, entity.RehydrateMode) {
+	config := env.Lifecycle
+	if config == nil {
+		return nil, entity.RehydrateOff
+	}
+	rehydrate, err := entity.ParseRehydrateMode(config.Rehydrate)
+	if err != nil {
+		panic("invalid lifecycle.rehydrate: " + err.Error())
+	}
+
+	rules := make([]*entity.LifecycleRule, 0, len(config.Rules))
+	for _, r := range config.Rules {
+		rule := &entity.LifecycleRule{
+			Name:   r.Name,
+			From:   r.From,
+			To:     r.To,
+			MinAge: time.Duration(r.MinAge) * time.Second,
+			Idle:   time.Duration(r.Idle) * time.Second,
+		}
+		if len(rule.From) == 0 {
+			rule.From = []string{backends.Default().Name}
+		}
+		for _, t := range r.Types {
+			rule.Types = append(rule.Types, entity.MediaType(t))
+		}
+		if err := validateLifecycleRule(rule, backends, rehydrate); err != nil {
+			panic(fmt.Sprintf("invalid lifecycle rule %q: %v", r.Name, err))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rehydrate
+}
+
+func validateLifecycleRule(rule *entity.LifecycleRule, backends *domain_blob.Registry, rehydrate entity.RehydrateMode) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if rule.To == "" {
+		return fmt.Errorf("to is required")
+	}
+	for _, name := range append([]string{rule.To}, rule.From...) {
+		if _, err := backends.Get(name); err != nil {
+			return err
+		}
+	}
+	if slices.Contains(rule.From, rule.To) {
+		return fmt.Errorf("media cannot be moved from %s to itself", rule.To)
+	}
+	for _, t := range rule.Types {
+		switch t {
+		case entity.MediaTypeImage, entity.MediaTypeVideo, entity.MediaTypeAudio, entity.MediaTypeDocument, entity.MediaTypeOther:
+		default:
+			return fmt.Errorf("unknown media type: %s", t)
+		}
+	}
+	if rule.MinAge <= 0 && rule.Idle <= 0 {
+		return fmt.Errorf("min_age or idle is required")
+	}
+	if rehydrate != entity.RehydrateOff && rule.Idle <= 0 && slices.Contains(rule.From, backends.Default().Name) {
+		return fmt.Errorf("idle is required to move media out of the default backend when rehydrating")
+	}
+	return nil
+}
+
+// RunStorageLifecycle applies the lifecycle rules periodically until ctx is done
+func (app *App) RunStorageLifecycle(ctx context.Context) {
+	config := app.Env.Lifecycle
+	if config == nil || len(config.Rules) == 0 || config.Interval < 0 {
+		return
+	}
+	interval := time.Duration(constants.DefaultLifecycleInterval) * time.Second
+	if config.Interval > 0 {
+		interval = time.Duration(config.Interval) * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := app.MediaUsecases.ApplyStorageLifecycle(ctx)
+		if err != nil {
+			app.Logger.Error(fmt.Sprintf("Storage lifecycle sweep failed: %v", err))
+		} else if result.Moved > 0 || result.Failed > 0 {
+			app.Logger.Info(fmt.Sprintf("Storage lifecycle sweep: %d media moved, %d failed", result.Moved, result.Failed))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/infrastructure/metrics"
+	"time"
+)
+
+// RunReaper sweeps stuck media periodically until ctx is done
+func (app *App) RunReaper(ctx context.Context) {
+	interval := time.Duration(constants.DefaultReaperInterval) * time.Second
+	timeout := time.Duration(constants.DefaultReaperTimeout) * time.Second
+	if config := app.Env.Worker; config != nil {
+		if config.ReaperInterval < 0 {
+			return
+		}
+		if config.ReaperInterval > 0 {
+			interval = time.Duration(config.ReaperInterval) * time.Second
+		}
+		if config.ReaperTimeout > 0 {
+			timeout = time.Duration(config.ReaperTimeout) * time.Second
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := app.MediaUsecases.ReapStuckMedia(ctx, timeout)
+		if err != nil {
+			app.Logger.Error(fmt.Sprintf("Stuck media sweep failed: %v", err))
+			metrics.RecordReap(0, 0, 0, 0, err)
+		} else {
+			metrics.RecordReap(result.Found, result.Requeued, result.Failed, result.Errors, nil)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
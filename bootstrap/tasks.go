@@ -0,0 +1,93 @@
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/task"
+	"media-service/infrastructure/task_queue"
+)
+
+// NewTaskServers creates the consumers of the background tasks enqueued by
+// the usecases: one for the weighted interactive and bulk processing queues,
+// where each owner is limited to its share, and one for the cleanup queue
+func (app *App) NewTaskServers() []*task_queue.Server {
+	concurrency := app.queueConcurrency(constants.QueueMediaProcessing)
+	processing := task_queue.NewServer(app.TaskRedis, concurrency, map[string]int{
+		constants.QueueMediaProcessing: app.queueWeight(constants.QueueMediaProcessing, constants.DefaultInteractiveWeight),
+		constants.QueueMediaBulk:       app.queueWeight(constants.QueueMediaBulk, constants.DefaultBulkWeight),
+	}, app.ownerLimit(concurrency))
+	cleanup := task_queue.NewServer(app.TaskRedis, app.queueConcurrency(constants.QueueMediaCleanup), map[string]int{
+		constants.QueueMediaCleanup: 1,
+	}, 0)
+
+	servers := []*task_queue.Server{processing, cleanup}
+	for _, server := range servers {
+		app.registerTaskHandlers(server)
+	}
+	return servers
+}
+
+// registerTaskHandlers registers every task type; a server only receives the
+// types enqueued on its queue
+func (app *App) registerTaskHandlers(server *task_queue.Server) {
+	server.Handle(constants.JobTypeMediaProcess, app.mediaTaskHandler(constants.JobTypeMediaProcess))
+	for _, taskType := range []string{
+		constants.JobTypeImageResize,
+		constants.JobTypeImageConvert,
+		constants.JobTypeCreateThumbnail,
+		constants.JobTypeVideoTranscode,
+	} {
+		server.Handle(taskType, unsupportedTaskHandler(taskType))
+	}
+	server.Handle(constants.JobTypeCleanupFiles, app.MediaUsecases.CleanupFiles)
+	server.Handle(constants.JobTypeMediaReprocess, app.MediaUsecases.RunReprocessTask)
+	server.Handle(constants.JobTypeVideoClip, app.MediaUsecases.RunClipTask)
+	server.Handle(constants.JobTypeStorageMigrate, app.MediaUsecases.RunStorageMigrationTask)
+	server.Handle(constants.JobTypeStorageRestore, app.MediaUsecases.RunStorageRestoreTask)
+	server.Handle(constants.JobTypeMediaVariants, app.MediaUsecases.RunVariantsTask)
+}
+
+// mediaTaskHandler processes a media, recording the task type as the trigger of the attempt
+func (app *App) mediaTaskHandler(taskType string) task.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return app.MediaUsecases.RunMediaTask(ctx, taskType, payload)
+	}
+}
+
+// unsupportedTaskHandler archives the tasks of a type the worker cannot
+// perform, rather than passing them off as media_process
+func unsupportedTaskHandler(taskType string) task.Handler {
+	return func(ctx context.Context, payload []byte) error {
+		return fmt.Errorf("%s tasks are not supported, enqueue media_process instead: %w", taskType, task.ErrSkipRetry)
+	}
+}
+
+func (app *App) queueWeight(queue string, fallback int) int {
+	if weight := app.Env.Queue.Weights[queue]; weight > 0 {
+		return weight
+	}
+	return fallback
+}
+
+// ownerLimit is the number of tasks one owner can run at once, 0 for no limit
+func (app *App) ownerLimit(concurrency int) int {
+	share := app.Env.Queue.OwnerShare
+	if share <= 0 {
+		share = constants.DefaultOwnerShare
+	}
+	if share >= 1 {
+		return 0
+	}
+	return max(1, int(share*float64(concurrency)))
+}
+
+func (app *App) queueConcurrency(queue string) int {
+	if concurrency := app.Env.Queue.Workers[queue]; concurrency > 0 {
+		return concurrency
+	}
+	if app.Env.Queue.Concurrency > 0 {
+		return app.Env.Queue.Concurrency
+	}
+	return constants.DefaultTaskConcurrency
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+	"log"
+	"media-service/bootstrap"
+)
+
+// Flags image media whose stored file still carries a non-upright EXIF
+// orientation, as uploaded before orientation was applied on conversion.
+// Flagged media can then be fixed by re-uploading; ReprocessMedia already
+// renders their variants upright.
+func main() {
+	app := bootstrap.NewApp()
+	flagged, err := app.MediaUsecases.DetectRotatedMedia(context.Background())
+	if err != nil {
+		log.Fatal("Failed to detect rotated media: " + err.Error())
+	}
+	log.Printf("%d media flagged as misoriented", flagged)
+}
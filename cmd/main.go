@@ -17,6 +17,18 @@ func main() {
 	grpcServer := app.Start()
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
+	go func() {
+		if _, err := app.MediaUsecases.SyncWatermarks(ctx); err != nil {
+			log.Println("Failed to sync watermarks: " + err.Error())
+		}
+	}()
+	if deliveryServer := app.NewDeliveryServer(); deliveryServer != nil {
+		go func() {
+			if err := deliveryServer.Serve(ctx); err != nil {
+				log.Fatal("Delivery server error: " + err.Error())
+			}
+		}()
+	}
 	permissions := app.Helper.ConvertResourcesToPermissions(grpcServer.GetResources())
 	if _, err := permissionClient.PermissionServiceClient.RegisterPermission(ctx, permissions); err != nil {
 		log.Fatal("Failed to register permission: " + err.Error())
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"media-service/bootstrap"
+	"media-service/domain/usecase"
+)
+
+// Reports the stored files no media, variant or track references, and the
+// rows whose file is missing. Nothing is deleted unless -delete is given: the
+// orphan files older than -grace are then deleted by cleanup_files tasks on
+// the media_cleanup queue, so cmd/worker must be running. Rows without a file
+// are only reported.
+func main() {
+	remove := flag.Bool("delete", false, "enqueue the deletion of orphan files")
+	grace := flag.Duration("grace", 0, "ignore files and rows younger than this (default 24h)")
+	flag.Parse()
+
+	app := bootstrap.NewApp()
+	report, err := app.MediaUsecases.ReconcileStorage(context.Background(), &usecase.ReconcileStorageRequest{
+		Delete:      *remove,
+		GracePeriod: *grace,
+	})
+	if err != nil {
+		log.Fatal("Failed to reconcile storage: " + err.Error())
+	}
+
+	for _, obj := range report.OrphanFiles {
+		log.Printf("orphan file: %s in %s (%d bytes, modified %s)", obj.Key, obj.Backend, obj.Size, obj.ModTime.Format("2006-01-02 15:04:05"))
+	}
+	for _, ref := range report.MissingFiles {
+		log.Printf("missing file: %s %s of media %s: %s in %s", ref.Source, ref.RowID, ref.MediaID, ref.URL, ref.Backend)
+	}
+	log.Printf("%d files and %d references scanned: %d orphan files, %d missing files", report.Files, report.References, len(report.OrphanFiles), len(report.MissingFiles))
+	if *remove {
+		log.Printf("%d orphan files enqueued for deletion", report.Deleted)
+	} else if len(report.OrphanFiles) > 0 {
+		log.Print("Dry run: rerun with -delete to delete the orphan files")
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"log"
+	"media-service/bootstrap"
+	"media-service/infrastructure/metrics"
+	"os/signal"
+	"syscall"
+)
+
+// Consumes the background tasks of the media queues and sweeps stuck media
+// until interrupted, then waits for the active tasks to finish.
+func main() {
+	app := bootstrap.NewApp()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	servers := app.NewTaskServers()
+	for _, server := range servers {
+		if err := server.Start(); err != nil {
+			log.Fatal("Failed to start task server: " + err.Error())
+		}
+	}
+	log.Printf("Worker consuming %d queues", len(servers))
+
+	go app.RunReaper(ctx)
+	go app.RunStorageLifecycle(ctx)
+	if config := app.Env.Worker; config != nil && config.MetricsAddr != "" {
+		go func() {
+			if err := metrics.Serve(ctx, config.MetricsAddr); err != nil {
+				log.Println("Metrics server error: " + err.Error())
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	log.Println("Shutting down worker")
+	for _, server := range servers {
+		server.Shutdown()
+	}
+}
@@ -20,16 +20,101 @@ const (
 	DefaultImageQuality = 85
 	MaxVideoDuration    = 1800 // 30 minutes
 
+	// Documents
+	MaxDocumentTextLength = 1024 * 1024 // 1MB of extracted text
+	DefaultPreviewDPI     = 72
+
+	// Text tracks
+	MaxTrackFileSize = 2 * 1024 * 1024 // 2MB of SRT or WebVTT
+	MaxTrackLabel    = 255
+
+	// Color palette
+	PaletteSize          = 5
+	DefaultColorDistance = 10.0 // CIEDE2000, ~ clearly similar colors
+	MaxColorDistance     = 100.0
+
+	// Near-duplicate search (Hamming distance between 64-bit perceptual hashes)
+	DefaultSimilarDistance = 10
+	MaxSimilarDistance     = 24
+	MaxProbePixels         = 50_000_000 // Largest probe image decoded by FindSimilarMedia
+
+	// Reprocessing
+	MaxReprocessMedia = 10000 // media matched by one ReprocessMedia call
+
+	// Processing retries, with a delay doubling from the base up to the max
+	MaxProcessingAttempts    = 5
+	ProcessingRetryBaseDelay = 30   // Seconds
+	ProcessingRetryMaxDelay  = 1800 // Seconds
+
+	// Dead task administration
+	MaxDeadTasks = 10000 // archived tasks scanned per call
+
+	// Stuck media reaper; the timeout must exceed the max retry delay so
+	// scheduled retries are not requeued
+	DefaultReaperInterval = 300  // Seconds between sweeps
+	DefaultReaperTimeout  = 3600 // Seconds in pending or processing before media are stuck
+	ReaperBatchSize       = 100  // Media handled per sweep
+
+	// Storage reconciliation; files and rows younger than the grace period may
+	// belong to uploads in flight and are left alone
+	DefaultOrphanGracePeriod = 86400 // Seconds
+	MinOrphanGracePeriod     = 3600  // Seconds, when deleting
+	ReconcilePageSize        = 1000  // File references read per query
+	CleanupBatchSize         = 100   // Files deleted per cleanup_files task
+
+	// Storage migrations copy the files of a batch of media per task; a
+	// running migration unchanged for the stale period may be resumed
+	StorageMigrationBatchSize   = 20
+	StorageMigrationStalePeriod = 900 // Seconds
+
+	// Storage tiers; the lifecycle sweep moves a batch of media per rule, a
+	// restore unchanged for the stale period may be started again, and the
+	// last access of a media is recorded at most once per touch interval
+	DefaultStorageTier       = "standard"
+	DefaultLifecycleInterval = 3600 // Seconds
+	LifecycleBatchSize       = 50
+	RestoreStalePeriod       = 900  // Seconds
+	RestoreRetryAfter        = 60   // Seconds, suggested to clients of restoring media
+	AccessTouchInterval      = 3600 // Seconds
+
+	// HTTP delivery; clients revalidate with the ETag once the age is reached
+	DefaultDeliveryCacheControl = "public, max-age=3600"
+
+	// Signed delivery URLs
+	DefaultSignedURLTTL = 3600   // Seconds
+	MaxSignedURLTTL     = 604800 // Seconds
+
+	// Processing progress streams
+	MaxWatchedMedia = 50 // media followed by one WatchProcessing call
+
 	// Thumbnail sizes
 	ThumbnailSmall  = "small"
 	ThumbnailMedium = "medium"
 	ThumbnailLarge  = "large"
 
+	// Thumbnail dimensions (square, in pixels)
+	ThumbnailSmallSize  = 150
+	ThumbnailMediumSize = 300
+	ThumbnailLargeSize  = 600
+
+	// Preview variant bounds (fit, not cropped)
+	PreviewMaxSize = 1200
+
 	// Image formats
 	FormatWebP = "webp"
 	FormatJPEG = "jpeg"
 	FormatPNG  = "png"
 
+	// Video scrubbing sprites
+	PosterFrameOffset = 1.0 // Seconds into the video of the poster frame
+	SpriteInterval    = 5.0 // Seconds between sampled frames
+	SpriteTileWidth   = 160
+	SpriteColumns     = 10
+	SpriteRows        = 10
+
+	// Video clips
+	MinClipDuration = 0.5 // Seconds
+
 	// Video formats
 	FormatMP4  = "mp4"
 	FormatWebM = "webm"
@@ -37,11 +122,31 @@ const (
 	// Queue names
 	QueueMediaProcessing = "media_processing"
 	QueueMediaCleanup    = "media_cleanup"
+	QueueMediaBulk       = "media_bulk" // Processing of bulk priority media
+
+	// Task consumers
+	DefaultTaskConcurrency = 4
+
+	// Weighted processing queues: the worker takes interactive tasks six
+	// times as often as bulk ones, and one owner holds at most a share of
+	// the processing concurrency
+	DefaultInteractiveWeight = 6
+	DefaultBulkWeight        = 1
+	DefaultOwnerShare        = 0.5
 
-	// Job types
+	// Job types. media_process renders the variants of a media, and the
+	// sprites of videos. The worker cannot perform image_resize,
+	// image_convert, create_thumbnail or video_transcode on their own (there
+	// is no transcoder) and archives their tasks with an error.
 	JobTypeImageResize     = "image_resize"
 	JobTypeImageConvert    = "image_convert"
 	JobTypeVideoTranscode  = "video_transcode"
 	JobTypeCreateThumbnail = "create_thumbnail"
 	JobTypeCleanupFiles    = "cleanup_files"
+	JobTypeMediaProcess    = "media_process"
+	JobTypeMediaReprocess  = "media_reprocess"
+	JobTypeVideoClip       = "video_clip"
+	JobTypeStorageMigrate  = "storage_migrate"
+	JobTypeStorageRestore  = "storage_restore"
+	JobTypeMediaVariants   = "media_variants" // Regenerates the crop-based variants after a focal point move
 )
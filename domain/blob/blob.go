@@ -0,0 +1,41 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned for files missing from their backend
+var ErrNotFound = errors.New("stored file not found")
+
+// Reader opens files previously written through storage.StorageI,
+// addressed by the URL the upload returned
+type Reader interface {
+	Open(ctx context.Context, url string) (io.ReadCloser, error)
+}
+
+// Object is a stored file
+type Object struct {
+	Key     string // Path relative to the storage root, shared by every URL of the file and usable as the output path of an upload
+	URL     string // URL the file is deleted by through storage.StorageI
+	Backend string // Name of the backend the file is stored in
+	Size    int64
+	ModTime time.Time
+}
+
+// Lister enumerates the stored files
+type Lister interface {
+	// List calls fn for every stored file, stopping at the first error
+	List(ctx context.Context, fn func(*Object) error) error
+	// Key returns the key of the file a stored URL addresses
+	Key(url string) (string, error)
+}
+
+// FileOpener opens stored files for random access, as ranged delivery needs
+type FileOpener interface {
+	// OpenFile opens the file a URL addresses, with its size and modification
+	// time; it returns ErrNotFound for a missing file
+	OpenFile(ctx context.Context, url string) (io.ReadSeekCloser, *Object, error)
+}
@@ -0,0 +1,115 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/anhvanhoa/service-core/domain/storage"
+)
+
+// Backend is a named place files are stored in
+type Backend struct {
+	Name    string
+	Tier    string // Storage class of the backend, such as hot or archive
+	Storage storage.StorageI
+	Reader  Reader
+	Lister  Lister
+	Files   FileOpener
+}
+
+// Registry routes file operations to the backend recorded on each row; new
+// files are written to the default backend
+type Registry struct {
+	backends    map[string]*Backend
+	defaultName string
+}
+
+// NewRegistry creates a registry of the given backends
+func NewRegistry(defaultName string, backends ...*Backend) (*Registry, error) {
+	r := &Registry{backends: make(map[string]*Backend, len(backends)), defaultName: defaultName}
+	for _, backend := range backends {
+		if backend.Name == "" {
+			return nil, fmt.Errorf("storage backend name is required")
+		}
+		if _, ok := r.backends[backend.Name]; ok {
+			return nil, fmt.Errorf("duplicate storage backend: %s", backend.Name)
+		}
+		r.backends[backend.Name] = backend
+	}
+	if _, ok := r.backends[defaultName]; !ok {
+		return nil, fmt.Errorf("default storage backend %s is not configured", defaultName)
+	}
+	return r, nil
+}
+
+// Default is the backend new files are written to
+func (r *Registry) Default() *Backend {
+	return r.backends[r.defaultName]
+}
+
+// Get returns a backend by name; an empty name is the default backend
+func (r *Registry) Get(name string) (*Backend, error) {
+	if name == "" {
+		return r.Default(), nil
+	}
+	backend, ok := r.backends[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown storage backend: %s", name)
+	}
+	return backend, nil
+}
+
+// Tier returns the tier of a backend, empty for an unknown one
+func (r *Registry) Tier(name string) string {
+	backend, err := r.Get(name)
+	if err != nil {
+		return ""
+	}
+	return backend.Tier
+}
+
+// Names lists the configured backends
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.backends))
+	for name := range r.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Upload writes a file to the default backend, returning its name and the URL
+func (r *Registry) Upload(ctx context.Context, req *storage.UploadRequest) (string, string, error) {
+	backend := r.Default()
+	url, err := backend.Storage.Upload(ctx, req)
+	return backend.Name, url, err
+}
+
+// Open reads a file of the named backend
+func (r *Registry) Open(ctx context.Context, name, url string) (io.ReadCloser, error) {
+	backend, err := r.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	return backend.Reader.Open(ctx, url)
+}
+
+// OpenFile opens a file of the named backend for random access
+func (r *Registry) OpenFile(ctx context.Context, name, url string) (io.ReadSeekCloser, *Object, error) {
+	backend, err := r.Get(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return backend.Files.OpenFile(ctx, url)
+}
+
+// Delete deletes a file of the named backend
+func (r *Registry) Delete(ctx context.Context, name, url string) error {
+	backend, err := r.Get(name)
+	if err != nil {
+		return err
+	}
+	return backend.Storage.Delete(ctx, url)
+}
@@ -0,0 +1,42 @@
+package document
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"io"
+)
+
+// pdfSniffLength is how far into a file the %PDF- header may appear
+const pdfSniffLength = 1024
+
+// Info is the metadata extracted from a document
+type Info struct {
+	PageCount int
+	Title     string
+	Author    string
+}
+
+// Inspector validates documents and extracts their metadata and text
+type Inspector interface {
+	Inspect(ctx context.Context, path string) (*Info, error)
+	ExtractText(ctx context.Context, path string) (string, error)
+}
+
+// Renderer renders a page (1-based) of a document to an image
+type Renderer interface {
+	RenderPage(ctx context.Context, path string, page int) (image.Image, error)
+}
+
+// IsPDF sniffs the start of the file for a PDF header and rewinds it
+func IsPDF(r io.ReadSeeker) (bool, error) {
+	header := make([]byte, pdfSniffLength)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return bytes.Contains(header[:n], []byte("%PDF-")), nil
+}
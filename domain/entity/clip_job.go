@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"time"
+)
+
+type ClipJobStatus string
+
+const (
+	ClipJobStatusQueued    ClipJobStatus = "queued"
+	ClipJobStatusRunning   ClipJobStatus = "running"
+	ClipJobStatusCompleted ClipJobStatus = "completed"
+	ClipJobStatusFailed    ClipJobStatus = "failed"
+)
+
+// Clip modes
+const (
+	// ClipModeAccurate re-encodes the clip so it starts and ends on the requested frames
+	ClipModeAccurate = "accurate"
+	// ClipModeKeyframe copies the streams without re-encoding; the clip starts
+	// at the keyframe preceding the requested start
+	ClipModeKeyframe = "keyframe"
+)
+
+// ClipJob tracks the extraction of a clip of a video into a new media
+type ClipJob struct {
+	tableName struct{} `pg:"media_clip_jobs"`
+
+	ID            string        `json:"id" pg:"id,pk"`
+	SourceMediaID string        `json:"source_media_id" pg:"source_media_id,notnull"`
+	ClipMediaID   *string       `json:"clip_media_id,omitempty" pg:"clip_media_id"`
+	RequestedBy   string        `json:"requested_by" pg:"requested_by,notnull"`
+	Start         float64       `json:"start" pg:"start_time,use_zero"` // Seconds
+	End           float64       `json:"end" pg:"end_time,use_zero"`
+	Width         int           `json:"width,omitempty" pg:"width,use_zero"` // Output size, 0 keeps the source size or aspect ratio
+	Height        int           `json:"height,omitempty" pg:"height,use_zero"`
+	Mode          string        `json:"mode" pg:"mode,notnull"`
+	Status        ClipJobStatus `json:"status" pg:"status,notnull"`
+	Error         string        `json:"error,omitempty" pg:"error"`
+	CreatedAt     time.Time     `json:"created_at" pg:"created_at,default:now()"`
+	UpdatedAt     time.Time     `json:"updated_at" pg:"updated_at,default:now()"`
+	CompletedAt   *time.Time    `json:"completed_at,omitempty" pg:"completed_at"`
+}
+
+// Finished reports whether the job completed or failed
+func (j *ClipJob) Finished() bool {
+	return j.Status == ClipJobStatusCompleted || j.Status == ClipJobStatusFailed
+}
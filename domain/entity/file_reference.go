@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Tables whose rows reference stored files
+const (
+	FileSourceMedia    = "media"
+	FileSourceVariants = "media_variants"
+	FileSourceTracks   = "media_tracks"
+)
+
+// FileReference is a stored file referenced by a row of media, media_variants
+// or media_tracks
+type FileReference struct {
+	Source    string    `json:"source"` // Table of the row
+	RowID     string    `json:"row_id"`
+	MediaID   string    `json:"media_id"`
+	URL       string    `json:"url"`
+	Backend   string    `json:"backend"` // storage_backend of the row
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -1,6 +1,8 @@
 package entity
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -8,10 +10,11 @@ import (
 type MediaType string
 
 const (
-	MediaTypeImage MediaType = "image"
-	MediaTypeVideo MediaType = "video"
-	MediaTypeAudio MediaType = "audio"
-	MediaTypeOther MediaType = "other"
+	MediaTypeImage    MediaType = "image"
+	MediaTypeVideo    MediaType = "video"
+	MediaTypeAudio    MediaType = "audio"
+	MediaTypeDocument MediaType = "document"
+	MediaTypeOther    MediaType = "other"
 )
 
 type MimeType string
@@ -23,7 +26,12 @@ const (
 	MimeTypeJPEG  MimeType = "image/jpeg"
 	MimeTypeGIF   MimeType = "image/gif"
 	MimeTypeVideo MimeType = "video/mp4"
+	MimeTypeMOV   MimeType = "video/quicktime"
+	MimeTypeWebM  MimeType = "video/webm"
+	MimeTypeVTT   MimeType = "text/vtt"
 	MimeTypeAudio MimeType = "audio/mpeg"
+	MimeTypePDF   MimeType = "application/pdf"
+	MimeTypeSVG   MimeType = "image/svg+xml"
 	MimeTypeOther MimeType = "application/octet-stream"
 )
 
@@ -33,10 +41,34 @@ const (
 	ExtPNG   = ".png"
 	ExtGIF   = ".gif"
 	ExtMP4   = ".mp4"
+	ExtMOV   = ".mov"
+	ExtWebM  = ".webm"
+	ExtVTT   = ".vtt"
 	ExtAudio = ".mp3"
+	ExtPDF   = ".pdf"
+	ExtSVG   = ".svg"
 	ExtOther = ".other"
 )
 
+// Priority routes the processing tasks of a media to a weighted queue
+type Priority string
+
+const (
+	PriorityInteractive Priority = "interactive" // Uploads a user is waiting for
+	PriorityBulk        Priority = "bulk"        // Imports and backfills
+)
+
+// ParsePriority validates a requested priority, defaulting to interactive
+func ParsePriority(s string) (Priority, error) {
+	switch Priority(s) {
+	case "", PriorityInteractive:
+		return PriorityInteractive, nil
+	case PriorityBulk:
+		return PriorityBulk, nil
+	}
+	return "", fmt.Errorf("unknown priority: %s", s)
+}
+
 type ProcessingStatus string
 
 const (
@@ -46,22 +78,78 @@ const (
 	ProcessingStatusFailed     ProcessingStatus = "failed"
 )
 
+// ErrInvalidTransition is returned for processing status changes the state machine forbids
+var ErrInvalidTransition = errors.New("invalid processing transition")
+
+// processingTransitions lists the statuses reachable from each status.
+// Processing goes back to pending when a retry is scheduled, finished media
+// go back to pending when processing is requested again.
+var processingTransitions = map[ProcessingStatus][]ProcessingStatus{
+	ProcessingStatusPending:    {ProcessingStatusProcessing, ProcessingStatusFailed},
+	ProcessingStatusProcessing: {ProcessingStatusCompleted, ProcessingStatusFailed, ProcessingStatusPending},
+	ProcessingStatusCompleted:  {ProcessingStatusPending},
+	ProcessingStatusFailed:     {ProcessingStatusPending},
+}
+
+// CanTransitionTo reports whether the state machine allows moving to status to
+func (s ProcessingStatus) CanTransitionTo(to ProcessingStatus) bool {
+	for _, next := range processingTransitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Media struct {
 	ID               string            `json:"id" pg:"id,pk"`
 	CreatedBy        string            `json:"created_by" pg:"created_by"`
 	Name             string            `json:"name" pg:"name,notnull"`
 	Size             int64             `json:"size" pg:"size"`
 	URL              string            `json:"url" pg:"url"`
+	StorageBackend   string            `json:"storage_backend" pg:"storage_backend"` // Named backend holding the original
+	StorageTier      string            `json:"storage_tier" pg:"storage_tier"`       // Tier of that backend
+	TierStatus       string            `json:"tier_status,omitempty" pg:"tier_status,use_zero"`
+	TierUpdatedAt    *time.Time        `json:"tier_updated_at,omitempty" pg:"tier_updated_at"`
+	LastAccessedAt   *time.Time        `json:"last_accessed_at,omitempty" pg:"last_accessed_at"` // Last delivery, recorded at most hourly
 	MimeType         string            `json:"mime_type" pg:"mime_type"`
 	Type             MediaType         `json:"type" pg:"type"`
 	Width            *int              `json:"width,omitempty" pg:"width"`
 	Height           *int              `json:"height,omitempty" pg:"height"`
 	Duration         *float64          `json:"duration,omitempty" pg:"duration"` // For video/audio in seconds
 	ProcessingStatus ProcessingStatus  `json:"processing_status" pg:"processing_status"`
+	Attempts         int               `json:"attempts" pg:"attempts,use_zero"`      // Processing attempts since the last request
+	LastError        string            `json:"last_error,omitempty" pg:"last_error"` // Why the last processing attempt failed
+	Priority         Priority          `json:"priority" pg:"priority"`
 	Metadata         map[string]string `json:"metadata,omitempty" pg:"metadata"`
+	Palette          []PaletteColor    `json:"palette,omitempty" pg:"palette,type:jsonb"`
+	PHash            *int64            `json:"phash,omitempty" pg:"phash"`     // 64-bit perceptual hash (dHash)
+	FocalX           *float64          `json:"focal_x,omitempty" pg:"focal_x"` // Focal point as fractions of width/height
+	FocalY           *float64          `json:"focal_y,omitempty" pg:"focal_y"`
+	PageCount        *int              `json:"page_count,omitempty" pg:"page_count"` // For documents
+	Content          string            `json:"-" pg:"content"`                       // Extracted searchable text of documents
+	Misoriented      bool              `json:"misoriented,omitempty" pg:"misoriented,use_zero"`
+	SourceMediaID    *string           `json:"source_media_id,omitempty" pg:"source_media_id"` // Video a clip was cut from
+	Variants         []*MediaVariant   `json:"variants,omitempty" pg:"rel:has-many"`
 	CreatedAt        time.Time         `json:"created_at" pg:"created_at,default:now()"`
 	UpdatedAt        time.Time         `json:"updated_at" pg:"updated_at,default:now()"`
 }
+
+// FocalPoint is the point of interest of an image, as fractions (0..1) of its width and height
+type FocalPoint struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// PaletteColor is one dominant color of an image
+type PaletteColor struct {
+	Hex   string  `json:"hex"`
+	Share float64 `json:"share"` // Fraction of the image covered by the color, 0..1
+	L     float64 `json:"l"`     // CIE L*a*b* components used for color search
+	A     float64 `json:"a"`
+	B     float64 `json:"b"`
+}
+
 type UploadRequest struct {
 	FileName  string            `json:"file_name"`
 	FileSize  int64             `json:"file_size"`
@@ -73,3 +161,36 @@ type UploadRequest struct {
 func (Media) TableName() string {
 	return "media"
 }
+
+// TransitionProcessing moves the media to the processing status to. Entering
+// processing starts an attempt, failing or scheduling a retry records reason,
+// completing clears it, and requesting a finished media again resets the attempts.
+func (m *Media) TransitionProcessing(to ProcessingStatus, reason string) error {
+	if !m.ProcessingStatus.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, m.ProcessingStatus, to)
+	}
+	switch to {
+	case ProcessingStatusProcessing:
+		m.Attempts++
+	case ProcessingStatusCompleted:
+		m.LastError = ""
+	case ProcessingStatusFailed:
+		m.LastError = reason
+	case ProcessingStatusPending:
+		if m.ProcessingStatus == ProcessingStatusProcessing {
+			m.LastError = reason
+		} else {
+			m.Attempts = 0
+		}
+	}
+	m.ProcessingStatus = to
+	return nil
+}
+
+// FocalPoint returns the focal point set by the owner, or nil when crops should be automatic
+func (m *Media) FocalPoint() *FocalPoint {
+	if m.FocalX == nil || m.FocalY == nil {
+		return nil
+	}
+	return &FocalPoint{X: *m.FocalX, Y: *m.FocalY}
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+)
+
+// Text track kinds, as in the HTML track element
+const (
+	TrackKindSubtitles    = "subtitles"
+	TrackKindCaptions     = "captions"
+	TrackKindDescriptions = "descriptions"
+	TrackKindChapters     = "chapters"
+	TrackKindMetadata     = "metadata"
+)
+
+// MediaTrack is a WebVTT text track (subtitles, captions...) attached to a video media
+type MediaTrack struct {
+	ID             string    `json:"id" pg:"id,pk"`
+	MediaID        string    `json:"media_id" pg:"media_id,notnull"`
+	Kind           string    `json:"kind" pg:"kind,notnull"`
+	Language       string    `json:"language" pg:"language,notnull"` // BCP 47 tag
+	Label          string    `json:"label" pg:"label,notnull"`
+	URL            string    `json:"url" pg:"url,notnull"`
+	StorageBackend string    `json:"storage_backend" pg:"storage_backend"`
+	MimeType       string    `json:"mime_type" pg:"mime_type"`
+	CreatedBy      string    `json:"created_by" pg:"created_by"`
+	CreatedAt      time.Time `json:"created_at" pg:"created_at,default:now()"`
+	UpdatedAt      time.Time `json:"updated_at" pg:"updated_at,default:now()"`
+}
+
+func (MediaTrack) TableName() string {
+	return "media_tracks"
+}
+
+// IsTrackKind reports whether kind is a known track kind
+func IsTrackKind(kind string) bool {
+	switch kind {
+	case TrackKindSubtitles, TrackKindCaptions, TrackKindDescriptions, TrackKindChapters, TrackKindMetadata:
+		return true
+	}
+	return false
+}
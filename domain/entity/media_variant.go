@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Variant names
+const (
+	// VariantOriginal refers to the uploaded file itself, served only to its owner
+	// and never watermarked; it is not stored as a variant row
+	VariantOriginal        = "original"
+	VariantPreview         = "preview"
+	VariantThumbnailSmall  = "thumbnail_small"
+	VariantThumbnailMedium = "thumbnail_medium"
+	VariantThumbnailLarge  = "thumbnail_large"
+	// VariantSpriteTrack is the WebVTT thumbnail track of a video, whose cues
+	// point at tiles of the sprite sheet variants
+	VariantSpriteTrack  = "sprite_track"
+	variantSpritePrefix = "sprite_sheet_"
+)
+
+// SpriteSheetName returns the variant name of the i-th sprite sheet of a video
+func SpriteSheetName(i int) string {
+	return fmt.Sprintf("%s%03d", variantSpritePrefix, i)
+}
+
+// IsSpriteVariant reports whether the variant belongs to the scrubbing sprites of a video
+func IsSpriteVariant(name string) bool {
+	return name == VariantSpriteTrack || strings.HasPrefix(name, variantSpritePrefix)
+}
+
+// MediaVariant is a rendition derived from a media (thumbnail, resized copy, ...)
+type MediaVariant struct {
+	ID             string    `json:"id" pg:"id,pk"`
+	MediaID        string    `json:"media_id" pg:"media_id,notnull"`
+	Name           string    `json:"name" pg:"name,notnull"`
+	URL            string    `json:"url" pg:"url"`
+	StorageBackend string    `json:"storage_backend" pg:"storage_backend"`
+	MimeType       string    `json:"mime_type" pg:"mime_type"`
+	Width          int       `json:"width" pg:"width"`
+	Height         int       `json:"height" pg:"height"`
+	Watermark      string    `json:"watermark,omitempty" pg:"watermark,use_zero"` // Key of the watermark profile applied
+	CreatedAt      time.Time `json:"created_at" pg:"created_at,default:now()"`
+	UpdatedAt      time.Time `json:"updated_at" pg:"updated_at,default:now()"`
+}
+
+func (MediaVariant) TableName() string {
+	return "media_variants"
+}
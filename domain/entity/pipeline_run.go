@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+)
+
+type PipelineRunStatus string
+
+const (
+	PipelineRunStatusSucceeded PipelineRunStatus = "succeeded"
+	PipelineRunStatusFailed    PipelineRunStatus = "failed"
+)
+
+type PipelineStepStatus string
+
+const (
+	PipelineStepStatusSucceeded PipelineStepStatus = "succeeded"
+	PipelineStepStatusFailed    PipelineStepStatus = "failed"
+	PipelineStepStatusSkipped   PipelineStepStatus = "skipped"
+)
+
+// PipelineStepResult is the outcome and timing of one step of a run
+type PipelineStepResult struct {
+	Name       string             `json:"name"`
+	Status     PipelineStepStatus `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	StartedAt  time.Time          `json:"started_at"`
+	DurationMs int64              `json:"duration_ms"`
+}
+
+// PipelineRun records a processing pipeline run over a media
+type PipelineRun struct {
+	tableName struct{} `pg:"media_pipeline_runs"`
+
+	ID         string               `json:"id" pg:"id,pk"`
+	MediaID    string               `json:"media_id" pg:"media_id,notnull"`
+	Family     string               `json:"family" pg:"family,notnull"`
+	Status     PipelineRunStatus    `json:"status" pg:"status,notnull"`
+	Error      string               `json:"error,omitempty" pg:"error"`
+	Steps      []PipelineStepResult `json:"steps" pg:"steps,type:jsonb"`
+	StartedAt  time.Time            `json:"started_at" pg:"started_at"`
+	DurationMs int64                `json:"duration_ms" pg:"duration_ms,use_zero"`
+	CreatedAt  time.Time            `json:"created_at" pg:"created_at,default:now()"`
+}
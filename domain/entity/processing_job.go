@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+)
+
+type ProcessingJobStatus string
+
+const (
+	ProcessingJobStatusRunning   ProcessingJobStatus = "running"
+	ProcessingJobStatusCompleted ProcessingJobStatus = "completed"
+	ProcessingJobStatusFailed    ProcessingJobStatus = "failed"
+)
+
+// ProcessingTriggerUpload is the trigger of the processing of new uploads;
+// worker attempts are triggered by their task type
+const ProcessingTriggerUpload = "upload"
+
+// ProcessingJob records one processing attempt of a media
+type ProcessingJob struct {
+	tableName struct{} `pg:"media_processing_jobs"`
+
+	ID         string              `json:"id" pg:"id,pk"`
+	MediaID    string              `json:"media_id" pg:"media_id,notnull"`
+	Attempt    int                 `json:"attempt" pg:"attempt,use_zero"`
+	Trigger    string              `json:"trigger" pg:"trigger,notnull"` // "upload" or the task type
+	Status     ProcessingJobStatus `json:"status" pg:"status,notnull"`
+	Error      string              `json:"error,omitempty" pg:"error"`
+	StartedAt  time.Time           `json:"started_at" pg:"started_at,notnull"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty" pg:"finished_at"`
+	DurationMs int64               `json:"duration_ms" pg:"duration_ms,use_zero"`
+}
+
+// Finish records the outcome of the attempt; a nil err completes it
+func (j *ProcessingJob) Finish(err error) {
+	now := time.Now()
+	j.FinishedAt = &now
+	j.DurationMs = now.Sub(j.StartedAt).Milliseconds()
+	j.Status = ProcessingJobStatusCompleted
+	if err != nil {
+		j.Status = ProcessingJobStatusFailed
+		j.Error = err.Error()
+	}
+}
@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+)
+
+type ReprocessJobStatus string
+
+const (
+	ReprocessJobStatusQueued    ReprocessJobStatus = "queued"
+	ReprocessJobStatusRunning   ReprocessJobStatus = "running"
+	ReprocessJobStatusCompleted ReprocessJobStatus = "completed"
+)
+
+// ReprocessJob tracks the regeneration of the variants of a set of media
+type ReprocessJob struct {
+	tableName struct{} `pg:"media_reprocess_jobs"`
+
+	ID          string             `json:"id" pg:"id,pk"`
+	RequestedBy string             `json:"requested_by" pg:"requested_by,notnull"`
+	Filters     map[string]string  `json:"filters,omitempty" pg:"filters,type:jsonb"`
+	Status      ReprocessJobStatus `json:"status" pg:"status,notnull"`
+	Total       int                `json:"total" pg:"total,use_zero"`
+	Processed   int                `json:"processed" pg:"processed,use_zero"` // Finished media, failed ones included
+	Failed      int                `json:"failed" pg:"failed,use_zero"`
+	LastError   string             `json:"last_error,omitempty" pg:"last_error"`
+	CreatedAt   time.Time          `json:"created_at" pg:"created_at,default:now()"`
+	UpdatedAt   time.Time          `json:"updated_at" pg:"updated_at,default:now()"`
+	CompletedAt *time.Time         `json:"completed_at,omitempty" pg:"completed_at"`
+}
+
+// Progress returns the finished fraction of the job, from 0 to 1
+func (j *ReprocessJob) Progress() float64 {
+	if j.Total == 0 {
+		return 1
+	}
+	return float64(j.Processed) / float64(j.Total)
+}
@@ -0,0 +1,43 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// TierStatusRestoring marks media whose files are being moved back to the
+// default storage backend; delivery is refused until they are
+const TierStatusRestoring = "restoring"
+
+// RehydrateMode says what delivering a media stored in a colder tier than the
+// default backend does
+type RehydrateMode string
+
+const (
+	RehydrateOff   RehydrateMode = "off"   // Serve it from where it is
+	RehydrateAsync RehydrateMode = "async" // Queue its move back and refuse it until done
+)
+
+// ParseRehydrateMode validates a configured mode, defaulting to off
+func ParseRehydrateMode(s string) (RehydrateMode, error) {
+	switch RehydrateMode(s) {
+	case "", RehydrateOff:
+		return RehydrateOff, nil
+	case RehydrateAsync:
+		return RehydrateAsync, nil
+	case "sync":
+		return "", fmt.Errorf("rehydrate mode sync is no longer supported, use async")
+	}
+	return "", fmt.Errorf("unknown rehydrate mode: %s", s)
+}
+
+// LifecycleRule moves the files of media out of the From backends to the To
+// backend once they match every set condition
+type LifecycleRule struct {
+	Name   string
+	From   []string
+	To     string
+	Types  []MediaType   // Any type when empty
+	MinAge time.Duration // Since the upload
+	Idle   time.Duration // Since the last delivery, or the upload when never delivered
+}
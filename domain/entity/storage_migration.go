@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"time"
+)
+
+type StorageMigrationStatus string
+
+const (
+	StorageMigrationStatusQueued    StorageMigrationStatus = "queued"
+	StorageMigrationStatusRunning   StorageMigrationStatus = "running"
+	StorageMigrationStatusCompleted StorageMigrationStatus = "completed"
+	StorageMigrationStatusFailed    StorageMigrationStatus = "failed" // Stopped early, resumable
+)
+
+// StorageMigration tracks the copy of the files of every media stored in one
+// backend to another. Media are migrated in ID order; LastMediaID is where a
+// resumed migration continues.
+type StorageMigration struct {
+	tableName struct{} `pg:"media_storage_migrations"`
+
+	ID           string                 `json:"id" pg:"id,pk"`
+	RequestedBy  string                 `json:"requested_by" pg:"requested_by,notnull"`
+	Source       string                 `json:"source" pg:"source,notnull"`
+	Target       string                 `json:"target" pg:"target,notnull"`
+	DeleteSource bool                   `json:"delete_source" pg:"delete_source,use_zero"`
+	Status       StorageMigrationStatus `json:"status" pg:"status,notnull"`
+	LastMediaID  string                 `json:"last_media_id" pg:"last_media_id,use_zero"`
+	Total        int                    `json:"total" pg:"total,use_zero"`
+	Migrated     int                    `json:"migrated" pg:"migrated,use_zero"`
+	Failed       int                    `json:"failed" pg:"failed,use_zero"`
+	LastError    string                 `json:"last_error,omitempty" pg:"last_error"`
+	CreatedAt    time.Time              `json:"created_at" pg:"created_at,default:now()"`
+	UpdatedAt    time.Time              `json:"updated_at" pg:"updated_at,default:now()"`
+	CompletedAt  *time.Time             `json:"completed_at,omitempty" pg:"completed_at"`
+}
+
+// Progress returns the finished fraction of the migration, from 0 to 1
+func (m *StorageMigration) Progress() float64 {
+	if m.Total == 0 {
+		return 1
+	}
+	return min(1, float64(m.Migrated+m.Failed)/float64(m.Total))
+}
@@ -0,0 +1,29 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// WatermarkProfile describes an overlay composited onto selected variants
+type WatermarkProfile struct {
+	Name           string
+	OverlayMediaID string   // Media used as the overlay image
+	Position       string   // top-left, top-right, bottom-left, bottom-right, center
+	Opacity        float64  // 0..1
+	Scale          float64  // Overlay width as a fraction of the variant width
+	Tile           bool     // Repeat the overlay over the whole variant
+	Margin         int      // Pixels from the edges, or between tiles
+	Variants       []string // Variant names the profile applies to
+}
+
+// Key identifies the profile and its settings, so any change to the profile
+// yields a new key and marks variants rendered with the old one as stale
+func (p *WatermarkProfile) Key() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf(
+		"%s|%s|%s|%.3f|%.3f|%t|%d",
+		p.Name, p.OverlayMediaID, p.Position, p.Opacity, p.Scale, p.Tile, p.Margin,
+	)))
+	return p.Name + "-" + hex.EncodeToString(sum[:4])
+}
@@ -0,0 +1,107 @@
+package heif
+
+import (
+	"context"
+	"errors"
+	"image"
+	"io"
+	"media-service/domain/imaging"
+)
+
+const (
+	// ftypSniffLength is how much of the file the ftyp box is read from
+	ftypSniffLength = 64
+	// nclxDisplayP3 is the colour_primaries code of P3 with a D65 white point
+	nclxDisplayP3 = 12
+)
+
+// ErrUnsupported is returned for HEIF files whose primary image cannot be decoded
+var ErrUnsupported = errors.New("unsupported format")
+
+// Brands of the HEIF image files, as opposed to AVIF or other ISOBMFF files
+var heifBrands = map[string]bool{
+	"heic": true,
+	"heix": true,
+	"heim": true,
+	"heis": true,
+	"hevc": true,
+	"hevx": true,
+	"hevm": true,
+	"hevs": true,
+	"mif1": true,
+	"msf1": true,
+}
+
+// Info describes the primary image of a HEIF file
+type Info struct {
+	ItemType     string // Coding of the primary item: hvc1, grid...
+	PrimaryIndex int    // Position of the primary image among the top-level images
+	Images       int    // Number of top-level images
+	Width        int    // Size before the transformations, 0 when unknown
+	Height       int
+	Orientation  int    // EXIF orientation equivalent of the irot/imir transformations
+	ICCProfile   []byte // Embedded color profile, nil when none
+	Primaries    int    // nclx colour_primaries, 0 when none
+}
+
+// Decoder decodes a top-level image of a HEIF file with its transformations applied
+type Decoder interface {
+	Decode(ctx context.Context, path string, info *Info) (image.Image, error)
+}
+
+// IsHEIF sniffs the ftyp box for a HEIF brand and rewinds the file
+func IsHEIF(r io.ReadSeeker) (bool, error) {
+	header := make([]byte, ftypSniffLength)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return isHEIFBrand(header[:n]), nil
+}
+
+func isHEIFBrand(header []byte) bool {
+	if len(header) < 16 || string(header[4:8]) != "ftyp" {
+		return false
+	}
+	size := int(uint32(header[0])<<24 | uint32(header[1])<<16 | uint32(header[2])<<8 | uint32(header[3]))
+	if size > len(header) {
+		size = len(header)
+	}
+	brands := []string{string(header[8:12])}
+	for i := 16; i+4 <= size; i += 4 {
+		brands = append(brands, string(header[i:i+4]))
+	}
+
+	matched := false
+	for _, brand := range brands {
+		switch {
+		case brand == "avif" || brand == "avis":
+			// AVIF shares the mif1 brand but is not HEVC coded
+			return false
+		case heifBrands[brand]:
+			matched = true
+		}
+	}
+	return matched
+}
+
+// Normalize applies the rotation the decoder left out, if any, and converts
+// the pixels to sRGB
+func Normalize(img image.Image, info *Info) image.Image {
+	bounds := img.Bounds()
+	if imaging.SwapsAxes(info.Orientation) && info.Width != info.Height &&
+		bounds.Dx() == info.Width && bounds.Dy() == info.Height {
+		img = imaging.Orient(img, info.Orientation)
+	}
+
+	switch {
+	case info.ICCProfile != nil:
+		return imaging.ToSRGB(img, info.ICCProfile)
+	case info.Primaries == nclxDisplayP3:
+		return imaging.DisplayP3ToSRGB(img)
+	}
+	return img
+}
@@ -0,0 +1,450 @@
+package heif
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxMetaSize bounds the meta box loaded in memory; pixels live in mdat
+const maxMetaSize = 16 * 1024 * 1024
+
+// Item types libheif decodes with the HEVC plugin
+var supportedItemTypes = map[string]bool{
+	"hvc1": true,
+	"grid": true,
+	"iden": true,
+}
+
+// Image item types, used to find the top-level images
+var imageItemTypes = map[string]bool{
+	"hvc1": true,
+	"grid": true,
+	"iden": true,
+	"iovl": true,
+	"av01": true,
+	"jpeg": true,
+	"unci": true,
+	"j2k1": true,
+	"vvc1": true,
+}
+
+var errTruncated = errors.New("truncated box")
+
+type box struct {
+	kind string
+	data []byte
+}
+
+type item struct {
+	id     uint32
+	kind   string
+	hidden bool
+}
+
+type property struct {
+	kind string
+	data []byte
+}
+
+// Parse reads the meta box of a HEIF file and describes its primary image.
+// The error wraps ErrUnsupported when the primary image is not HEVC coded.
+func Parse(r io.ReadSeeker) (*Info, error) {
+	meta, err := findMeta(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(meta) < 4 {
+		return nil, errTruncated
+	}
+	children, err := readBoxes(meta[4:]) // meta is a full box
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		primary    uint32
+		hasPrimary bool
+		items      []item
+		properties []property
+		links      = make(map[uint32][]int)
+		refs       = make(map[string]map[uint32][]uint32)
+	)
+	for _, child := range children {
+		switch child.kind {
+		case "hdlr":
+			if len(child.data) < 12 || string(child.data[8:12]) != "pict" {
+				return nil, fmt.Errorf("%w: not an image file", ErrUnsupported)
+			}
+		case "pitm":
+			primary, hasPrimary = parsePitm(child.data)
+		case "iinf":
+			if items, err = parseIinf(child.data); err != nil {
+				return nil, err
+			}
+		case "iprp":
+			if properties, links, err = parseIprp(child.data); err != nil {
+				return nil, err
+			}
+		case "iref":
+			if refs, err = parseIref(child.data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if !hasPrimary {
+		return nil, fmt.Errorf("no primary item")
+	}
+
+	info := &Info{Orientation: 1}
+	topLevel := topLevelImages(items, refs)
+	info.Images = len(topLevel)
+	info.PrimaryIndex = -1
+	for i, id := range topLevel {
+		if id == primary {
+			info.PrimaryIndex = i
+		}
+	}
+	for _, it := range items {
+		if it.id == primary {
+			info.ItemType = it.kind
+		}
+	}
+	if info.PrimaryIndex < 0 {
+		return nil, fmt.Errorf("primary item %d is not an image", primary)
+	}
+	if !supportedItemTypes[info.ItemType] {
+		return nil, fmt.Errorf("%w: %s coded HEIF images are not supported", ErrUnsupported, info.ItemType)
+	}
+
+	// Transformations apply in the order the properties are associated
+	transform := identity
+	for _, index := range links[primary] {
+		if index < 1 || index > len(properties) {
+			continue
+		}
+		prop := properties[index-1]
+		switch prop.kind {
+		case "ispe":
+			if len(prop.data) >= 12 {
+				info.Width = int(binary.BigEndian.Uint32(prop.data[4:]))
+				info.Height = int(binary.BigEndian.Uint32(prop.data[8:]))
+			}
+		case "irot":
+			if len(prop.data) >= 1 {
+				// Anti-clockwise quarter turns
+				for i := 0; i < int(prop.data[0]&3); i++ {
+					transform = rotateCCW.mul(transform)
+				}
+			}
+		case "imir":
+			if len(prop.data) >= 1 {
+				if prop.data[0]&1 == 0 {
+					transform = flipH.mul(transform) // Vertical axis
+				} else {
+					transform = flipV.mul(transform) // Horizontal axis
+				}
+			}
+		case "colr":
+			parseColr(prop.data, info)
+		}
+	}
+	info.Orientation = transform.orientation()
+	return info, nil
+}
+
+// findMeta returns the content of the top-level meta box, skipping mdat
+func findMeta(r io.ReadSeeker) ([]byte, error) {
+	header := make([]byte, 16)
+	for {
+		if _, err := io.ReadFull(r, header[:8]); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("no meta box")
+			}
+			return nil, errTruncated
+		}
+		size := int64(binary.BigEndian.Uint32(header))
+		kind := string(header[4:8])
+		headerSize := int64(8)
+		toEnd := size == 0
+		switch size {
+		case 0:
+			if kind != "meta" {
+				return nil, fmt.Errorf("no meta box")
+			}
+			size = maxMetaSize + headerSize
+		case 1:
+			if _, err := io.ReadFull(r, header[8:16]); err != nil {
+				return nil, errTruncated
+			}
+			size = int64(binary.BigEndian.Uint64(header[8:]))
+			headerSize = 16
+		}
+		if size < headerSize {
+			return nil, errTruncated
+		}
+
+		if kind != "meta" {
+			if _, err := r.Seek(size-headerSize, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if size-headerSize > maxMetaSize {
+			return nil, fmt.Errorf("meta box too large")
+		}
+		data, err := io.ReadAll(io.LimitReader(r, size-headerSize))
+		if err != nil {
+			return nil, err
+		}
+		// A meta box extending to the end of the file has no size to check
+		if !toEnd && int64(len(data)) < size-headerSize {
+			return nil, errTruncated
+		}
+		return data, nil
+	}
+}
+
+func readBoxes(data []byte) ([]box, error) {
+	var boxes []box
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return nil, errTruncated
+		}
+		size := uint64(binary.BigEndian.Uint32(data))
+		kind := string(data[4:8])
+		headerSize := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return nil, errTruncated
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			headerSize = 16
+		}
+		if size < headerSize || size > uint64(len(data)) {
+			return nil, errTruncated
+		}
+		boxes = append(boxes, box{kind: kind, data: data[headerSize:size]})
+		data = data[size:]
+	}
+	return boxes, nil
+}
+
+func parsePitm(data []byte) (uint32, bool) {
+	if len(data) < 6 {
+		return 0, false
+	}
+	if data[0] == 0 {
+		return uint32(binary.BigEndian.Uint16(data[4:])), true
+	}
+	if len(data) < 8 {
+		return 0, false
+	}
+	return binary.BigEndian.Uint32(data[4:]), true
+}
+
+func parseIinf(data []byte) ([]item, error) {
+	if len(data) < 6 {
+		return nil, errTruncated
+	}
+	offset := 6 // version, flags, 16-bit entry count
+	if data[0] != 0 {
+		offset = 8
+	}
+	entries, err := readBoxes(data[offset:])
+	if err != nil {
+		return nil, err
+	}
+
+	var items []item
+	for _, entry := range entries {
+		if entry.kind != "infe" || len(entry.data) < 4 {
+			continue
+		}
+		version := entry.data[0]
+		if version < 2 {
+			continue // Pre-HEIF item entries have no item type
+		}
+		hidden := entry.data[3]&1 == 1
+		body := entry.data[4:]
+		var it item
+		if version == 2 {
+			if len(body) < 8 {
+				return nil, errTruncated
+			}
+			it = item{id: uint32(binary.BigEndian.Uint16(body)), kind: string(body[4:8])}
+		} else {
+			if len(body) < 10 {
+				return nil, errTruncated
+			}
+			it = item{id: binary.BigEndian.Uint32(body), kind: string(body[6:10])}
+		}
+		it.hidden = hidden
+		items = append(items, it)
+	}
+	return items, nil
+}
+
+// parseIprp returns the properties of ipco and, by item, the 1-based indexes
+// of its associated properties
+func parseIprp(data []byte) ([]property, map[uint32][]int, error) {
+	children, err := readBoxes(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var properties []property
+	links := make(map[uint32][]int)
+	for _, child := range children {
+		switch child.kind {
+		case "ipco":
+			boxes, err := readBoxes(child.data)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, b := range boxes {
+				properties = append(properties, property{kind: b.kind, data: b.data})
+			}
+		case "ipma":
+			if err := parseIpma(child.data, links); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	return properties, links, nil
+}
+
+func parseIpma(data []byte, links map[uint32][]int) error {
+	if len(data) < 8 {
+		return errTruncated
+	}
+	version := data[0]
+	wideIndex := data[3]&1 == 1
+	count := binary.BigEndian.Uint32(data[4:])
+	data = data[8:]
+	for i := uint32(0); i < count; i++ {
+		var id uint32
+		if version < 1 {
+			if len(data) < 3 {
+				return errTruncated
+			}
+			id = uint32(binary.BigEndian.Uint16(data))
+			data = data[2:]
+		} else {
+			if len(data) < 5 {
+				return errTruncated
+			}
+			id = binary.BigEndian.Uint32(data)
+			data = data[4:]
+		}
+		associations := int(data[0])
+		data = data[1:]
+		for j := 0; j < associations; j++ {
+			var index int
+			if wideIndex {
+				if len(data) < 2 {
+					return errTruncated
+				}
+				index = int(binary.BigEndian.Uint16(data) & 0x7fff)
+				data = data[2:]
+			} else {
+				if len(data) < 1 {
+					return errTruncated
+				}
+				index = int(data[0] & 0x7f)
+				data = data[1:]
+			}
+			links[id] = append(links[id], index)
+		}
+	}
+	return nil
+}
+
+// parseIref returns, by reference type, the items each item references
+func parseIref(data []byte) (map[string]map[uint32][]uint32, error) {
+	if len(data) < 4 {
+		return nil, errTruncated
+	}
+	wide := data[0] != 0
+	entries, err := readBoxes(data[4:])
+	if err != nil {
+		return nil, err
+	}
+
+	read := func(b []byte) (uint32, []byte, bool) {
+		if wide {
+			if len(b) < 4 {
+				return 0, nil, false
+			}
+			return binary.BigEndian.Uint32(b), b[4:], true
+		}
+		if len(b) < 2 {
+			return 0, nil, false
+		}
+		return uint32(binary.BigEndian.Uint16(b)), b[2:], true
+	}
+
+	refs := make(map[string]map[uint32][]uint32)
+	for _, entry := range entries {
+		from, rest, ok := read(entry.data)
+		if !ok || len(rest) < 2 {
+			return nil, errTruncated
+		}
+		count := int(binary.BigEndian.Uint16(rest))
+		rest = rest[2:]
+		if refs[entry.kind] == nil {
+			refs[entry.kind] = make(map[uint32][]uint32)
+		}
+		for i := 0; i < count; i++ {
+			var to uint32
+			if to, rest, ok = read(rest); !ok {
+				return nil, errTruncated
+			}
+			refs[entry.kind][from] = append(refs[entry.kind][from], to)
+		}
+	}
+	return refs, nil
+}
+
+// topLevelImages lists the images a decoder exposes, in item order: visible
+// images that are neither thumbnails, auxiliary images nor derived image inputs
+func topLevelImages(items []item, refs map[string]map[uint32][]uint32) []uint32 {
+	excluded := make(map[uint32]bool)
+	for from := range refs["thmb"] {
+		excluded[from] = true
+	}
+	for from := range refs["auxl"] {
+		excluded[from] = true
+	}
+	for _, inputs := range refs["dimg"] {
+		for _, id := range inputs {
+			excluded[id] = true
+		}
+	}
+
+	var ids []uint32
+	for _, it := range items {
+		if imageItemTypes[it.kind] && !it.hidden && !excluded[it.id] {
+			ids = append(ids, it.id)
+		}
+	}
+	return ids
+}
+
+func parseColr(data []byte, info *Info) {
+	if len(data) < 4 {
+		return
+	}
+	switch string(data[:4]) {
+	case "prof", "rICC":
+		info.ICCProfile = data[4:]
+	case "nclx":
+		if len(data) >= 6 {
+			info.Primaries = int(binary.BigEndian.Uint16(data[4:]))
+		}
+	}
+}
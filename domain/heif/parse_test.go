@@ -0,0 +1,261 @@
+package heif
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func u16(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+
+func u32(v uint32) []byte { return binary.BigEndian.AppendUint32(nil, v) }
+
+// fullBox is the version and flags header of a full box
+func fullBox(version byte, flags uint32) []byte {
+	return []byte{version, byte(flags >> 16), byte(flags >> 8), byte(flags)}
+}
+
+func mkBox(kind string, parts ...[]byte) []byte {
+	body := slices.Concat(parts...)
+	return slices.Concat(u32(uint32(8+len(body))), []byte(kind), body)
+}
+
+// largeBox encodes a box with a 64-bit size
+func largeBox(kind string, body []byte) []byte {
+	return slices.Concat(u32(1), []byte(kind), binary.BigEndian.AppendUint64(nil, uint64(16+len(body))), body)
+}
+
+func ftyp(major string, compatible ...string) []byte {
+	parts := [][]byte{[]byte(major), u32(0)}
+	for _, brand := range compatible {
+		parts = append(parts, []byte(brand))
+	}
+	return mkBox("ftyp", parts...)
+}
+
+func hdlr(handler string) []byte {
+	return mkBox("hdlr", fullBox(0, 0), u32(0), []byte(handler), make([]byte, 12), []byte{0})
+}
+
+func pitm(id uint16) []byte { return mkBox("pitm", fullBox(0, 0), u16(id)) }
+
+type testItem struct {
+	id     uint16
+	kind   string
+	hidden bool
+}
+
+func iinf(items ...testItem) []byte {
+	parts := [][]byte{fullBox(0, 0), u16(uint16(len(items)))}
+	for _, it := range items {
+		flags := uint32(0)
+		if it.hidden {
+			flags = 1
+		}
+		parts = append(parts, mkBox("infe", fullBox(2, flags), u16(it.id), u16(0), []byte(it.kind), []byte{0}))
+	}
+	return mkBox("iinf", parts...)
+}
+
+// iprp associates each item with the 1-based indexes of its properties
+func iprp(properties [][]byte, links map[uint16][]byte) []byte {
+	ids := make([]uint16, 0, len(links))
+	for id := range links {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	ipma := [][]byte{fullBox(0, 0), u32(uint32(len(ids)))}
+	for _, id := range ids {
+		ipma = append(ipma, u16(id), []byte{byte(len(links[id]))}, links[id])
+	}
+	return mkBox("iprp", mkBox("ipco", properties...), mkBox("ipma", ipma...))
+}
+
+// iref lists references of one type, from an item to others
+func iref(kind string, from uint16, to ...uint16) []byte {
+	entry := [][]byte{u16(from), u16(uint16(len(to)))}
+	for _, id := range to {
+		entry = append(entry, u16(id))
+	}
+	return mkBox("iref", fullBox(0, 0), mkBox(kind, entry...))
+}
+
+func ispe(width, height uint32) []byte {
+	return mkBox("ispe", fullBox(0, 0), u32(width), u32(height))
+}
+
+func meta(children ...[]byte) []byte {
+	return mkBox("meta", append([][]byte{fullBox(0, 0)}, children...)...)
+}
+
+// photo is a HEVC coded image with a thumbnail, as cameras write them
+func photo(properties ...[]byte) []byte {
+	links := make([]byte, 0, len(properties)+1)
+	for i := range len(properties) + 1 {
+		links = append(links, 0x80|byte(i+1))
+	}
+	return slices.Concat(
+		ftyp("heic", "mif1", "heic"),
+		meta(
+			hdlr("pict"),
+			pitm(1),
+			iinf(testItem{1, "hvc1", false}, testItem{2, "hvc1", false}, testItem{3, "Exif", false}),
+			iref("thmb", 2, 1),
+			iprp(append([][]byte{ispe(4032, 3024)}, properties...), map[uint16][]byte{1: links, 2: {0x81}}),
+		),
+		mkBox("mdat", make([]byte, 32)),
+	)
+}
+
+func TestParse(t *testing.T) {
+	info, err := Parse(bytes.NewReader(photo(
+		mkBox("colr", []byte("nclx"), u16(nclxDisplayP3), u16(16), u16(9), []byte{0}),
+	)))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := Info{ItemType: "hvc1", PrimaryIndex: 0, Images: 1, Width: 4032, Height: 3024, Orientation: 1, Primaries: nclxDisplayP3}
+	if info.ICCProfile != nil || info.ItemType != want.ItemType || info.PrimaryIndex != want.PrimaryIndex ||
+		info.Images != want.Images || info.Width != want.Width || info.Height != want.Height ||
+		info.Orientation != want.Orientation || info.Primaries != want.Primaries {
+		t.Errorf("Parse = %+v, want %+v", *info, want)
+	}
+}
+
+func TestParseICCProfile(t *testing.T) {
+	profile := []byte("icc profile bytes")
+	info, err := Parse(bytes.NewReader(photo(mkBox("colr", []byte("prof"), profile))))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if !bytes.Equal(info.ICCProfile, profile) {
+		t.Errorf("ICCProfile = %q, want %q", info.ICCProfile, profile)
+	}
+}
+
+func TestParseOrientation(t *testing.T) {
+	irot := func(angle byte) []byte { return mkBox("irot", []byte{angle}) }
+	imir := func(axis byte) []byte { return mkBox("imir", []byte{axis}) }
+	tests := []struct {
+		name       string
+		properties [][]byte
+		want       int
+	}{
+		{"none", nil, 1},
+		{"quarter turn anti-clockwise", [][]byte{irot(1)}, 8},
+		{"half turn", [][]byte{irot(2)}, 3},
+		{"quarter turn clockwise", [][]byte{irot(3)}, 6}, // Portrait photos of phones
+		{"mirror on the vertical axis", [][]byte{imir(0)}, 2},
+		{"mirror on the horizontal axis", [][]byte{imir(1)}, 4},
+		{"turn then mirror", [][]byte{irot(1), imir(0)}, 7},
+		{"mirror then turn", [][]byte{imir(0), irot(1)}, 5},
+		{"full turn", [][]byte{irot(2), irot(2)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Parse(bytes.NewReader(photo(tt.properties...)))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if info.Orientation != tt.want {
+				t.Errorf("Orientation = %d, want %d", info.Orientation, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGrid(t *testing.T) {
+	// A grid of two hidden tiles, followed by a second top-level image
+	file := slices.Concat(
+		ftyp("heic", "mif1"),
+		largeBox("mdat", make([]byte, 64)), // Skipped before the meta box
+		meta(
+			hdlr("pict"),
+			pitm(3),
+			iinf(testItem{1, "hvc1", true}, testItem{2, "hvc1", true}, testItem{3, "grid", false}, testItem{4, "hvc1", false}),
+			iref("dimg", 3, 1, 2),
+			iprp([][]byte{ispe(1024, 512)}, map[uint16][]byte{3: {0x01}}),
+		),
+	)
+	info, err := Parse(bytes.NewReader(file))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if info.ItemType != "grid" || info.Images != 2 || info.PrimaryIndex != 0 || info.Width != 1024 || info.Height != 512 {
+		t.Errorf("Parse = %+v, want the grid first of 2 images", *info)
+	}
+}
+
+func TestParseRejectsUnsupportedFiles(t *testing.T) {
+	avif := slices.Concat(ftyp("avif", "mif1"), meta(hdlr("pict"), pitm(1), iinf(testItem{1, "av01", false})))
+	video := slices.Concat(ftyp("heic"), meta(hdlr("vide"), pitm(1), iinf(testItem{1, "hvc1", false})))
+	for name, file := range map[string][]byte{"av01 primary": avif, "video handler": video} {
+		if _, err := Parse(bytes.NewReader(file)); !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Parse of %s = %v, want ErrUnsupported", name, err)
+		}
+	}
+}
+
+func TestParseRejectsInvalidFiles(t *testing.T) {
+	valid := photo()
+	metaStart := bytes.Index(valid, []byte("meta")) - 4
+	tests := map[string][]byte{
+		"empty":            nil,
+		"no meta box":      slices.Concat(ftyp("heic"), mkBox("mdat", nil)),
+		"truncated header": valid[:metaStart+6],
+		"truncated meta":   valid[:metaStart+40],
+		"no primary item":  slices.Concat(ftyp("heic"), meta(hdlr("pict"), iinf(testItem{1, "hvc1", false}))),
+		"primary not an image": slices.Concat(ftyp("heic"),
+			meta(hdlr("pict"), pitm(1), iinf(testItem{1, "Exif", false}))),
+		"child box overflowing meta": slices.Concat(ftyp("heic"),
+			mkBox("meta", fullBox(0, 0), u32(64), []byte("pitm"))),
+		"box smaller than its header": slices.Concat(ftyp("heic"), u32(4), []byte("mdat")),
+	}
+	for name, file := range tests {
+		if _, err := Parse(bytes.NewReader(file)); err == nil {
+			t.Errorf("Parse with %s succeeded", name)
+		}
+	}
+}
+
+func TestIsHEIF(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{"heic", ftyp("heic", "mif1", "heic"), true},
+		{"mif1 with a HEVC brand", ftyp("mif1", "heic"), true},
+		{"avif", ftyp("avif", "mif1", "avif"), false},
+		{"mif1 with avif", ftyp("mif1", "avif"), false},
+		{"mp4", ftyp("isom", "iso2", "mp41"), false},
+		{"no ftyp", mkBox("mdat", make([]byte, 16)), false},
+		{"short", []byte("\x00\x00\x00\x18ftyp"), false},
+	}
+	for _, tt := range tests {
+		r := bytes.NewReader(tt.header)
+		got, err := IsHEIF(r)
+		if err != nil {
+			t.Fatalf("IsHEIF(%s): %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("IsHEIF(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+		if r.Len() != len(tt.header) {
+			t.Errorf("IsHEIF(%s) did not rewind", tt.name)
+		}
+	}
+}
+
+// Every truncation of a file is an error, never a panic
+func TestParseTruncatedFiles(t *testing.T) {
+	file := photo(mkBox("irot", []byte{3}), mkBox("colr", []byte("prof"), []byte("icc")))
+	end := bytes.Index(file, []byte("mdat")) - 4
+	for n := range end {
+		if _, err := Parse(bytes.NewReader(file[:n])); err == nil {
+			t.Errorf("Parse of the first %d bytes succeeded", n)
+		}
+	}
+}
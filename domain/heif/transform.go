@@ -0,0 +1,39 @@
+package heif
+
+// matrix is an orthogonal transformation of display coordinates, x to the
+// right and y down
+type matrix [4]int
+
+var (
+	identity  = matrix{1, 0, 0, 1}
+	rotateCCW = matrix{0, 1, -1, 0}
+	flipH     = matrix{-1, 0, 0, 1}
+	flipV     = matrix{1, 0, 0, -1}
+)
+
+// exifMatrices maps the EXIF orientations to the transformation they name
+var exifMatrices = map[matrix]int{
+	{1, 0, 0, 1}:   1,
+	{-1, 0, 0, 1}:  2,
+	{-1, 0, 0, -1}: 3,
+	{1, 0, 0, -1}:  4,
+	{0, 1, 1, 0}:   5,
+	{0, -1, 1, 0}:  6,
+	{0, -1, -1, 0}: 7,
+	{0, 1, -1, 0}:  8,
+}
+
+// mul returns the transformation m applied after n
+func (m matrix) mul(n matrix) matrix {
+	return matrix{
+		m[0]*n[0] + m[1]*n[2], m[0]*n[1] + m[1]*n[3],
+		m[2]*n[0] + m[3]*n[2], m[2]*n[1] + m[3]*n[3],
+	}
+}
+
+func (m matrix) orientation() int {
+	if orientation, ok := exifMatrices[m]; ok {
+		return orientation
+	}
+	return 1
+}
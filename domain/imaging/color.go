@@ -0,0 +1,156 @@
+package imaging
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Lab represents a color in the CIE L*a*b* color space (D65 white point)
+type Lab struct {
+	L float64
+	A float64
+	B float64
+}
+
+// RGBToLab converts 8-bit sRGB components to CIE L*a*b*
+func RGBToLab(r, g, b uint8) Lab {
+	rl := linearize(float64(r) / 255)
+	gl := linearize(float64(g) / 255)
+	bl := linearize(float64(b) / 255)
+
+	x := (rl*0.4124564 + gl*0.3575761 + bl*0.1804375) / 0.95047
+	y := (rl*0.2126729 + gl*0.7151522 + bl*0.0721750) / 1.00000
+	z := (rl*0.0193339 + gl*0.1191920 + bl*0.9503041) / 1.08883
+
+	fx, fy, fz := labF(x), labF(y), labF(z)
+	return Lab{
+		L: 116*fy - 16,
+		A: 500 * (fx - fy),
+		B: 200 * (fy - fz),
+	}
+}
+
+// ParseHex parses a #rrggbb (or rrggbb) color string
+func ParseHex(hex string) (r, g, b uint8, err error) {
+	hex = strings.TrimPrefix(strings.TrimSpace(hex), "#")
+	if len(hex) != 6 {
+		return 0, 0, 0, fmt.Errorf("invalid hex color: %s", hex)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid hex color: %s", hex)
+	}
+	return uint8(v >> 16), uint8(v >> 8), uint8(v), nil
+}
+
+// HexToLab parses a hex color string and converts it to CIE L*a*b*
+func HexToLab(hex string) (Lab, error) {
+	r, g, b, err := ParseHex(hex)
+	if err != nil {
+		return Lab{}, err
+	}
+	return RGBToLab(r, g, b), nil
+}
+
+// FormatHex formats 8-bit RGB components as a lowercase #rrggbb string
+func FormatHex(r, g, b uint8) string {
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// CIEDE2000 returns the perceptual distance between two colors.
+// The formula follows Sharma, Wu and Dalal (2005) with kL = kC = kH = 1;
+// migrations/000003_add_media_palette.up.sql mirrors it in SQL.
+func CIEDE2000(c1, c2 Lab) float64 {
+	const pow25_7 = 6103515625.0 // 25^7
+
+	c1ab := math.Hypot(c1.A, c1.B)
+	c2ab := math.Hypot(c2.A, c2.B)
+	cbar7 := math.Pow((c1ab+c2ab)/2, 7)
+	g := 0.5 * (1 - math.Sqrt(cbar7/(cbar7+pow25_7)))
+
+	a1p := (1 + g) * c1.A
+	a2p := (1 + g) * c2.A
+	c1p := math.Hypot(a1p, c1.B)
+	c2p := math.Hypot(a2p, c2.B)
+	h1p := hueAngle(c1.B, a1p)
+	h2p := hueAngle(c2.B, a2p)
+
+	dLp := c2.L - c1.L
+	dCp := c2p - c1p
+
+	var dhp float64
+	if c1p*c2p != 0 {
+		dhp = h2p - h1p
+		if dhp > 180 {
+			dhp -= 360
+		} else if dhp < -180 {
+			dhp += 360
+		}
+	}
+	dHp := 2 * math.Sqrt(c1p*c2p) * math.Sin(deg2rad(dhp/2))
+
+	lbarp := (c1.L + c2.L) / 2
+	cbarp := (c1p + c2p) / 2
+
+	hbarp := h1p + h2p
+	if c1p*c2p != 0 {
+		if math.Abs(h1p-h2p) <= 180 {
+			hbarp /= 2
+		} else if hbarp < 360 {
+			hbarp = (hbarp + 360) / 2
+		} else {
+			hbarp = (hbarp - 360) / 2
+		}
+	}
+
+	t := 1 -
+		0.17*math.Cos(deg2rad(hbarp-30)) +
+		0.24*math.Cos(deg2rad(2*hbarp)) +
+		0.32*math.Cos(deg2rad(3*hbarp+6)) -
+		0.20*math.Cos(deg2rad(4*hbarp-63))
+	dTheta := 30 * math.Exp(-math.Pow((hbarp-275)/25, 2))
+	cbarp7 := math.Pow(cbarp, 7)
+	rc := 2 * math.Sqrt(cbarp7/(cbarp7+pow25_7))
+	lm50 := (lbarp - 50) * (lbarp - 50)
+	sl := 1 + 0.015*lm50/math.Sqrt(20+lm50)
+	sc := 1 + 0.045*cbarp
+	sh := 1 + 0.015*cbarp*t
+	rt := -math.Sin(deg2rad(2*dTheta)) * rc
+
+	dl := dLp / sl
+	dc := dCp / sc
+	dh := dHp / sh
+	return math.Sqrt(dl*dl + dc*dc + dh*dh + rt*dc*dh)
+}
+
+func linearize(c float64) float64 {
+	if c <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Pow((c+0.055)/1.055, 2.4)
+}
+
+func labF(t float64) float64 {
+	const delta = 6.0 / 29.0
+	if t > delta*delta*delta {
+		return math.Cbrt(t)
+	}
+	return t/(3*delta*delta) + 4.0/29.0
+}
+
+func hueAngle(b, a float64) float64 {
+	if a == 0 && b == 0 {
+		return 0
+	}
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+func deg2rad(d float64) float64 {
+	return d * math.Pi / 180
+}
@@ -0,0 +1,94 @@
+package imaging
+
+import (
+	"math"
+	"testing"
+)
+
+// The test pairs of Sharma, Wu and Dalal (2005), "The CIEDE2000
+// Color-Difference Formula: Implementation Notes, Supplementary Test Data,
+// and Mathematical Observations", table 1
+var sharmaPairs = []struct {
+	c1, c2 Lab
+	want   float64
+}{
+	{Lab{50.0000, 2.6772, -79.7751}, Lab{50.0000, 0.0000, -82.7485}, 2.0425},
+	{Lab{50.0000, 3.1571, -77.2803}, Lab{50.0000, 0.0000, -82.7485}, 2.8615},
+	{Lab{50.0000, 2.8361, -74.0200}, Lab{50.0000, 0.0000, -82.7485}, 3.4412},
+	{Lab{50.0000, -1.3802, -84.2814}, Lab{50.0000, 0.0000, -82.7485}, 1.0000},
+	{Lab{50.0000, -1.1848, -84.8006}, Lab{50.0000, 0.0000, -82.7485}, 1.0000},
+	{Lab{50.0000, -0.9009, -85.5211}, Lab{50.0000, 0.0000, -82.7485}, 1.0000},
+	{Lab{50.0000, 0.0000, 0.0000}, Lab{50.0000, -1.0000, 2.0000}, 2.3669},
+	{Lab{50.0000, -1.0000, 2.0000}, Lab{50.0000, 0.0000, 0.0000}, 2.3669},
+	{Lab{50.0000, 2.4900, -0.0010}, Lab{50.0000, -2.4900, 0.0009}, 7.1792},
+	{Lab{50.0000, 2.4900, -0.0010}, Lab{50.0000, -2.4900, 0.0010}, 7.1792},
+	{Lab{50.0000, 2.4900, -0.0010}, Lab{50.0000, -2.4900, 0.0011}, 7.2195},
+	{Lab{50.0000, 2.4900, -0.0010}, Lab{50.0000, -2.4900, 0.0012}, 7.2195},
+	{Lab{50.0000, -0.0010, 2.4900}, Lab{50.0000, 0.0009, -2.4900}, 4.8045},
+	{Lab{50.0000, -0.0010, 2.4900}, Lab{50.0000, 0.0010, -2.4900}, 4.8045},
+	{Lab{50.0000, -0.0010, 2.4900}, Lab{50.0000, 0.0011, -2.4900}, 4.7461},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{50.0000, 0.0000, -2.5000}, 4.3065},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{73.0000, 25.0000, -18.0000}, 27.1492},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{61.0000, -5.0000, 29.0000}, 22.8977},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{56.0000, -27.0000, -3.0000}, 31.9030},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{58.0000, 24.0000, 15.0000}, 19.4535},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{50.0000, 3.1736, 0.5854}, 1.0000},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{50.0000, 3.2972, 0.0000}, 1.0000},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{50.0000, 1.8634, 0.5757}, 1.0000},
+	{Lab{50.0000, 2.5000, 0.0000}, Lab{50.0000, 3.2592, 0.3350}, 1.0000},
+	{Lab{60.2574, -34.0099, 36.2677}, Lab{60.4626, -34.1751, 39.4387}, 1.2644},
+	{Lab{63.0109, -31.0961, -5.8663}, Lab{62.8187, -29.7946, -4.0864}, 1.2630},
+	{Lab{61.2901, 3.7196, -5.3901}, Lab{61.4292, 2.2480, -4.9620}, 1.8731},
+	{Lab{35.0831, -44.1164, 3.7933}, Lab{35.0232, -40.0716, 1.5901}, 1.8645},
+	{Lab{22.7233, 20.0904, -46.6940}, Lab{23.0331, 14.9730, -42.5619}, 2.0373},
+	{Lab{36.4612, 47.8580, 18.3852}, Lab{36.2715, 50.5065, 21.2231}, 1.4146},
+	{Lab{90.8027, -2.0831, 1.4410}, Lab{91.1528, -1.6435, 0.0447}, 1.4441},
+	{Lab{90.9257, -0.5406, -0.9208}, Lab{88.6381, -0.8985, -0.7239}, 1.5381},
+	{Lab{6.7747, -0.2908, -2.4247}, Lab{5.8714, -0.0985, -2.2286}, 0.6377},
+	{Lab{2.0776, 0.0795, -1.1350}, Lab{0.9033, -0.0636, -0.5514}, 0.9082},
+}
+
+func TestCIEDE2000MatchesSharmaPairs(t *testing.T) {
+	for i, pair := range sharmaPairs {
+		// The published differences are rounded to 4 decimals
+		if got := CIEDE2000(pair.c1, pair.c2); math.Abs(got-pair.want) > 5e-5 {
+			t.Errorf("pair %d: CIEDE2000 = %.4f, want %.4f", i+1, got, pair.want)
+		}
+		if got := CIEDE2000(pair.c2, pair.c1); math.Abs(got-pair.want) > 5e-5 {
+			t.Errorf("pair %d swapped: CIEDE2000 = %.4f, want %.4f", i+1, got, pair.want)
+		}
+	}
+}
+
+func TestRGBToLab(t *testing.T) {
+	tests := []struct {
+		hex  string
+		want Lab
+	}{
+		{"#000000", Lab{0, 0, 0}},
+		{"#ffffff", Lab{100, 0, 0}},
+		{"#808080", Lab{53.5850, 0, 0}},
+		{"#ff0000", Lab{53.2408, 80.0925, 67.2032}},
+		{"#0000ff", Lab{32.2970, 79.1875, -107.8602}},
+	}
+	for _, tt := range tests {
+		got, err := HexToLab(tt.hex)
+		if err != nil {
+			t.Fatalf("HexToLab(%s): %v", tt.hex, err)
+		}
+		if math.Abs(got.L-tt.want.L) > 1e-2 || math.Abs(got.A-tt.want.A) > 1e-2 || math.Abs(got.B-tt.want.B) > 1e-2 {
+			t.Errorf("HexToLab(%s) = %+v, want %+v", tt.hex, got, tt.want)
+		}
+	}
+}
+
+func TestParseHex(t *testing.T) {
+	if r, g, b, err := ParseHex(" #1A2b3C "); err != nil || r != 0x1a || g != 0x2b || b != 0x3c {
+		t.Errorf("ParseHex = %d, %d, %d, %v", r, g, b, err)
+	}
+	for _, hex := range []string{"", "#fff", "#12345", "#1234567", "#12345g"} {
+		if _, _, _, err := ParseHex(hex); err == nil {
+			t.Errorf("ParseHex(%q) succeeded", hex)
+		}
+	}
+}
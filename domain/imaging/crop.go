@@ -0,0 +1,145 @@
+package imaging
+
+import (
+	"image"
+	"math"
+
+	"media-service/domain/entity"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	// smartCropGridSize is the number of cells along the long side used for entropy analysis
+	smartCropGridSize = 64
+	// smartCropBins is the number of luminance bins of the entropy histogram
+	smartCropBins = 32
+)
+
+// Thumbnail crops the image to the aspect ratio of width x height around the
+// focal point, or around the most detailed region when focal is nil, then
+// scales it down to fit. Images smaller than the target are never upscaled.
+func Thumbnail(img image.Image, width, height int, focal *entity.FocalPoint) image.Image {
+	if focal == nil {
+		focal = SmartFocalPoint(img, width, height)
+	}
+	rect := FocalCrop(img.Bounds(), width, height, *focal)
+
+	dstW, dstH := width, height
+	if rect.Dx() < dstW {
+		dstW, dstH = rect.Dx(), rect.Dy()
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, max(1, dstW), max(1, dstH)))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, rect, draw.Src, nil)
+	return dst
+}
+
+// Fit scales the image down to fit within maxWidth x maxHeight, keeping its
+// aspect ratio. Images already within the bounds are returned unchanged.
+func Fit(img image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() <= maxWidth && bounds.Dy() <= maxHeight {
+		return img
+	}
+
+	scale := math.Min(float64(maxWidth)/float64(bounds.Dx()), float64(maxHeight)/float64(bounds.Dy()))
+	w := max(1, int(math.Round(float64(bounds.Dx())*scale)))
+	h := max(1, int(math.Round(float64(bounds.Dy())*scale)))
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// FocalCrop returns the largest rectangle with the aspect ratio of width x height
+// that fits in bounds, centered as close to the focal point as the bounds allow
+func FocalCrop(bounds image.Rectangle, width, height int, focal entity.FocalPoint) image.Rectangle {
+	cw, ch := cropSize(bounds, width, height)
+
+	cx := bounds.Min.X + int(math.Round(focal.X*float64(bounds.Dx())))
+	cy := bounds.Min.Y + int(math.Round(focal.Y*float64(bounds.Dy())))
+	x0 := clamp(cx-cw/2, bounds.Min.X, bounds.Max.X-cw)
+	y0 := clamp(cy-ch/2, bounds.Min.Y, bounds.Max.Y-ch)
+	return image.Rect(x0, y0, x0+cw, y0+ch)
+}
+
+// SmartFocalPoint picks the focal point of the crop window with the highest
+// luminance entropy, preferring the most central window on ties
+func SmartFocalPoint(img image.Image, width, height int) *entity.FocalPoint {
+	bounds := img.Bounds()
+	center := &entity.FocalPoint{X: 0.5, Y: 0.5}
+	if bounds.Empty() || width <= 0 || height <= 0 {
+		return center
+	}
+
+	scale := float64(smartCropGridSize) / float64(max(bounds.Dx(), bounds.Dy()))
+	gw := max(1, int(math.Round(float64(bounds.Dx())*scale)))
+	gh := max(1, int(math.Round(float64(bounds.Dy())*scale)))
+	grid := grayGrid(img, gw, gh)
+
+	cw, ch := cropSize(bounds, width, height)
+	ww := clamp(int(math.Round(float64(cw)*scale)), 1, gw)
+	wh := clamp(int(math.Round(float64(ch)*scale)), 1, gh)
+	if ww == gw && wh == gh {
+		return center
+	}
+
+	best, bestDist := -1.0, math.MaxFloat64
+	bestX, bestY := (gw-ww)/2, (gh-wh)/2
+	for y := 0; y+wh <= gh; y++ {
+		for x := 0; x+ww <= gw; x++ {
+			e := windowEntropy(grid, gw, x, y, ww, wh)
+			dist := math.Hypot(float64(x-(gw-ww)/2), float64(y-(gh-wh)/2))
+			if e > best+1e-9 || (math.Abs(e-best) <= 1e-9 && dist < bestDist) {
+				best, bestDist, bestX, bestY = e, dist, x, y
+			}
+		}
+	}
+
+	return &entity.FocalPoint{
+		X: (float64(bestX) + float64(ww)/2) / float64(gw),
+		Y: (float64(bestY) + float64(wh)/2) / float64(gh),
+	}
+}
+
+func windowEntropy(grid []float64, gw, x0, y0, ww, wh int) float64 {
+	var histogram [smartCropBins]int
+	for y := y0; y < y0+wh; y++ {
+		for x := x0; x < x0+ww; x++ {
+			bin := int(grid[y*gw+x] / 65536 * smartCropBins)
+			histogram[clamp(bin, 0, smartCropBins-1)]++
+		}
+	}
+
+	total := float64(ww * wh)
+	var entropy float64
+	for _, count := range histogram {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / total
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+func cropSize(bounds image.Rectangle, width, height int) (int, int) {
+	bw, bh := bounds.Dx(), bounds.Dy()
+	if width <= 0 || height <= 0 {
+		return bw, bh
+	}
+	cw, ch := bw, bw*height/width
+	if ch > bh {
+		cw, ch = bh*width/height, bh
+	}
+	return max(1, cw), max(1, ch)
+}
+
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
@@ -0,0 +1,39 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"io"
+
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/webp"
+)
+
+// Decode decodes an image from the reader using the registered Go decoders and
+// applies its EXIF orientation, so the pixels are upright and re-encoding them
+// needs no orientation tag
+func Decode(r io.Reader) (image.Image, error) {
+	return DecodeOriented(r, OrientationNormal)
+}
+
+// DecodeOriented decodes like Decode, applying fallback when the data carries
+// no orientation, as when its metadata was stripped after reading it
+func DecodeOriented(r io.Reader, fallback int) (image.Image, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	orientation := Orientation(data)
+	if orientation == OrientationNormal {
+		orientation = fallback
+	}
+	return Orient(img, orientation), nil
+}
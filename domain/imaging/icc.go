@@ -0,0 +1,250 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+)
+
+// lutSize is the precision of the linearization tables, over 16-bit samples
+const lutSize = 4096
+
+// xyzD50ToSRGB converts PCS (D50) XYZ to linear sRGB, Bradford adapted
+var xyzD50ToSRGB = [9]float64{
+	3.1338561, -1.6168667, -0.4906146,
+	-0.9787684, 1.9161415, 0.0334540,
+	0.0719453, -0.2289914, 1.4052427,
+}
+
+// srgbToXYZD50 holds the colorant columns of sRGB, as in an sRGB ICC profile
+var srgbToXYZD50 = [9]float64{
+	0.4360747, 0.3850649, 0.1430804,
+	0.2225045, 0.7168786, 0.0606169,
+	0.0139322, 0.0971045, 0.7141733,
+}
+
+// displayP3ToXYZD50 holds the colorant columns of Display P3, whose transfer
+// function is the sRGB one
+var displayP3ToXYZD50 = [9]float64{
+	0.5151, 0.2920, 0.1571,
+	0.2412, 0.6922, 0.0666,
+	-0.0011, 0.0419, 0.7841,
+}
+
+type toneCurve func(float64) float64
+
+// colorProfile is an RGB matrix/TRC profile
+type colorProfile struct {
+	toXYZ  [9]float64
+	curves [3]toneCurve
+}
+
+// ToSRGB converts an image from an ICC matrix/TRC profile to sRGB. Images
+// already in sRGB, or with profiles that are not matrix/TRC based, are
+// returned unchanged.
+func ToSRGB(img image.Image, icc []byte) image.Image {
+	profile, ok := parseICC(icc)
+	if !ok || profile.isSRGB() {
+		return img
+	}
+	return convert(img, profile)
+}
+
+// DisplayP3ToSRGB converts an image from Display P3 to sRGB
+func DisplayP3ToSRGB(img image.Image) image.Image {
+	return convert(img, &colorProfile{
+		toXYZ:  displayP3ToXYZD50,
+		curves: [3]toneCurve{srgbToLinear, srgbToLinear, srgbToLinear},
+	})
+}
+
+func convert(img image.Image, profile *colorProfile) image.Image {
+	var luts [3][lutSize]float64
+	for c := range luts {
+		for i := range luts[c] {
+			luts[c][i] = profile.curves[c](float64(i) / (lutSize - 1))
+		}
+	}
+	m := mul3(xyzD50ToSRGB, profile.toXYZ)
+
+	var encode [lutSize]uint8
+	for i := range encode {
+		encode[i] = uint8(math.Round(linearToSRGB(float64(i)/(lutSize-1)) * 255))
+	}
+	quantize := func(v float64) uint8 {
+		return encode[int(math.Round(math.Max(0, math.Min(1, v))*(lutSize-1)))]
+	}
+
+	bounds := img.Bounds()
+	out := image.NewNRGBA(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBA64Model.Convert(img.At(x, y)).(color.NRGBA64)
+			r := luts[0][int(c.R)*(lutSize-1)/0xffff]
+			g := luts[1][int(c.G)*(lutSize-1)/0xffff]
+			b := luts[2][int(c.B)*(lutSize-1)/0xffff]
+			out.SetNRGBA(x, y, color.NRGBA{
+				R: quantize(m[0]*r + m[1]*g + m[2]*b),
+				G: quantize(m[3]*r + m[4]*g + m[5]*b),
+				B: quantize(m[6]*r + m[7]*g + m[8]*b),
+				A: uint8(c.A >> 8),
+			})
+		}
+	}
+	return out
+}
+
+func (p *colorProfile) isSRGB() bool {
+	for i := range p.toXYZ {
+		if math.Abs(p.toXYZ[i]-srgbToXYZD50[i]) > 0.002 {
+			return false
+		}
+	}
+	for _, curve := range p.curves {
+		if math.Abs(curve(0.5)-srgbToLinear(0.5)) > 0.005 {
+			return false
+		}
+	}
+	return true
+}
+
+// parseICC reads the colorants and tone curves of an RGB matrix/TRC profile
+func parseICC(data []byte) (*colorProfile, bool) {
+	if len(data) < 132 || string(data[36:40]) != "acsp" || string(data[16:20]) != "RGB " {
+		return nil, false
+	}
+	tags := make(map[string][]byte)
+	count := int(binary.BigEndian.Uint32(data[128:]))
+	for i := 0; i < count; i++ {
+		entry := 132 + i*12
+		if entry+12 > len(data) {
+			return nil, false
+		}
+		offset := int(binary.BigEndian.Uint32(data[entry+4:]))
+		size := int(binary.BigEndian.Uint32(data[entry+8:]))
+		if offset < 0 || size < 0 || offset+size > len(data) {
+			return nil, false
+		}
+		tags[string(data[entry:entry+4])] = data[offset : offset+size]
+	}
+
+	profile := &colorProfile{}
+	for i, name := range []string{"rXYZ", "gXYZ", "bXYZ"} {
+		tag := tags[name]
+		if len(tag) < 20 || string(tag[:4]) != "XYZ " {
+			return nil, false
+		}
+		for j := 0; j < 3; j++ {
+			profile.toXYZ[j*3+i] = s15Fixed16(tag[8+j*4:])
+		}
+	}
+	for i, name := range []string{"rTRC", "gTRC", "bTRC"} {
+		curve, ok := parseCurve(tags[name])
+		if !ok {
+			return nil, false
+		}
+		profile.curves[i] = curve
+	}
+	return profile, true
+}
+
+func parseCurve(tag []byte) (toneCurve, bool) {
+	if len(tag) < 12 {
+		return nil, false
+	}
+	switch string(tag[:4]) {
+	case "curv":
+		n := int(binary.BigEndian.Uint32(tag[8:]))
+		if len(tag) < 12+n*2 {
+			return nil, false
+		}
+		switch n {
+		case 0:
+			return func(v float64) float64 { return v }, true
+		case 1:
+			gamma := float64(binary.BigEndian.Uint16(tag[12:])) / 256
+			return func(v float64) float64 { return math.Pow(v, gamma) }, true
+		}
+		table := make([]float64, n)
+		for i := range table {
+			table[i] = float64(binary.BigEndian.Uint16(tag[12+i*2:])) / 0xffff
+		}
+		return func(v float64) float64 {
+			pos := v * float64(n-1)
+			i := int(pos)
+			if i >= n-1 {
+				return table[n-1]
+			}
+			return table[i] + (table[i+1]-table[i])*(pos-float64(i))
+		}, true
+	case "para":
+		kind := binary.BigEndian.Uint16(tag[8:])
+		arity := map[uint16]int{0: 1, 1: 3, 2: 4, 3: 5, 4: 7}[kind]
+		if arity == 0 || len(tag) < 12+arity*4 {
+			return nil, false
+		}
+		p := make([]float64, 7)
+		for i := 0; i < arity; i++ {
+			p[i] = s15Fixed16(tag[12+i*4:])
+		}
+		g, a, b, c, d, e, f := p[0], p[1], p[2], p[3], p[4], p[5], p[6]
+		return func(v float64) float64 {
+			switch kind {
+			case 0:
+				return math.Pow(v, g)
+			case 1:
+				if v >= -b/a {
+					return math.Pow(a*v+b, g)
+				}
+				return 0
+			case 2:
+				if v >= -b/a {
+					return math.Pow(a*v+b, g) + c
+				}
+				return c
+			case 3:
+				if v >= d {
+					return math.Pow(a*v+b, g)
+				}
+				return c * v
+			default:
+				if v >= d {
+					return math.Pow(a*v+b, g) + e
+				}
+				return c*v + f
+			}
+		}, true
+	}
+	return nil, false
+}
+
+func s15Fixed16(b []byte) float64 {
+	return float64(int32(binary.BigEndian.Uint32(b))) / 65536
+}
+
+func mul3(a, b [9]float64) [9]float64 {
+	var m [9]float64
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			for k := 0; k < 3; k++ {
+				m[i*3+j] += a[i*3+k] * b[k*3+j]
+			}
+		}
+	}
+	return m
+}
+
+func srgbToLinear(v float64) float64 {
+	if v <= 0.04045 {
+		return v / 12.92
+	}
+	return math.Pow((v+0.055)/1.055, 2.4)
+}
+
+func linearToSRGB(v float64) float64 {
+	if v <= 0.0031308 {
+		return v * 12.92
+	}
+	return 1.055*math.Pow(v, 1/2.4) - 0.055
+}
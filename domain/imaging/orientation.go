@@ -0,0 +1,169 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// EXIF orientations, naming how the stored pixels must be transformed for display
+const (
+	OrientationNormal     = 1
+	OrientationFlipH      = 2
+	OrientationRotate180  = 3
+	OrientationFlipV      = 4
+	OrientationTranspose  = 5
+	OrientationRotate90   = 6 // clockwise
+	OrientationTransverse = 7
+	OrientationRotate270  = 8 // clockwise
+)
+
+const (
+	exifOrientationTag     = 0x0112
+	exifHeader             = "Exif\x00\x00"
+	tiffLittleEndianHeader = "II*\x00"
+	tiffBigEndianHeader    = "MM\x00*"
+)
+
+// SwapsAxes reports whether displaying with the orientation swaps width and height
+func SwapsAxes(orientation int) bool {
+	return orientation >= OrientationTranspose && orientation <= OrientationRotate270
+}
+
+// Orientation returns the EXIF orientation of JPEG, PNG or WebP data, or
+// OrientationNormal when there is none
+func Orientation(data []byte) int {
+	tiff := findEXIF(data)
+	if tiff == nil {
+		return OrientationNormal
+	}
+	tiff = bytes.TrimPrefix(tiff, []byte(exifHeader))
+	if len(tiff) < 8 {
+		return OrientationNormal
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:4]) {
+	case tiffLittleEndianHeader:
+		order = binary.LittleEndian
+	case tiffBigEndianHeader:
+		order = binary.BigEndian
+	default:
+		return OrientationNormal
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return OrientationNormal
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			break
+		}
+		if order.Uint16(tiff[entry:]) != exifOrientationTag {
+			continue
+		}
+		value := int(order.Uint16(tiff[entry+8:]))
+		if value < OrientationNormal || value > OrientationRotate270 {
+			return OrientationNormal
+		}
+		return value
+	}
+	return OrientationNormal
+}
+
+// findEXIF returns the EXIF payload of JPEG (APP1), PNG (eXIf) or WebP (EXIF) data
+func findEXIF(data []byte) []byte {
+	switch {
+	case bytes.HasPrefix(data, jpegSOI):
+		pos := len(jpegSOI)
+		for pos+4 <= len(data) && data[pos] == 0xFF {
+			marker := data[pos+1]
+			if marker == 0xDA {
+				return nil
+			}
+			end := pos + 2 + int(binary.BigEndian.Uint16(data[pos+2:]))
+			if end > len(data) {
+				return nil
+			}
+			if marker == 0xE1 && bytes.HasPrefix(data[pos+4:end], []byte(exifHeader)) {
+				return data[pos+4 : end]
+			}
+			pos = end
+		}
+	case bytes.HasPrefix(data, pngSignature):
+		pos := len(pngSignature)
+		for pos+12 <= len(data) {
+			end := pos + 12 + int(binary.BigEndian.Uint32(data[pos:]))
+			if end > len(data) {
+				return nil
+			}
+			switch string(data[pos+4 : pos+8]) {
+			case "eXIf":
+				return data[pos+8 : end-4]
+			case "IDAT":
+				return nil
+			}
+			pos = end
+		}
+	case len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		pos := 12
+		for pos+8 <= len(data) {
+			size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+			if pos+8+size > len(data) {
+				return nil
+			}
+			if string(data[pos:pos+4]) == "EXIF" {
+				return data[pos+8 : pos+8+size]
+			}
+			pos += 8 + size + size%2
+		}
+	}
+	return nil
+}
+
+// Orient transforms the pixels so that they display upright without the
+// orientation tag, swapping width and height for rotations by 90 degrees
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation <= OrientationNormal || orientation > OrientationRotate270 {
+		return img
+	}
+
+	b := img.Bounds()
+	src := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	w, h := b.Dx(), b.Dy()
+
+	dw, dh := w, h
+	if SwapsAxes(orientation) {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case OrientationFlipH:
+				sx, sy = w-1-x, y
+			case OrientationRotate180:
+				sx, sy = w-1-x, h-1-y
+			case OrientationFlipV:
+				sx, sy = x, h-1-y
+			case OrientationTranspose:
+				sx, sy = y, x
+			case OrientationRotate90:
+				sx, sy = y, h-1-x
+			case OrientationTransverse:
+				sx, sy = w-1-y, h-1-x
+			case OrientationRotate270:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
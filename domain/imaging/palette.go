@@ -0,0 +1,117 @@
+package imaging
+
+import (
+	"image"
+	"sort"
+
+	"media-service/domain/entity"
+)
+
+const (
+	// paletteSampleSize is the number of pixels sampled along each axis
+	paletteSampleSize = 96
+	// paletteMergeDistance is the CIEDE2000 distance under which buckets are merged
+	paletteMergeDistance = 12.0
+)
+
+type colorBucket struct {
+	r, g, b uint64
+	count   uint64
+}
+
+func (c *colorBucket) add(o *colorBucket) {
+	c.r += o.r
+	c.g += o.g
+	c.b += o.b
+	c.count += o.count
+}
+
+func (c *colorBucket) rgb() (uint8, uint8, uint8) {
+	return uint8(c.r / c.count), uint8(c.g / c.count), uint8(c.b / c.count)
+}
+
+func (c *colorBucket) lab() Lab {
+	return RGBToLab(c.rgb())
+}
+
+// ExtractPalette returns up to size dominant colors of the image ordered by share.
+// Pixels are sampled on a grid, quantized to a 4-bit-per-channel histogram and
+// perceptually similar buckets are merged before the largest are kept.
+func ExtractPalette(img image.Image, size int) []entity.PaletteColor {
+	bounds := img.Bounds()
+	if bounds.Empty() || size <= 0 {
+		return nil
+	}
+
+	stepX := max(1, bounds.Dx()/paletteSampleSize)
+	stepY := max(1, bounds.Dy()/paletteSampleSize)
+
+	histogram := make(map[uint16]*colorBucket)
+	var total uint64
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += stepY {
+		for x := bounds.Min.X; x < bounds.Max.X; x += stepX {
+			r, g, b, a := img.At(x, y).RGBA()
+			if a < 0x8000 {
+				continue // Ignore mostly transparent pixels
+			}
+			r8, g8, b8 := uint64(r>>8), uint64(g>>8), uint64(b>>8)
+			key := uint16(r8>>4)<<8 | uint16(g8>>4)<<4 | uint16(b8>>4)
+			bucket, ok := histogram[key]
+			if !ok {
+				bucket = &colorBucket{}
+				histogram[key] = bucket
+			}
+			bucket.add(&colorBucket{r: r8, g: g8, b: b8, count: 1})
+			total++
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	buckets := make([]*colorBucket, 0, len(histogram))
+	for _, bucket := range histogram {
+		buckets = append(buckets, bucket)
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		if buckets[i].count != buckets[j].count {
+			return buckets[i].count > buckets[j].count
+		}
+		return buckets[i].lab().L > buckets[j].lab().L
+	})
+
+	var clusters []*colorBucket
+	for _, bucket := range buckets {
+		merged := false
+		for _, cluster := range clusters {
+			if CIEDE2000(cluster.lab(), bucket.lab()) < paletteMergeDistance {
+				cluster.add(bucket)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			clusters = append(clusters, &colorBucket{r: bucket.r, g: bucket.g, b: bucket.b, count: bucket.count})
+		}
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].count > clusters[j].count
+	})
+
+	if len(clusters) > size {
+		clusters = clusters[:size]
+	}
+	palette := make([]entity.PaletteColor, 0, len(clusters))
+	for _, cluster := range clusters {
+		r, g, b := cluster.rgb()
+		lab := RGBToLab(r, g, b)
+		palette = append(palette, entity.PaletteColor{
+			Hex:   FormatHex(r, g, b),
+			Share: float64(cluster.count) / float64(total),
+			L:     lab.L,
+			A:     lab.A,
+			B:     lab.B,
+		})
+	}
+	return palette
+}
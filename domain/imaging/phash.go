@@ -0,0 +1,97 @@
+package imaging
+
+import (
+	"image"
+	"math/bits"
+)
+
+// DHash computes a 64-bit difference hash of the image.
+// The image is reduced to a 9x8 grayscale grid and each bit records whether a
+// cell is brighter than its right neighbour, so re-encoded, resized or lightly
+// cropped copies produce hashes a few bits apart.
+func DHash(img image.Image) uint64 {
+	const w, h = 9, 8
+	grid := grayGrid(img, w, h)
+
+	var hash uint64
+	for y := 0; y < h; y++ {
+		for x := 0; x < w-1; x++ {
+			hash <<= 1
+			if grid[y*w+x] > grid[y*w+x+1] {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+// HashBands is the number of 16-bit bands a hash is split into to search it
+// by multi-index hashing
+const HashBands = 4
+
+// HashBandCandidates lists, for each 16-bit band of a hash from the lowest,
+// the band values at most maxDistance/HashBands bits away. By the pigeonhole
+// principle a hash within maxDistance of it has at least one band among
+// them, so hashes matching none can be skipped without comparing them.
+func HashBandCandidates(hash uint64, maxDistance int) [HashBands][]int {
+	radius := min(maxDistance/HashBands, 16)
+	var candidates [HashBands][]int
+	for band := range candidates {
+		value := uint16(hash >> (16 * band))
+		candidates[band] = flipBits(nil, value, 0, radius)
+	}
+	return candidates
+}
+
+// flipBits appends value and every value obtained by flipping up to radius
+// of its bits from bit from upwards
+func flipBits(values []int, value uint16, from, radius int) []int {
+	values = append(values, int(value))
+	if radius == 0 {
+		return values
+	}
+	for bit := from; bit < 16; bit++ {
+		values = flipBits(values, value^(1<<bit), bit+1, radius-1)
+	}
+	return values
+}
+
+// HammingDistance returns the number of differing bits between two hashes
+func HammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// grayGrid averages the luminance of the image over a w x h grid of cells
+func grayGrid(img image.Image, w, h int) []float64 {
+	bounds := img.Bounds()
+	grid := make([]float64, w*h)
+	if bounds.Empty() {
+		return grid
+	}
+
+	for gy := 0; gy < h; gy++ {
+		y0 := bounds.Min.Y + gy*bounds.Dy()/h
+		y1 := max(y0+1, bounds.Min.Y+(gy+1)*bounds.Dy()/h)
+		for gx := 0; gx < w; gx++ {
+			x0 := bounds.Min.X + gx*bounds.Dx()/w
+			x1 := max(x0+1, bounds.Min.X+(gx+1)*bounds.Dx()/w)
+
+			// Sample at most 16x16 pixels per cell to keep large images cheap
+			stepX := max(1, (x1-x0)/16)
+			stepY := max(1, (y1-y0)/16)
+			var sum float64
+			var count int
+			for y := y0; y < y1 && y < bounds.Max.Y; y += stepY {
+				for x := x0; x < x1 && x < bounds.Max.X; x += stepX {
+					r, g, b, _ := img.At(x, y).RGBA()
+					sum += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+					count++
+				}
+			}
+			if count > 0 {
+				grid[gy*w+gx] = sum / float64(count)
+			}
+		}
+	}
+	return grid
+}
@@ -0,0 +1,40 @@
+package imaging
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func TestHashBandCandidatesCount(t *testing.T) {
+	// 1 + C(16,1) + C(16,2) values per band within 2 bits
+	for band, values := range HashBandCandidates(0x0123456789abcdef, 10) {
+		if len(values) != 137 {
+			t.Errorf("band %d has %d candidates, want 137", band, len(values))
+		}
+	}
+}
+
+// Every hash within the distance shares a band with the candidates
+func TestHashBandCandidatesFindNeighbours(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for _, distance := range []int{0, 3, 4, 10, 24} {
+		hash := random.Uint64()
+		candidates := HashBandCandidates(hash, distance)
+		for range 200 {
+			other := hash
+			for _, bit := range random.Perm(64)[:distance] {
+				other ^= 1 << bit
+			}
+			found := false
+			for band, values := range candidates {
+				if slices.Contains(values, int(uint16(other>>(16*band)))) {
+					found = true
+				}
+			}
+			if !found {
+				t.Fatalf("hash %016x at distance %d of %016x matches no band", other, distance, hash)
+			}
+		}
+	}
+}
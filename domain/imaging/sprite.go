@@ -0,0 +1,25 @@
+package imaging
+
+import (
+	"image"
+
+	"golang.org/x/image/draw"
+)
+
+// TileSheet lays the frames out left to right, top to bottom, in a grid of
+// tileWidth x tileHeight cells, scaling frames of another size into their cell
+func TileSheet(frames []image.Image, columns, tileWidth, tileHeight int) *image.RGBA {
+	rows := (len(frames) + columns - 1) / columns
+	width := min(len(frames), columns) * tileWidth
+	sheet := image.NewRGBA(image.Rect(0, 0, max(1, width), max(1, rows*tileHeight)))
+	for i, frame := range frames {
+		x, y := (i%columns)*tileWidth, (i/columns)*tileHeight
+		cell := image.Rect(x, y, x+tileWidth, y+tileHeight)
+		if frame.Bounds().Size() == cell.Size() {
+			draw.Draw(sheet, cell, frame, frame.Bounds().Min, draw.Src)
+			continue
+		}
+		draw.ApproxBiLinear.Scale(sheet, cell, frame, frame.Bounds(), draw.Src, nil)
+	}
+	return sheet
+}
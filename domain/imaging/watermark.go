@@ -0,0 +1,89 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+)
+
+// Watermark positions
+const (
+	PositionTopLeft     = "top-left"
+	PositionTopRight    = "top-right"
+	PositionBottomLeft  = "bottom-left"
+	PositionBottomRight = "bottom-right"
+	PositionCenter      = "center"
+)
+
+// WatermarkOptions controls how an overlay is composited onto an image
+type WatermarkOptions struct {
+	Position string  // One of the Position* constants, ignored when tiling
+	Opacity  float64 // 0..1
+	Scale    float64 // Overlay width as a fraction of the image width
+	Tile     bool    // Repeat the overlay over the whole image
+	Margin   int     // Pixels between the overlay and the edges, or between tiles
+}
+
+// ValidPosition reports whether the position is one of the Position* constants
+func ValidPosition(position string) bool {
+	switch position {
+	case PositionTopLeft, PositionTopRight, PositionBottomLeft, PositionBottomRight, PositionCenter:
+		return true
+	}
+	return false
+}
+
+// Watermark returns a copy of img with the overlay composited on top of it
+func Watermark(img image.Image, overlay image.Image, opts WatermarkOptions) image.Image {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+
+	ow := max(1, int(math.Round(float64(bounds.Dx())*opts.Scale)))
+	ob := overlay.Bounds()
+	oh := max(1, ob.Dy()*ow/max(1, ob.Dx()))
+	mark := image.NewRGBA(image.Rect(0, 0, ow, oh))
+	draw.CatmullRom.Scale(mark, mark.Bounds(), overlay, ob, draw.Src, nil)
+
+	mask := image.NewUniform(color.Alpha{A: uint8(math.Round(clampFloat(opts.Opacity, 0, 1) * 255))})
+	for _, at := range watermarkOrigins(dst.Bounds(), mark.Bounds(), opts) {
+		r := image.Rectangle{Min: at, Max: at.Add(mark.Bounds().Size())}
+		draw.DrawMask(dst, r, mark, image.Point{}, mask, image.Point{}, draw.Over)
+	}
+	return dst
+}
+
+func watermarkOrigins(dst, mark image.Rectangle, opts WatermarkOptions) []image.Point {
+	w, h := mark.Dx(), mark.Dy()
+	if opts.Tile {
+		var points []image.Point
+		stepX, stepY := w+opts.Margin, h+opts.Margin
+		for y := opts.Margin; y < dst.Dy(); y += stepY {
+			for x := opts.Margin; x < dst.Dx(); x += stepX {
+				points = append(points, image.Pt(x, y))
+			}
+		}
+		return points
+	}
+
+	left, top := opts.Margin, opts.Margin
+	right, bottom := dst.Dx()-w-opts.Margin, dst.Dy()-h-opts.Margin
+	switch opts.Position {
+	case PositionTopLeft:
+		return []image.Point{image.Pt(left, top)}
+	case PositionTopRight:
+		return []image.Point{image.Pt(right, top)}
+	case PositionBottomLeft:
+		return []image.Point{image.Pt(left, bottom)}
+	case PositionCenter:
+		return []image.Point{image.Pt((dst.Dx()-w)/2, (dst.Dy()-h)/2)}
+	default:
+		return []image.Point{image.Pt(right, bottom)}
+	}
+}
+
+func clampFloat(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
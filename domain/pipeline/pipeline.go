@@ -0,0 +1,137 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/entity"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Pipeline runs the step graph of a MIME family
+type Pipeline struct {
+	family string
+	nodes  []*node
+}
+
+type node struct {
+	name     string
+	step     Step
+	spec     Spec
+	optional bool
+	deps     []int
+}
+
+func (n *node) uses(key string) bool {
+	return slices.Contains(n.spec.Inputs, key) ||
+		slices.Contains(n.spec.Optional, key) ||
+		slices.Contains(n.spec.Outputs, key)
+}
+
+func (p *Pipeline) lastWriter(key string, before int) int {
+	for j := before - 1; j >= 0; j-- {
+		if slices.Contains(p.nodes[j].spec.Outputs, key) {
+			return j
+		}
+	}
+	return -1
+}
+
+// Family returns the MIME family the pipeline processes
+func (p *Pipeline) Family() string {
+	return p.family
+}
+
+// Run executes every step once its dependencies are done, independent steps in
+// parallel. The first failure of a required step stops the run: steps not yet
+// started are skipped and the failure is returned with the report.
+func (p *Pipeline) Run(ctx context.Context, state *State) (*entity.PipelineRun, error) {
+	run := &entity.PipelineRun{
+		Family:    p.family,
+		Status:    entity.PipelineRunStatusSucceeded,
+		Steps:     make([]entity.PipelineStepResult, len(p.nodes)),
+		StartedAt: time.Now(),
+	}
+
+	var (
+		mu       sync.Mutex
+		abort    error
+		finished int
+		wg       sync.WaitGroup
+	)
+	done := make([]chan struct{}, len(p.nodes))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+
+	for i, n := range p.nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[i])
+			for _, dep := range n.deps {
+				<-done[dep]
+			}
+
+			mu.Lock()
+			aborted := abort != nil
+			mu.Unlock()
+
+			result, err := p.runNode(ctx, state, n, aborted)
+			run.Steps[i] = result
+			mu.Lock()
+			if err != nil && !n.optional && abort == nil {
+				abort = err
+			}
+			finished++
+			count := finished
+			mu.Unlock()
+			state.notify(result, count, len(p.nodes))
+		}()
+	}
+	wg.Wait()
+
+	run.DurationMs = time.Since(run.StartedAt).Milliseconds()
+	if abort != nil {
+		run.Status = entity.PipelineRunStatusFailed
+		run.Error = abort.Error()
+	}
+	return run, abort
+}
+
+func (p *Pipeline) runNode(ctx context.Context, state *State, n *node, aborted bool) (result entity.PipelineStepResult, err error) {
+	result = entity.PipelineStepResult{
+		Name:      n.name,
+		Status:    entity.PipelineStepStatusSkipped,
+		StartedAt: time.Now(),
+	}
+	if aborted {
+		result.Error = "pipeline aborted"
+		return result, nil
+	}
+	for _, key := range n.spec.Inputs {
+		if !state.Has(key) {
+			result.Error = "missing input: " + key
+			return result, fmt.Errorf("step %s: %s", n.name, result.Error)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		result.Error = err.Error()
+		return result, err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("step %s panicked: %v", n.name, r)
+		}
+		result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+		if err != nil {
+			result.Status = entity.PipelineStepStatusFailed
+			result.Error = err.Error()
+			return
+		}
+		result.Status = entity.PipelineStepStatusSucceeded
+	}()
+	return result, n.step.Run(ctx, state)
+}
@@ -0,0 +1,306 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"media-service/domain/entity"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testStep is a step of the given spec running fn, a no-op when nil
+type testStep struct {
+	spec Spec
+	fn   func(ctx context.Context, state *State) error
+}
+
+func (s *testStep) Spec() Spec { return s.spec }
+
+func (s *testStep) Run(ctx context.Context, state *State) error {
+	if s.fn == nil {
+		return nil
+	}
+	return s.fn(ctx, state)
+}
+
+// build registers the steps and builds a pipeline of them in the given order
+func build(t *testing.T, steps map[string]*testStep, configs ...StepConfig) *Pipeline {
+	t.Helper()
+	registry := NewRegistry()
+	for name, step := range steps {
+		if err := registry.Register(name, func(map[string]string) (Step, error) { return step, nil }); err != nil {
+			t.Fatalf("Register(%s): %v", name, err)
+		}
+	}
+	p, err := registry.Build("image", configs)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	return p
+}
+
+func steps(names ...string) []StepConfig {
+	configs := make([]StepConfig, len(names))
+	for i, name := range names {
+		configs[i] = StepConfig{Name: name}
+	}
+	return configs
+}
+
+// deps returns the names of the steps a step waits for, sorted
+func deps(p *Pipeline, name string) []string {
+	var names []string
+	for _, n := range p.nodes {
+		if n.name != name {
+			continue
+		}
+		for _, dep := range n.deps {
+			names = append(names, p.nodes[dep].name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// results indexes the step results of a run by step name
+func results(run *entity.PipelineRun) map[string]entity.PipelineStepResult {
+	byName := make(map[string]entity.PipelineStepResult, len(run.Steps))
+	for _, result := range run.Steps {
+		byName[result.Name] = result
+	}
+	return byName
+}
+
+func TestBuildDerivesDependencies(t *testing.T) {
+	p := build(t, map[string]*testStep{
+		"decode":  {spec: Spec{Inputs: []string{"source"}, Outputs: []string{"image"}}},
+		"hash":    {spec: Spec{Inputs: []string{"image"}, Outputs: []string{"hash"}}},
+		"resize":  {spec: Spec{Inputs: []string{"image"}, Outputs: []string{"image"}}},
+		"palette": {spec: Spec{Inputs: []string{"image"}, Outputs: []string{"palette"}}},
+		"encode":  {spec: Spec{Inputs: []string{"source"}, Optional: []string{"image"}, Outputs: []string{"stored"}}},
+		"notify":  {},
+	}, append(steps("decode", "hash", "resize", "palette", "encode"), StepConfig{Name: "notify", After: []string{"hash"}})...)
+
+	tests := map[string][]string{
+		"decode":  nil,
+		"hash":    {"decode"},
+		"resize":  {"decode", "hash"}, // Rewrites the image hash reads
+		"palette": {"resize"},
+		"encode":  {"resize"}, // Optional inputs order like required ones
+		"notify":  {"hash"},
+	}
+	for name, want := range tests {
+		if got := deps(p, name); !slices.Equal(got, want) {
+			t.Errorf("%s waits for %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestBuildRejectsInvalidConfigs(t *testing.T) {
+	registry := NewRegistry()
+	for _, name := range []string{"a", "b"} {
+		registry.Register(name, func(map[string]string) (Step, error) { return &testStep{}, nil })
+	}
+	registry.Register("broken", func(map[string]string) (Step, error) { return nil, errors.New("bad params") })
+
+	tests := map[string][]StepConfig{
+		"unknown step":        steps("a", "c"),
+		"step twice":          steps("a", "b", "a"),
+		"after a later step":  {{Name: "a", After: []string{"b"}}, {Name: "b", After: []string{"a"}}},
+		"after itself":        {{Name: "a", After: []string{"a"}}},
+		"after a missing one": {{Name: "a", After: []string{"c"}}},
+		"factory error":       steps("a", "broken"),
+	}
+	for name, configs := range tests {
+		if _, err := registry.Build("image", configs); err == nil {
+			t.Errorf("Build with %s succeeded", name)
+		}
+	}
+}
+
+func TestRunOrdersStepsByDependencies(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) func(context.Context, *State) error {
+		return func(context.Context, *State) error {
+			mu.Lock()
+			defer mu.Unlock()
+			order = append(order, name)
+			return nil
+		}
+	}
+	p := build(t, map[string]*testStep{
+		"decode": {spec: Spec{Outputs: []string{"image"}}, fn: func(ctx context.Context, state *State) error {
+			time.Sleep(10 * time.Millisecond) // Gives dependents a chance to run early
+			Set(state, Key[int]("image"), 1)
+			return record("decode")(ctx, state)
+		}},
+		"resize": {spec: Spec{Inputs: []string{"image"}, Outputs: []string{"image"}}, fn: record("resize")},
+		"encode": {spec: Spec{Inputs: []string{"image"}, Outputs: []string{"stored"}}, fn: record("encode")},
+	}, steps("decode", "resize", "encode")...)
+
+	run, err := p.Run(context.Background(), NewState())
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if want := []string{"decode", "resize", "encode"}; !slices.Equal(order, want) {
+		t.Errorf("steps ran in order %v, want %v", order, want)
+	}
+	if run.Status != entity.PipelineRunStatusSucceeded {
+		t.Errorf("run status = %s", run.Status)
+	}
+}
+
+func TestRunRunsIndependentStepsInParallel(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(2)
+	// Each step waits for the other one to start, which only a parallel run completes
+	meet := func(ctx context.Context, _ *State) error {
+		started.Done()
+		wait := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(wait)
+		}()
+		select {
+		case <-wait:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	p := build(t, map[string]*testStep{
+		"palette": {spec: Spec{Outputs: []string{"palette"}}, fn: meet},
+		"hash":    {spec: Spec{Outputs: []string{"hash"}}, fn: meet},
+	}, steps("palette", "hash")...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := p.Run(ctx, NewState()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+}
+
+func TestRunFailsOnMissingInput(t *testing.T) {
+	ran := false
+	p := build(t, map[string]*testStep{
+		"encode": {spec: Spec{Inputs: []string{"source"}, Outputs: []string{"stored"}}, fn: func(context.Context, *State) error {
+			ran = true
+			return nil
+		}},
+	}, steps("encode")...)
+
+	run, err := p.Run(context.Background(), NewState())
+	if err == nil || !strings.Contains(err.Error(), "missing input: source") {
+		t.Fatalf("Run = %v, want a missing input error", err)
+	}
+	if ran {
+		t.Error("step ran without its input")
+	}
+	if got := results(run)["encode"].Status; got != entity.PipelineStepStatusSkipped {
+		t.Errorf("step status = %s, want skipped", got)
+	}
+	if run.Status != entity.PipelineRunStatusFailed {
+		t.Errorf("run status = %s, want failed", run.Status)
+	}
+}
+
+func TestRunOptionalFailureDoesNotAbort(t *testing.T) {
+	p := build(t, map[string]*testStep{
+		"palette": {spec: Spec{Outputs: []string{"palette"}}, fn: func(context.Context, *State) error {
+			return errors.New("no colors")
+		}},
+		"encode": {spec: Spec{Optional: []string{"palette"}, Outputs: []string{"stored"}}},
+	}, StepConfig{Name: "palette", Optional: true}, StepConfig{Name: "encode"})
+
+	run, err := p.Run(context.Background(), NewState())
+	if err != nil {
+		t.Fatalf("Run = %v, want the optional failure ignored", err)
+	}
+	byName := results(run)
+	if got := byName["palette"]; got.Status != entity.PipelineStepStatusFailed || got.Error != "no colors" {
+		t.Errorf("palette = %s %q, want failed with its error", got.Status, got.Error)
+	}
+	if got := byName["encode"].Status; got != entity.PipelineStepStatusSucceeded {
+		t.Errorf("encode status = %s, want succeeded", got)
+	}
+	if run.Status != entity.PipelineRunStatusSucceeded {
+		t.Errorf("run status = %s, want succeeded", run.Status)
+	}
+}
+
+func TestRunRequiredFailureAborts(t *testing.T) {
+	failure := errors.New("corrupt file")
+	ran := false
+	p := build(t, map[string]*testStep{
+		"decode": {spec: Spec{Outputs: []string{"image"}}, fn: func(context.Context, *State) error { return failure }},
+		"resize": {spec: Spec{Optional: []string{"image"}, Outputs: []string{"thumbnail"}}, fn: func(context.Context, *State) error {
+			ran = true
+			return nil
+		}},
+	}, steps("decode", "resize")...)
+
+	run, err := p.Run(context.Background(), NewState())
+	if !errors.Is(err, failure) {
+		t.Fatalf("Run = %v, want %v", err, failure)
+	}
+	if ran {
+		t.Error("a step ran after the run aborted")
+	}
+	byName := results(run)
+	if got := byName["decode"].Status; got != entity.PipelineStepStatusFailed {
+		t.Errorf("decode status = %s, want failed", got)
+	}
+	if got := byName["resize"]; got.Status != entity.PipelineStepStatusSkipped || got.Error != "pipeline aborted" {
+		t.Errorf("resize = %s %q, want skipped by the abort", got.Status, got.Error)
+	}
+	if run.Status != entity.PipelineRunStatusFailed || run.Error != failure.Error() {
+		t.Errorf("run = %s %q, want failed with %q", run.Status, run.Error, failure)
+	}
+}
+
+func TestRunStopsOnCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ran := false
+	p := build(t, map[string]*testStep{
+		"decode": {spec: Spec{Outputs: []string{"image"}}, fn: func(context.Context, *State) error {
+			cancel()
+			return nil
+		}},
+		"resize": {spec: Spec{Optional: []string{"image"}, Outputs: []string{"thumbnail"}}, fn: func(context.Context, *State) error {
+			ran = true
+			return nil
+		}},
+	}, steps("decode", "resize")...)
+
+	run, err := p.Run(ctx, NewState())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run = %v, want context.Canceled", err)
+	}
+	if ran {
+		t.Error("a step started after the cancellation")
+	}
+	if got := results(run)["resize"].Status; got != entity.PipelineStepStatusSkipped {
+		t.Errorf("resize status = %s, want skipped", got)
+	}
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	p := build(t, map[string]*testStep{
+		"decode": {spec: Spec{Outputs: []string{"image"}}, fn: func(context.Context, *State) error { panic("nil image") }},
+	}, steps("decode")...)
+
+	run, err := p.Run(context.Background(), NewState())
+	if err == nil || !strings.Contains(err.Error(), "panicked: nil image") {
+		t.Fatalf("Run = %v, want the panic as an error", err)
+	}
+	if got := results(run)["decode"].Status; got != entity.PipelineStepStatusFailed {
+		t.Errorf("decode status = %s, want failed", got)
+	}
+}
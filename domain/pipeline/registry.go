@@ -0,0 +1,114 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Spec declares the state keys a step reads and writes. The engine derives
+// the step graph from it: a step runs after the last earlier step writing
+// one of its inputs, and after every earlier step using one of its outputs.
+type Spec struct {
+	Inputs   []string // Required; a missing one fails the step, aborting the run unless the step is optional
+	Optional []string // Read when present
+	Outputs  []string
+}
+
+// Step is a unit of processing of a pipeline
+type Step interface {
+	Spec() Spec
+	Run(ctx context.Context, state *State) error
+}
+
+// Factory builds a step from its configured parameters
+type Factory func(params map[string]string) (Step, error)
+
+// StepConfig places a registered step in the graph of a MIME family
+type StepConfig struct {
+	Name     string
+	After    []string          // Extra ordering on earlier steps, besides data dependencies
+	Optional bool              // A failure is recorded without failing the run
+	Params   map[string]string // Passed to the step factory
+}
+
+// Registry holds the step implementations available to pipelines
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// NewRegistry creates an empty step registry
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]Factory)}
+}
+
+// Register adds a step implementation under a name
+func (r *Registry) Register(name string, factory Factory) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.factories[name]; ok {
+		return fmt.Errorf("step %s is already registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// Has reports whether a step is registered under the name
+func (r *Registry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.factories[name]
+	return ok
+}
+
+// Build instantiates the configured steps of a family and links them into a graph
+func (r *Registry) Build(family string, configs []StepConfig) (*Pipeline, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p := &Pipeline{family: family}
+	index := make(map[string]int, len(configs))
+	for i, config := range configs {
+		factory, ok := r.factories[config.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s pipeline: unknown step %s", family, config.Name)
+		}
+		if _, ok := index[config.Name]; ok {
+			return nil, fmt.Errorf("%s pipeline: step %s is configured twice", family, config.Name)
+		}
+		step, err := factory(config.Params)
+		if err != nil {
+			return nil, fmt.Errorf("%s pipeline: step %s: %w", family, config.Name, err)
+		}
+
+		n := &node{name: config.Name, step: step, spec: step.Spec(), optional: config.Optional}
+		deps := make(map[int]bool)
+		for _, name := range config.After {
+			j, ok := index[name]
+			if !ok {
+				return nil, fmt.Errorf("%s pipeline: step %s must come after %s, which is not an earlier step", family, config.Name, name)
+			}
+			deps[j] = true
+		}
+		for _, key := range append(append([]string{}, n.spec.Inputs...), n.spec.Optional...) {
+			if j := p.lastWriter(key, i); j >= 0 {
+				deps[j] = true
+			}
+		}
+		for _, key := range n.spec.Outputs {
+			for j := 0; j < i; j++ {
+				if p.nodes[j].uses(key) {
+					deps[j] = true
+				}
+			}
+		}
+		for j := range deps {
+			n.deps = append(n.deps, j)
+		}
+
+		index[config.Name] = i
+		p.nodes = append(p.nodes, n)
+	}
+	return p, nil
+}
@@ -0,0 +1,88 @@
+package pipeline
+
+import (
+	"media-service/domain/entity"
+	"sync"
+)
+
+// Key names a value of the run state and fixes its type
+type Key[T any] string
+
+func (k Key[T]) String() string {
+	return string(k)
+}
+
+// State holds the values produced and consumed by the steps of a run.
+// It is safe for use by steps running in parallel.
+type State struct {
+	mu       sync.RWMutex
+	values   map[string]any
+	cleanups []func()
+	observer StepObserver
+}
+
+// StepObserver is notified as each step of a run finishes, with the number of
+// steps finished so far out of total
+type StepObserver func(result entity.PipelineStepResult, finished, total int)
+
+// NewState creates an empty run state
+func NewState() *State {
+	return &State{values: make(map[string]any)}
+}
+
+// Get returns the value of the key and whether it is set
+func Get[T any](s *State, key Key[T]) (T, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[string(key)].(T)
+	return value, ok
+}
+
+// Set stores the value of the key, replacing any previous one
+func Set[T any](s *State, key Key[T], value T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[string(key)] = value
+}
+
+// Has reports whether the key named name is set
+func (s *State) Has(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.values[name]
+	return ok
+}
+
+// Defer registers a cleanup, such as removing a temp file, run by Close
+func (s *State) Defer(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cleanups = append(s.cleanups, fn)
+}
+
+// OnStep registers the observer of the steps of the run
+func (s *State) OnStep(fn StepObserver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observer = fn
+}
+
+func (s *State) notify(result entity.PipelineStepResult, finished, total int) {
+	s.mu.RLock()
+	observer := s.observer
+	s.mu.RUnlock()
+	if observer != nil {
+		observer(result, finished, total)
+	}
+}
+
+// Close runs the registered cleanups in reverse order
+func (s *State) Close() {
+	s.mu.Lock()
+	cleanups := s.cleanups
+	s.cleanups = nil
+	s.mu.Unlock()
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanups[i]()
+	}
+}
@@ -0,0 +1,39 @@
+package progress
+
+import (
+	"context"
+	"media-service/domain/entity"
+	"time"
+)
+
+// Event reports the processing progress of a media
+type Event struct {
+	MediaID string                  `json:"media_id"`
+	Status  entity.ProcessingStatus `json:"status"`
+	Stage   string                  `json:"stage,omitempty"` // Step being run, empty for status changes
+	Percent int                     `json:"percent"`
+	Reason  string                  `json:"reason,omitempty"` // Error of failed attempts
+	At      time.Time               `json:"at"`
+}
+
+// Final reports whether the media reached a status it only leaves on a new request
+func (e *Event) Final() bool {
+	return e.Status == entity.ProcessingStatusCompleted || e.Status == entity.ProcessingStatusFailed
+}
+
+// Publisher broadcasts events to every subscriber, whichever replica they are connected to
+type Publisher interface {
+	Publish(ctx context.Context, event *Event) error
+}
+
+// Subscriber receives the events of media
+type Subscriber interface {
+	// Subscribe returns the events of the given media until ctx is done, when the channel is closed
+	Subscribe(ctx context.Context, mediaIDs []string) (<-chan *Event, error)
+}
+
+// Broker publishes and subscribes to events
+type Broker interface {
+	Publisher
+	Subscriber
+}
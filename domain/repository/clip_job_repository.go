@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"media-service/domain/entity"
+)
+
+type ClipJobRepository interface {
+	Create(ctx context.Context, job *entity.ClipJob) error
+
+	GetByID(ctx context.Context, id string) (*entity.ClipJob, error)
+
+	// UpdateStatus records the progress of the job: its status, the created clip and the error
+	UpdateStatus(ctx context.Context, job *entity.ClipJob) error
+}
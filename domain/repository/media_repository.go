@@ -2,9 +2,15 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"media-service/domain/entity"
+	"time"
 )
 
+// ErrFileChanged is returned by MoveFiles when a row no longer references the
+// file it was read with
+var ErrFileChanged = errors.New("file reference changed")
+
 type MediaRepository interface {
 	Create(ctx context.Context, media *entity.Media) error
 
@@ -18,17 +24,89 @@ type MediaRepository interface {
 
 	List(ctx context.Context, filters MediaFilters) ([]*entity.Media, int, error)
 
-	UpdateProcessingStatus(ctx context.Context, id string, status entity.ProcessingStatus) error
+	// UpdateProcessingStatus saves the processing status, attempts and last error
+	// of a media still in status from; it returns entity.ErrInvalidTransition
+	// when the media moved on concurrently
+	UpdateProcessingStatus(ctx context.Context, media *entity.Media, from entity.ProcessingStatus) error
+
+	// GetPendingProcessing lists media pending or processing, unchanged since
+	// before the given time, least recently updated first
+	GetPendingProcessing(ctx context.Context, updatedBefore time.Time, limit int) ([]*entity.Media, error)
+
+	// GetFileReferences lists the stored files referenced by media, variants
+	// and tracks, ordered by source and row ID, starting after the given
+	// reference (nil for the first page)
+	GetFileReferences(ctx context.Context, after *entity.FileReference, limit int) ([]*entity.FileReference, error)
+
+	// CountMediaOnBackend counts the media with a file, of their own or of a
+	// variant or track, stored in the backend
+	CountMediaOnBackend(ctx context.Context, backend string) (int, error)
+
+	// GetMediaIDsOnBackend lists by ID the media after the given one with a
+	// file stored in the backend
+	GetMediaIDsOnBackend(ctx context.Context, backend, after string, limit int) ([]string, error)
+
+	// GetMediaFileReferences lists the stored files of a media, its variants and tracks
+	GetMediaFileReferences(ctx context.Context, mediaID string) ([]*entity.FileReference, error)
+
+	// MoveFiles points rows at copies of their files, all of them or none;
+	// it returns ErrFileChanged when a row changed since it was read
+	MoveFiles(ctx context.Context, moves []*FileMove) error
+
+	// GetLifecycleCandidates lists by ID the settled media a lifecycle rule selects
+	GetLifecycleCandidates(ctx context.Context, filters LifecycleFilters) ([]string, error)
+
+	// UpdateStorageTier records the tier the files of a media were moved to,
+	// ending a restore
+	UpdateStorageTier(ctx context.Context, id, tier string) error
 
-	GetPendingProcessing(ctx context.Context, limit int) ([]*entity.Media, error)
+	// MarkRestoring starts a restore of the media unless one started after
+	// staleBefore; it returns false when one is in progress
+	MarkRestoring(ctx context.Context, id string, staleBefore time.Time) (bool, error)
+
+	// TouchAccess records a delivery of the media unless one was recorded
+	// after recordedAfter
+	TouchAccess(ctx context.Context, id string, at, recordedAfter time.Time) error
+
+	FindSimilar(ctx context.Context, filters SimilarMediaFilters) ([]*entity.Media, error)
+
+	SetMisoriented(ctx context.Context, id string, flagged bool) error
 }
 
 type MediaFilters struct {
-	CreatedBy string
-	Type      entity.MediaType
-	MimeType  string
-	Limit     int
-	Offset    int
-	SortBy    string // created_at, name, size
-	SortOrder string // asc, desc
+	CreatedBy   string
+	Type        entity.MediaType
+	MimeType    string
+	Color       string  // hex color, e.g. #336699
+	ColorDist   float64 // max CIEDE2000 distance from Color
+	Search      string  // full-text query over extracted document text
+	Misoriented bool    // only media flagged by rotation detection
+	Limit       int
+	Offset      int
+	SortBy      string // created_at, name, size
+	SortOrder   string // asc, desc
+}
+
+// LifecycleFilters select the media a lifecycle rule moves
+type LifecycleFilters struct {
+	Backends       []string // Backends holding the originals
+	Types          []entity.MediaType
+	CreatedBefore  *time.Time
+	AccessedBefore *time.Time // Media never delivered count as accessed when uploaded
+	Limit          int
+}
+
+// FileMove points the row of a file reference at a copy of its file
+type FileMove struct {
+	From    *entity.FileReference
+	Backend string
+	URL     string
+}
+
+type SimilarMediaFilters struct {
+	PHash       int64
+	MaxDistance int    // max Hamming distance between hashes
+	CreatedBy   string // empty searches every owner
+	ExcludeID   string
+	Limit       int
 }
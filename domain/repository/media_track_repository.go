@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"media-service/domain/entity"
+)
+
+type MediaTrackRepository interface {
+	Create(ctx context.Context, track *entity.MediaTrack) error
+
+	GetByID(ctx context.Context, id string) (*entity.MediaTrack, error)
+
+	GetByMediaID(ctx context.Context, mediaID string) ([]*entity.MediaTrack, error)
+
+	Delete(ctx context.Context, id string) error
+}
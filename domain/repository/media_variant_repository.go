@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"media-service/domain/entity"
+)
+
+type MediaVariantRepository interface {
+	// Upsert creates the variants or replaces the existing ones with the same media ID and name
+	Upsert(ctx context.Context, variants []*entity.MediaVariant) error
+
+	GetByMediaID(ctx context.Context, mediaID string) ([]*entity.MediaVariant, error)
+
+	DeleteByMediaID(ctx context.Context, mediaID string) error
+
+	// DeleteByNames deletes the named variants of the media
+	DeleteByNames(ctx context.Context, mediaID string, names []string) error
+
+	// GetMediaIDsByStaleWatermark returns media whose variant was rendered with another watermark key
+	GetMediaIDsByStaleWatermark(ctx context.Context, name, watermark string) ([]string, error)
+}
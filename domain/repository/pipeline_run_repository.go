@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"context"
+	"media-service/domain/entity"
+)
+
+type PipelineRunRepository interface {
+	Create(ctx context.Context, run *entity.PipelineRun) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"media-service/domain/entity"
+)
+
+type ProcessingJobRepository interface {
+	Create(ctx context.Context, job *entity.ProcessingJob) error
+
+	// Finish records the status, error and timings of a finished attempt
+	Finish(ctx context.Context, job *entity.ProcessingJob) error
+
+	// GetByMediaID lists the attempts of a media, most recent first
+	GetByMediaID(ctx context.Context, mediaID string, limit int) ([]*entity.ProcessingJob, error)
+}
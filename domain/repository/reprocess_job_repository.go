@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"media-service/domain/entity"
+)
+
+type ReprocessJobRepository interface {
+	Create(ctx context.Context, job *entity.ReprocessJob) error
+
+	GetByID(ctx context.Context, id string) (*entity.ReprocessJob, error)
+
+	// RecordResult counts one more finished media and completes the job with the last one
+	RecordResult(ctx context.Context, id string, failure error) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"media-service/domain/entity"
+)
+
+type StorageMigrationRepository interface {
+	Create(ctx context.Context, migration *entity.StorageMigration) error
+
+	GetByID(ctx context.Context, id string) (*entity.StorageMigration, error)
+
+	// Update saves the status, position and counters of a migration
+	Update(ctx context.Context, migration *entity.StorageMigration) error
+}
@@ -0,0 +1,241 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"media-service/domain/video"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Cue is a timed block of text
+type Cue struct {
+	Start    float64 // Seconds
+	End      float64
+	Settings string // WebVTT cue settings (position, align...)
+	Text     string
+}
+
+var (
+	vttTiming = regexp.MustCompile(`^((?:\d+:)?\d{2}:\d{2}\.\d{3})[ \t]+-->[ \t]+((?:\d+:)?\d{2}:\d{2}\.\d{3})(?:[ \t]+(.*))?$`)
+	srtTiming = regexp.MustCompile(`^(\d+:\d{2}:\d{2}[,.]\d{1,3})[ \t]*-->[ \t]*(\d+:\d{2}:\d{2}[,.]\d{1,3})`)
+	// Formatting SRT players honor that WebVTT has no equivalent for
+	srtFont     = regexp.MustCompile(`(?i)</?font[^>]*>`)
+	srtOverride = regexp.MustCompile(`\{\\[^}]*\}`)
+	vttTag      = regexp.MustCompile(`(?i)^</?(i|b|u)>`)
+	entity      = regexp.MustCompile(`^&(amp|lt|gt|nbsp|lrm|rlm);`)
+)
+
+// ToWebVTT validates SRT or WebVTT data and returns it as WebVTT: WebVTT is
+// kept as-is, SRT is converted
+func ToWebVTT(data []byte) ([]byte, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("subtitles are not UTF-8 encoded")
+	}
+	text := strings.ReplaceAll(strings.ReplaceAll(string(data), "\r\n", "\n"), "\r", "\n")
+
+	if strings.HasPrefix(text, "WEBVTT") {
+		if _, err := ParseVTT(text); err != nil {
+			return nil, err
+		}
+		return []byte(text), nil
+	}
+
+	cues, err := ParseSRT(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := WriteVTT(&buf, cues); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseVTT validates a WebVTT file and returns its cues
+func ParseVTT(text string) ([]Cue, error) {
+	blocks := splitBlocks(text)
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("empty WebVTT file")
+	}
+	header := blocks[0][0]
+	if header != "WEBVTT" && !strings.HasPrefix(header, "WEBVTT ") && !strings.HasPrefix(header, "WEBVTT\t") {
+		return nil, fmt.Errorf("missing WEBVTT header")
+	}
+
+	var cues []Cue
+	lastStart := 0.0
+	for _, block := range blocks[1:] {
+		if isVTTDefinition(block[0]) {
+			continue
+		}
+		lines := block
+		if !strings.Contains(lines[0], "-->") {
+			lines = lines[1:] // Cue identifier
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("cue %q has no timing", block[0])
+		}
+		match := vttTiming.FindStringSubmatch(lines[0])
+		if match == nil {
+			return nil, fmt.Errorf("invalid cue timing %q", lines[0])
+		}
+		cue := Cue{Settings: match[3], Text: strings.Join(lines[1:], "\n")}
+		cue.Start, _ = parseTimestamp(match[1])
+		cue.End, _ = parseTimestamp(match[2])
+		if cue.End <= cue.Start {
+			return nil, fmt.Errorf("cue %s ends before it starts", lines[0])
+		}
+		if cue.Start < lastStart {
+			return nil, fmt.Errorf("cue %s starts before the previous one", lines[0])
+		}
+		lastStart = cue.Start
+		cues = append(cues, cue)
+	}
+	return cues, nil
+}
+
+// ParseSRT parses a SubRip file; cues are sorted by start time and their
+// formatting reduced to what WebVTT supports
+func ParseSRT(text string) ([]Cue, error) {
+	var cues []Cue
+	for _, block := range splitBlocks(text) {
+		lines := block
+		if _, err := strconv.Atoi(strings.TrimSpace(lines[0])); err == nil {
+			lines = lines[1:] // Sequence number
+		}
+		if len(lines) == 0 {
+			return nil, fmt.Errorf("cue %s has no timing", block[0])
+		}
+		match := srtTiming.FindStringSubmatch(lines[0])
+		if match == nil {
+			return nil, fmt.Errorf("invalid cue timing %q", lines[0])
+		}
+		start, err := parseTimestamp(match[1])
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseTimestamp(match[2])
+		if err != nil {
+			return nil, err
+		}
+		if end <= start {
+			return nil, fmt.Errorf("cue %s ends before it starts", lines[0])
+		}
+		cues = append(cues, Cue{Start: start, End: end, Text: srtText(lines[1:])})
+	}
+	if len(cues) == 0 {
+		return nil, fmt.Errorf("no cues")
+	}
+	sort.SliceStable(cues, func(i, j int) bool { return cues[i].Start < cues[j].Start })
+	return cues, nil
+}
+
+// WriteVTT writes the cues as a WebVTT file
+func WriteVTT(w io.Writer, cues []Cue) error {
+	if _, err := io.WriteString(w, "WEBVTT\n"); err != nil {
+		return err
+	}
+	for _, cue := range cues {
+		timing := video.Timestamp(cue.Start) + " --> " + video.Timestamp(cue.End)
+		if cue.Settings != "" {
+			timing += " " + cue.Settings
+		}
+		if _, err := fmt.Fprintf(w, "\n%s\n%s\n", timing, cue.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitBlocks splits on blank lines, dropping trailing spaces of each line
+func splitBlocks(text string) [][]string {
+	var (
+		blocks  [][]string
+		current []string
+	)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, current)
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, current)
+	}
+	return blocks
+}
+
+func isVTTDefinition(line string) bool {
+	for _, keyword := range []string{"NOTE", "STYLE", "REGION"} {
+		if line == keyword || strings.HasPrefix(line, keyword+" ") || strings.HasPrefix(line, keyword+"\t") {
+			return true
+		}
+	}
+	return false
+}
+
+// srtText strips unsupported formatting and escapes the markup characters
+// that are not part of a WebVTT tag or entity; "-->" cannot appear in a cue
+func srtText(lines []string) string {
+	text := strings.Join(lines, "\n")
+	text = srtFont.ReplaceAllString(text, "")
+	text = srtOverride.ReplaceAllString(text, "")
+	text = strings.ReplaceAll(text, "-->", "->")
+
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '&':
+			if entity.MatchString(text[i:]) {
+				b.WriteByte('&')
+			} else {
+				b.WriteString("&amp;")
+			}
+		case '<':
+			if tag := vttTag.FindString(text[i:]); tag != "" {
+				b.WriteString(strings.ToLower(tag))
+				i += len(tag) - 1
+			} else {
+				b.WriteString("&lt;")
+			}
+		default:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
+
+// parseTimestamp parses [hh:]mm:ss.ttt, with a comma or a dot before the fraction
+func parseTimestamp(value string) (float64, error) {
+	value = strings.Replace(value, ",", ".", 1)
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+	if err != nil || seconds >= 60 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	minutes, err := strconv.Atoi(parts[len(parts)-2])
+	if err != nil || minutes >= 60 {
+		return 0, fmt.Errorf("invalid timestamp %q", value)
+	}
+	hours := 0
+	if len(parts) == 3 {
+		if hours, err = strconv.Atoi(parts[0]); err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", value)
+		}
+	}
+	return float64(hours*3600+minutes*60) + seconds, nil
+}
@@ -0,0 +1,98 @@
+package subtitle
+
+import (
+	"testing"
+)
+
+func TestToWebVTTConvertsSRT(t *testing.T) {
+	tests := []struct {
+		name string
+		srt  string
+		want string
+	}{
+		{
+			name: "comma fractions",
+			srt:  "1\n00:00:01,000 --> 00:00:02,500\nHello\n",
+			want: "WEBVTT\n\n00:00:01.000 --> 00:00:02.500\nHello\n",
+		},
+		{
+			name: "byte order mark and CRLF",
+			srt:  "\xef\xbb\xbf1\r\n00:00:01,000 --> 00:00:02,000\r\nHello\r\n\r\n2\r\n00:00:03,000 --> 00:00:04,000\r\nWorld\r\n",
+			want: "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nHello\n\n00:00:03.000 --> 00:00:04.000\nWorld\n",
+		},
+		{
+			name: "multi-line cue",
+			srt:  "1\n00:00:01,000 --> 00:00:02,000\nFirst line\nSecond line\n\n2\n00:00:02,000 --> 00:00:03,000\nNext\n",
+			want: "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nFirst line\nSecond line\n\n00:00:02.000 --> 00:00:03.000\nNext\n",
+		},
+		{
+			name: "short fractions, dots and hours",
+			srt:  "1\n01:02:03,5 --> 01:02:04.25\nLate\n",
+			want: "WEBVTT\n\n01:02:03.500 --> 01:02:04.250\nLate\n",
+		},
+		{
+			name: "missing sequence numbers and extra blank lines",
+			srt:  "\n\n00:00:01,000 --> 00:00:02,000\nA\n\n\n\n00:00:03,000 --> 00:00:04,000  \nB\n\n",
+			want: "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nA\n\n00:00:03.000 --> 00:00:04.000\nB\n",
+		},
+		{
+			name: "cues out of order",
+			srt:  "2\n00:00:05,000 --> 00:00:06,000\nSecond\n\n1\n00:00:01,000 --> 00:00:02,000\nFirst\n",
+			want: "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nFirst\n\n00:00:05.000 --> 00:00:06.000\nSecond\n",
+		},
+		{
+			name: "formatting",
+			srt:  "1\n00:00:01,000 --> 00:00:02,000\n<I>Tom</I> & <font color=\"red\">Jerry</font> {\\an8}<3 &amp; -->\n",
+			want: "WEBVTT\n\n00:00:01.000 --> 00:00:02.000\n<i>Tom</i> &amp; Jerry &lt;3 &amp; ->\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToWebVTT([]byte(tt.srt))
+			if err != nil {
+				t.Fatalf("ToWebVTT: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ToWebVTT =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToWebVTTKeepsWebVTT(t *testing.T) {
+	vtt := "WEBVTT - captions\n\nNOTE made by hand\n\nSTYLE\n::cue { color: yellow }\n\nintro\n00:01.000 --> 00:02.000 align:start\nHello\nthere\n"
+	got, err := ToWebVTT([]byte("\xef\xbb\xbf" + vtt))
+	if err != nil {
+		t.Fatalf("ToWebVTT: %v", err)
+	}
+	if string(got) != vtt {
+		t.Errorf("ToWebVTT = %q, want the file unchanged", got)
+	}
+
+	cues, err := ParseVTT(vtt)
+	if err != nil {
+		t.Fatalf("ParseVTT: %v", err)
+	}
+	want := Cue{Start: 1, End: 2, Settings: "align:start", Text: "Hello\nthere"}
+	if len(cues) != 1 || cues[0] != want {
+		t.Errorf("ParseVTT = %+v, want [%+v]", cues, want)
+	}
+}
+
+func TestToWebVTTRejectsInvalidFiles(t *testing.T) {
+	tests := map[string]string{
+		"empty":                "",
+		"not UTF-8":            "1\n00:00:01,000 --> 00:00:02,000\n\xff\xfe\n",
+		"no timing":            "1\nHello\n",
+		"ends before start":    "1\n00:00:02,000 --> 00:00:01,000\nHello\n",
+		"seconds out of range": "1\n00:00:61,000 --> 00:01:02,000\nHello\n",
+		"vtt without header":   "WEBVTTX\n\n00:01.000 --> 00:02.000\nHello\n",
+		"vtt bad timing":       "WEBVTT\n\n00:01,000 --> 00:02,000\nHello\n",
+		"vtt out of order":     "WEBVTT\n\n00:05.000 --> 00:06.000\nB\n\n00:01.000 --> 00:02.000\nA\n",
+	}
+	for name, data := range tests {
+		if _, err := ToWebVTT([]byte(data)); err == nil {
+			t.Errorf("ToWebVTT with %s succeeded", name)
+		}
+	}
+}
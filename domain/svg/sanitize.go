@@ -0,0 +1,227 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// forbiddenElements are removed with their whole subtree
+var forbiddenElements = map[string]bool{
+	"script":        true,
+	"foreignObject": true,
+	"iframe":        true,
+	"embed":         true,
+	"object":        true,
+	"audio":         true,
+	"video":         true,
+	"handler":       true,
+	"listener":      true,
+}
+
+// animationElements can set attributes, so they are removed when they target
+// a link or an event handler
+var animationElements = map[string]bool{
+	"set":              true,
+	"animate":          true,
+	"animateMotion":    true,
+	"animateTransform": true,
+}
+
+var (
+	cssURL     = regexp.MustCompile(`url\(\s*['"]?\s*([^'")\s]*)`)
+	cssComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	cssEscape  = regexp.MustCompile(`(?s)\\(?:([0-9a-fA-F]{1,6})(?:\r\n|[ \t\r\n\f])?|\r\n|(.))`)
+)
+
+// unsafeCSSTokens load external content or run code wherever they appear
+var unsafeCSSTokens = []string{"@import", "expression(", "image-set(", "src(", "behavior:", "-moz-binding"}
+
+// Sanitize rewrites an SVG without scripts, event handlers, external
+// references, foreignObject content, comments, processing instructions and
+// DTDs. Links may only point inside the document or to embedded raster images.
+func Sanitize(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	out.WriteString(xml.Header)
+
+	var (
+		stack    []xml.Name
+		skip     int // depth inside a removed element
+		seenRoot bool
+		inStyle  bool
+	)
+	for {
+		token, err := decoder.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid svg: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if !seenRoot {
+				if t.Name.Local != "svg" {
+					return nil, fmt.Errorf("invalid svg: root element is %s", t.Name.Local)
+				}
+				seenRoot = true
+			} else if len(stack) == 0 {
+				return nil, fmt.Errorf("invalid svg: content after the root element")
+			}
+			stack = append(stack, t.Name)
+			if skip > 0 || removedElement(t) {
+				skip++
+				continue
+			}
+			inStyle = t.Name.Local == "style"
+			writeStart(&out, t)
+
+		case xml.EndElement:
+			if len(stack) == 0 || stack[len(stack)-1] != t.Name {
+				return nil, fmt.Errorf("invalid svg: unexpected </%s>", t.Name.Local)
+			}
+			stack = stack[:len(stack)-1]
+			inStyle = false
+			if skip > 0 {
+				skip--
+				continue
+			}
+			out.WriteString("</")
+			writeName(&out, t.Name)
+			out.WriteString(">")
+
+		case xml.CharData:
+			if skip > 0 || len(stack) == 0 {
+				continue
+			}
+			if inStyle && unsafeCSS(string(t)) {
+				continue
+			}
+			_ = xml.EscapeText(&out, t)
+
+		case xml.Comment, xml.ProcInst, xml.Directive:
+			// Dropped: comments may hide conditional content, DTDs may declare entities
+		}
+	}
+	if !seenRoot || len(stack) != 0 {
+		return nil, fmt.Errorf("invalid svg: unterminated document")
+	}
+	return out.Bytes(), nil
+}
+
+func removedElement(t xml.StartElement) bool {
+	if forbiddenElements[t.Name.Local] {
+		return true
+	}
+	if animationElements[t.Name.Local] {
+		for _, attr := range t.Attr {
+			if attr.Name.Local != "attributeName" {
+				continue
+			}
+			target := strings.ToLower(strings.TrimSpace(attr.Value))
+			if strings.HasSuffix(target, "href") || strings.HasPrefix(target, "on") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeStart(out *bytes.Buffer, t xml.StartElement) {
+	out.WriteString("<")
+	writeName(out, t.Name)
+	for _, attr := range t.Attr {
+		if !safeAttr(attr) {
+			continue
+		}
+		out.WriteString(" ")
+		writeName(out, attr.Name)
+		out.WriteString(`="`)
+		_ = xml.EscapeText(out, []byte(attr.Value))
+		out.WriteString(`"`)
+	}
+	out.WriteString(">")
+}
+
+func writeName(out *bytes.Buffer, name xml.Name) {
+	if name.Space != "" {
+		out.WriteString(name.Space)
+		out.WriteString(":")
+	}
+	out.WriteString(name.Local)
+}
+
+func safeAttr(attr xml.Attr) bool {
+	local := strings.ToLower(attr.Name.Local)
+	if strings.HasPrefix(local, "on") {
+		return false
+	}
+	if local == "href" || local == "src" {
+		return safeReference(attr.Value)
+	}
+	// Presentation attributes such as fill take url() references like style does
+	return !unsafeCSS(attr.Value)
+}
+
+// safeReference accepts fragment links and embedded raster images
+func safeReference(value string) bool {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "#") {
+		return true
+	}
+	lower := strings.ToLower(value)
+	for _, prefix := range []string{"data:image/png", "data:image/jpeg", "data:image/gif", "data:image/webp"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unsafeCSS reports CSS loading external content, matched once comments and
+// escapes are resolved so that u\72l( or @\69mport cannot hide it
+func unsafeCSS(css string) bool {
+	css = strings.ToLower(unescapeCSS(cssComment.ReplaceAllString(css, "")))
+	for _, token := range unsafeCSSTokens {
+		if strings.Contains(css, token) {
+			return true
+		}
+	}
+	for _, match := range cssURL.FindAllStringSubmatch(css, -1) {
+		if !safeReference(match[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// unescapeCSS resolves the backslash escapes of CSS: hexadecimal code points,
+// escaped characters and escaped newlines
+func unescapeCSS(css string) string {
+	if !strings.Contains(css, "\\") {
+		return css
+	}
+	return cssEscape.ReplaceAllStringFunc(css, func(escape string) string {
+		match := cssEscape.FindStringSubmatch(escape)
+		if match[1] != "" {
+			code, _ := strconv.ParseUint(match[1], 16, 32)
+			if code == 0 || code > unicode.MaxRune || (code >= 0xD800 && code <= 0xDFFF) {
+				return string(unicode.ReplacementChar)
+			}
+			return string(rune(code))
+		}
+		if strings.ContainsAny(match[2], "\r\n\f") {
+			return "" // Line continuation
+		}
+		return match[2]
+	})
+}
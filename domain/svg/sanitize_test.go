@@ -0,0 +1,136 @@
+package svg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeRemovesActiveContent(t *testing.T) {
+	tests := []struct {
+		name   string
+		svg    string
+		absent []string // Substrings the sanitized document must not contain
+		kept   []string // Substrings it must still contain
+	}{
+		{
+			name:   "script",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script><rect width="1"/></svg>`,
+			absent: []string{"script", "alert"},
+			kept:   []string{`<rect width="1">`},
+		},
+		{
+			name:   "foreignObject",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><foreignObject><div xmlns="http://www.w3.org/1999/xhtml">x</div></foreignObject></svg>`,
+			absent: []string{"foreignObject", "div"},
+		},
+		{
+			name:   "event handlers",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"><rect ONCLICK="alert(2)" onMouseOver="alert(3)" width="1"/></svg>`,
+			absent: []string{"alert"},
+			kept:   []string{`<rect width="1">`},
+		},
+		{
+			name:   "external href",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink"><a href="javascript:alert(1)"><use xlink:href="https://evil/x.svg#a"/></a></svg>`,
+			absent: []string{"javascript", "evil"},
+		},
+		{
+			name:   "uppercase url in presentation attribute",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><rect fill="URL(https://evil/x)" width="1"/></svg>`,
+			absent: []string{"evil"},
+			kept:   []string{`width="1"`},
+		},
+		{
+			name:   "escaped url in style attribute",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><rect style="fill: u\72l(https://evil/x)"/></svg>`,
+			absent: []string{"evil"},
+		},
+		{
+			name:   "escaped import in style element",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><style>@\69mport "https://evil/x.css";</style></svg>`,
+			absent: []string{"evil"},
+		},
+		{
+			name:   "url split by a comment",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><style>rect { fill: url/**/(https://evil/x) }</style></svg>`,
+			absent: []string{"evil"},
+		},
+		{
+			name:   "animation of href with trailing space",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><a><set attributeName="href " to="javascript:alert(1)"/></a></svg>`,
+			absent: []string{"set", "javascript"},
+		},
+		{
+			name:   "animation of an event handler",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><animate attributeName=" OnBegin" values="alert(1)"/></svg>`,
+			absent: []string{"animate", "alert"},
+		},
+		{
+			name:   "svg data image",
+			svg:    `<svg xmlns="http://www.w3.org/2000/svg"><image href="data:image/svg+xml;base64,PHN2Zz4="/></svg>`,
+			absent: []string{"data:"},
+		},
+		{
+			name:   "comments and doctype",
+			svg:    `<!DOCTYPE svg [<!ENTITY x "y">]><svg xmlns="http://www.w3.org/2000/svg"><!-- hidden --><rect/></svg>`,
+			absent: []string{"DOCTYPE", "ENTITY", "hidden"},
+		},
+		{
+			name: "internal references",
+			svg:  `<svg xmlns="http://www.w3.org/2000/svg"><style>rect { fill: url(#g) }</style><rect fill="url(#g)"/><use href="#r"/><image href="data:image/png;base64,iVBORw0KGgo="/></svg>`,
+			kept: []string{`fill: url(#g)`, `fill="url(#g)"`, `href="#r"`, `href="data:image/png;base64,iVBORw0KGgo="`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Sanitize([]byte(tt.svg))
+			if err != nil {
+				t.Fatalf("Sanitize: %v", err)
+			}
+			for _, s := range tt.absent {
+				if strings.Contains(string(out), s) {
+					t.Errorf("sanitized svg contains %q: %s", s, out)
+				}
+			}
+			for _, s := range tt.kept {
+				if !strings.Contains(string(out), s) {
+					t.Errorf("sanitized svg lost %q: %s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestSanitizeRejectsInvalidDocuments(t *testing.T) {
+	for _, svg := range []string{
+		`<html><svg/></html>`,
+		`<svg><rect></svg>`,
+		`<svg/><svg/>`,
+		`not xml`,
+	} {
+		if _, err := Sanitize([]byte(svg)); err == nil {
+			t.Errorf("Sanitize(%q) succeeded", svg)
+		}
+	}
+}
+
+func TestUnescapeCSS(t *testing.T) {
+	tests := map[string]string{
+		`u\72l(`:        "url(",
+		`u\000072 l(`:   "url(",
+		`@\69mport`:     "@import",
+		`@\49 mport`:    "@Import",
+		`\@import`:      "@import",
+		"ur\\\nl(":      "url(",
+		`plain`:         "plain",
+		`\0`:            "�",
+		`\110000 x`:     "�x",
+		`a\\b`:          `a\b`,
+		`content: "\""`: `content: """`,
+	}
+	for in, want := range tests {
+		if got := unescapeCSS(in); got != want {
+			t.Errorf("unescapeCSS(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
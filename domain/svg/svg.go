@@ -0,0 +1,105 @@
+package svg
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"image"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// sniffLength is how far into a file the root svg element is looked for
+const sniffLength = 1024
+
+// Info is the intrinsic size of an SVG, in user units
+type Info struct {
+	Width  float64
+	Height float64
+}
+
+// Rasterizer renders an SVG file to an image of the given size
+type Rasterizer interface {
+	Rasterize(ctx context.Context, path string, width, height int) (image.Image, error)
+}
+
+// IsSVG sniffs the start of the file for an svg root element and rewinds it
+func IsSVG(r io.ReadSeeker) (bool, error) {
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+
+	head := bytes.TrimPrefix(header[:n], []byte("\xef\xbb\xbf")) // UTF-8 BOM
+	head = bytes.TrimSpace(head)
+	if !bytes.HasPrefix(head, []byte("<")) {
+		return false, nil
+	}
+	return bytes.Contains(head, []byte("<svg")), nil
+}
+
+// Dimensions reads the intrinsic size from the viewBox of the root element,
+// falling back to its width and height attributes
+func Dimensions(data []byte) (*Info, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return nil, fmt.Errorf("no svg element: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return nil, fmt.Errorf("root element is %s, not svg", start.Name.Local)
+		}
+
+		var viewBox, width, height string
+		for _, attr := range start.Attr {
+			switch attr.Name.Local {
+			case "viewBox":
+				viewBox = attr.Value
+			case "width":
+				width = attr.Value
+			case "height":
+				height = attr.Value
+			}
+		}
+		if info, ok := parseViewBox(viewBox); ok {
+			return info, nil
+		}
+		w, wok := parseLength(width)
+		h, hok := parseLength(height)
+		if wok && hok {
+			return &Info{Width: w, Height: h}, nil
+		}
+		return nil, fmt.Errorf("svg has neither a viewBox nor a width and height")
+	}
+}
+
+func parseViewBox(value string) (*Info, bool) {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r' })
+	if len(fields) != 4 {
+		return nil, false
+	}
+	w, err1 := strconv.ParseFloat(fields[2], 64)
+	h, err2 := strconv.ParseFloat(fields[3], 64)
+	if err1 != nil || err2 != nil || w <= 0 || h <= 0 {
+		return nil, false
+	}
+	return &Info{Width: w, Height: h}, true
+}
+
+// parseLength parses absolute lengths in user units or px; relative units are rejected
+func parseLength(value string) (float64, bool) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "px")
+	n, err := strconv.ParseFloat(value, 64)
+	return n, err == nil && n > 0
+}
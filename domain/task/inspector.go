@@ -0,0 +1,42 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrTaskNotFound is returned for tasks missing from their queue
+var ErrTaskNotFound = errors.New("task not found")
+
+// DeadTask is a task archived after exhausting its retries
+type DeadTask struct {
+	ID       string
+	Queue    string
+	Type     string
+	Payload  []byte
+	Error    string
+	Retried  int
+	FailedAt time.Time
+}
+
+// QueueStats counts the tasks of a queue by state
+type QueueStats struct {
+	Queue     string
+	Size      int // Every task of the queue but the completed ones
+	Pending   int
+	Active    int
+	Scheduled int
+	Retry     int
+	Archived  int
+}
+
+// Inspector reads and manages the tasks of the queues
+type Inspector interface {
+	// ListDead lists the archived tasks of a queue, one page at a time from page 1
+	ListDead(ctx context.Context, queue string, page, size int) ([]*DeadTask, error)
+	// Requeue moves an archived task back to pending
+	Requeue(ctx context.Context, queue, id string) error
+	Delete(ctx context.Context, queue, id string) error
+	Stats(ctx context.Context, queue string) (*QueueStats, error)
+}
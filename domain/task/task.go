@@ -0,0 +1,62 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrSkipRetry marks the failures a retry cannot fix, such as a malformed
+// payload; the task is archived at once instead of being retried
+var ErrSkipRetry = errors.New("task not retried")
+
+// Options controls where and how a task is enqueued
+type Options struct {
+	Queue    string
+	MaxRetry int
+	Delay    time.Duration // Postpones the task
+}
+
+// Enqueuer schedules background tasks; the payload is serialized as JSON
+type Enqueuer interface {
+	Enqueue(ctx context.Context, taskType string, payload any, opts Options) (string, error)
+}
+
+// Handler processes the raw JSON payload of a task
+type Handler func(ctx context.Context, payload []byte) error
+
+// MediaPayload asks to process one media, for the media_process and
+// media_variants tasks
+type MediaPayload struct {
+	MediaID string `json:"media_id"`
+	Owner   string `json:"owner,omitempty"` // Tenant whose share of the worker capacity the task uses
+}
+
+// CleanupPayload asks to delete stored files
+type CleanupPayload struct {
+	URLs    []string `json:"urls"`
+	Backend string   `json:"backend,omitempty"` // Storage backend of the files; empty is the default
+}
+
+// ClipPayload asks to cut the clip of a clip job
+type ClipPayload struct {
+	JobID string `json:"job_id"`
+	Owner string `json:"owner,omitempty"`
+}
+
+// ReprocessPayload asks to regenerate the variants of one media of a reprocess job
+type ReprocessPayload struct {
+	JobID   string `json:"job_id"`
+	MediaID string `json:"media_id"`
+	Owner   string `json:"owner,omitempty"`
+}
+
+// StorageMigrationPayload asks to migrate the next batch of media of a storage migration
+type StorageMigrationPayload struct {
+	MigrationID string `json:"migration_id"`
+}
+
+// StorageRestorePayload asks to move the files of a media back to the default storage backend
+type StorageRestorePayload struct {
+	MediaID string `json:"media_id"`
+}
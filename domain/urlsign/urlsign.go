@@ -0,0 +1,162 @@
+package urlsign
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MinSecretLength is the shortest signing secret accepted, in bytes
+const MinSecretLength = 32
+
+// Query parameters a signed URL carries
+const (
+	ParamExpires  = "exp"
+	ParamKeyID    = "kid"
+	ParamClientIP = "ip"
+	ParamSig      = "sig"
+)
+
+var (
+	// ErrInvalid is returned for signatures that do not match their URL
+	ErrInvalid = errors.New("invalid signature")
+	// ErrExpired is returned for valid signatures past their expiry
+	ErrExpired = errors.New("signature expired")
+)
+
+// Key is a signing secret; its ID travels in the URLs it signs so that
+// verification finds it after the current key changes
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// Claims are what a signed URL grants access to
+type Claims struct {
+	MediaID   string
+	Rendition string // Variant name, the original, or tracks/{id} for a text track
+	ClientIP  string // Address the URL is bound to; empty for any client
+	Expires   time.Time
+}
+
+// Token is the signature part of a signed URL
+type Token struct {
+	KeyID    string
+	ClientIP string
+	Expires  int64 // Unix seconds
+	Sig      []byte
+}
+
+// Signer signs with its current key and verifies with any of its keys, so
+// keys rotate by adding the new one, making it current and dropping the
+// old one once the URLs it signed have expired
+type Signer struct {
+	current string
+	keys    map[string][]byte
+}
+
+// NewSigner creates a signer over the keys, signing with the current one
+func NewSigner(current string, keys ...Key) (*Signer, error) {
+	s := &Signer{current: current, keys: make(map[string][]byte, len(keys))}
+	for _, key := range keys {
+		if key.ID == "" || strings.ContainsAny(key.ID, "\n") {
+			return nil, fmt.Errorf("invalid key ID %q", key.ID)
+		}
+		if len(key.Secret) < MinSecretLength {
+			return nil, fmt.Errorf("secret of key %s is shorter than %d bytes", key.ID, MinSecretLength)
+		}
+		if _, ok := s.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key ID %s", key.ID)
+		}
+		s.keys[key.ID] = key.Secret
+	}
+	if _, ok := s.keys[current]; !ok {
+		return nil, fmt.Errorf("current key %q is not configured", current)
+	}
+	return s, nil
+}
+
+// Sign returns the query parameters granting the claims
+func (s *Signer) Sign(c *Claims) url.Values {
+	token := &Token{KeyID: s.current, ClientIP: c.ClientIP, Expires: c.Expires.Unix()}
+	token.Sig = s.mac(s.keys[s.current], c, token)
+
+	query := url.Values{}
+	query.Set(ParamExpires, strconv.FormatInt(token.Expires, 10))
+	query.Set(ParamKeyID, token.KeyID)
+	if token.ClientIP != "" {
+		query.Set(ParamClientIP, token.ClientIP)
+	}
+	query.Set(ParamSig, base64.RawURLEncoding.EncodeToString(token.Sig))
+	return query
+}
+
+// Verify checks that the token grants the media and rendition of the claims
+// to the client address of the claims at the given time
+func (s *Signer) Verify(c *Claims, token *Token, now time.Time) error {
+	secret, ok := s.keys[token.KeyID]
+	if !ok {
+		return ErrInvalid
+	}
+	if !hmac.Equal(token.Sig, s.mac(secret, &Claims{MediaID: c.MediaID, Rendition: c.Rendition}, token)) {
+		return ErrInvalid
+	}
+	if token.ClientIP != "" && !sameIP(token.ClientIP, c.ClientIP) {
+		return ErrInvalid
+	}
+	if now.Unix() >= token.Expires {
+		return ErrExpired
+	}
+	return nil
+}
+
+// mac signs the media and rendition of the claims with the expiry and
+// client binding of the token, one field per line
+func (s *Signer) mac(secret []byte, c *Claims, token *Token) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(strings.Join([]string{
+		"v1",
+		c.MediaID,
+		c.Rendition,
+		strconv.FormatInt(token.Expires, 10),
+		token.ClientIP,
+	}, "\n")))
+	return h.Sum(nil)
+}
+
+// ParseToken reads the signature parameters of a URL query; it returns nil
+// when the query carries no signature
+func ParseToken(query url.Values) (*Token, error) {
+	sig := query.Get(ParamSig)
+	if sig == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	expires, err := strconv.ParseInt(query.Get(ParamExpires), 10, 64)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+	return &Token{
+		KeyID:    query.Get(ParamKeyID),
+		ClientIP: query.Get(ParamClientIP),
+		Expires:  expires,
+		Sig:      raw,
+	}, nil
+}
+
+// sameIP compares addresses by value, so that 127.0.0.1 matches its
+// IPv4-mapped IPv6 form
+func sameIP(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	return ipA != nil && ipB != nil && ipA.Equal(ipB)
+}
@@ -0,0 +1,111 @@
+package urlsign
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	now      = time.Unix(1_800_000_000, 0)
+	previous = Key{ID: "2026-07", Secret: []byte(strings.Repeat("p", MinSecretLength))}
+	current  = Key{ID: "2026-10", Secret: []byte(strings.Repeat("c", MinSecretLength))}
+)
+
+func newSigner(t *testing.T, currentID string, keys ...Key) *Signer {
+	t.Helper()
+	signer, err := NewSigner(currentID, keys...)
+	if err != nil {
+		t.Fatalf("NewSigner: %v", err)
+	}
+	return signer
+}
+
+// sign signs the claims and reads the token back from the query
+func sign(t *testing.T, signer *Signer, c Claims) *Token {
+	t.Helper()
+	token, err := ParseToken(signer.Sign(&c))
+	if err != nil || token == nil {
+		t.Fatalf("ParseToken = %v, %v", token, err)
+	}
+	return token
+}
+
+func TestVerify(t *testing.T) {
+	signer := newSigner(t, current.ID, previous, current)
+	claims := Claims{MediaID: "m1", Rendition: "thumbnail", Expires: now.Add(time.Hour)}
+	bound := Claims{MediaID: "m1", Rendition: "thumbnail", ClientIP: "203.0.113.7", Expires: now.Add(time.Hour)}
+
+	tests := []struct {
+		name   string
+		token  *Token
+		claims Claims // Claims of the request
+		at     time.Time
+		want   error
+	}{
+		{"valid", sign(t, signer, claims), claims, now, nil},
+		{"expired", sign(t, signer, claims), claims, now.Add(time.Hour), ErrExpired},
+		{"other rendition", sign(t, signer, claims), Claims{MediaID: "m1", Rendition: "original"}, now, ErrInvalid},
+		{"other media", sign(t, signer, claims), Claims{MediaID: "m2", Rendition: "thumbnail"}, now, ErrInvalid},
+		{"bound client", sign(t, signer, bound), Claims{MediaID: "m1", Rendition: "thumbnail", ClientIP: "203.0.113.7"}, now, nil},
+		{"bound client as mapped IPv6", sign(t, signer, bound), Claims{MediaID: "m1", Rendition: "thumbnail", ClientIP: "::ffff:203.0.113.7"}, now, nil},
+		{"wrong client", sign(t, signer, bound), Claims{MediaID: "m1", Rendition: "thumbnail", ClientIP: "203.0.113.8"}, now, ErrInvalid},
+		{"previous key", sign(t, newSigner(t, previous.ID, previous), claims), claims, now, nil},
+		{"unknown key", sign(t, newSigner(t, "retired", Key{ID: "retired", Secret: current.Secret}), claims), claims, now, ErrInvalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := signer.Verify(&tt.claims, tt.token, tt.at); !errors.Is(err, tt.want) {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsTamperedTokens(t *testing.T) {
+	signer := newSigner(t, current.ID, current)
+	claims := Claims{MediaID: "m1", Rendition: "thumbnail", ClientIP: "203.0.113.7", Expires: now.Add(time.Hour)}
+
+	for name, tamper := range map[string]func(*Token){
+		"expiry":    func(token *Token) { token.Expires += 3600 },
+		"client":    func(token *Token) { token.ClientIP = "" },
+		"key ID":    func(token *Token) { token.KeyID = previous.ID },
+		"signature": func(token *Token) { token.Sig[0] ^= 1 },
+	} {
+		token := sign(t, signer, claims)
+		tamper(token)
+		if err := signer.Verify(&claims, token, now); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Verify with tampered %s = %v, want ErrInvalid", name, err)
+		}
+	}
+}
+
+func TestParseToken(t *testing.T) {
+	if token, err := ParseToken(url.Values{}); token != nil || err != nil {
+		t.Errorf("ParseToken of an unsigned query = %v, %v", token, err)
+	}
+	for _, query := range []url.Values{
+		{ParamSig: {"not base64!"}, ParamExpires: {"1"}},
+		{ParamSig: {"c2ln"}, ParamExpires: {"soon"}},
+	} {
+		if _, err := ParseToken(query); !errors.Is(err, ErrInvalid) {
+			t.Errorf("ParseToken(%v) = %v, want ErrInvalid", query, err)
+		}
+	}
+}
+
+func TestNewSignerValidatesKeys(t *testing.T) {
+	for name, keys := range map[string][]Key{
+		"short secret":      {{ID: "k", Secret: []byte("short")}},
+		"duplicate ID":      {current, current},
+		"empty ID":          {{ID: "", Secret: current.Secret}},
+		"missing current":   {previous},
+		"newline in key ID": {{ID: "2026-10\n", Secret: current.Secret}},
+	} {
+		if _, err := NewSigner(current.ID, keys...); err == nil {
+			t.Errorf("NewSigner with %s succeeded", name)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/subtitle"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+	"github.com/anhvanhoa/service-core/domain/storage"
+	"github.com/anhvanhoa/service-core/utils"
+)
+
+// languageTag loosely matches BCP 47 tags (en, pt-BR, zh-Hant-TW)
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{1,8})*$`)
+
+// AddMediaTrackUsecase attaches a subtitle or caption track to a video media
+type AddMediaTrackUsecase struct {
+	mediaRepo repository.MediaRepository
+	trackRepo repository.MediaTrackRepository
+	logger    *log.LogGRPCImpl
+	uuid      goid.GoUUID
+	backends  *blob.Registry
+}
+
+// AddMediaTrackRequest is an SRT or WebVTT file to attach to a media
+type AddMediaTrackRequest struct {
+	MediaID   string
+	Kind      string
+	Language  string
+	Label     string
+	Data      []byte
+	CreatedBy string
+}
+
+// NewAddMediaTrackUsecase creates a new add media track usecase
+func NewAddMediaTrackUsecase(
+	mediaRepo repository.MediaRepository,
+	trackRepo repository.MediaTrackRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	backends *blob.Registry,
+) *AddMediaTrackUsecase {
+	return &AddMediaTrackUsecase{
+		mediaRepo: mediaRepo,
+		trackRepo: trackRepo,
+		logger:    logger,
+		uuid:      uuid,
+		backends:  backends,
+	}
+}
+
+// Execute validates the track, converting SRT to WebVTT, and stores it
+func (uc *AddMediaTrackUsecase) Execute(ctx context.Context, req *AddMediaTrackRequest) (*entity.MediaTrack, error) {
+	uc.logger.Info(fmt.Sprintf("Adding %s track to media: %s", req.Kind, req.MediaID))
+
+	// Step 1: Validate and normalize input
+	if err := uc.validateAndNormalizeInput(req); err != nil {
+		uc.logger.Error(fmt.Sprintf("Input validation failed: %v", err))
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Step 2: Check the media is a video of the requester
+	media, err := uc.mediaRepo.GetByID(ctx, req.MediaID)
+	if err != nil {
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if media == nil {
+		return nil, fmt.Errorf("media not found")
+	}
+	if media.CreatedBy != req.CreatedBy {
+		return nil, fmt.Errorf("unauthorized: user %s does not own media %s", req.CreatedBy, media.ID)
+	}
+	if media.Type != entity.MediaTypeVideo {
+		return nil, fmt.Errorf("validation failed: tracks can only be attached to videos")
+	}
+
+	// Step 3: Convert to WebVTT
+	vtt, err := subtitle.ToWebVTT(req.Data)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: invalid subtitles: %w", err)
+	}
+
+	// Step 4: Store the file
+	track := &entity.MediaTrack{
+		ID:        uc.uuid.Gen(),
+		MediaID:   media.ID,
+		Kind:      req.Kind,
+		Language:  req.Language,
+		Label:     req.Label,
+		MimeType:  string(entity.MimeTypeVTT),
+		CreatedBy: req.CreatedBy,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	track.StorageBackend, track.URL, err = uc.backends.Upload(ctx, &storage.UploadRequest{
+		FileData:   bytes.NewReader(vtt),
+		OutputPath: utils.ConvertToSlug(media.ID+"_"+track.Kind+"_"+track.Language+"_"+track.ID) + entity.ExtVTT,
+	})
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to store track: %v", err))
+		return nil, fmt.Errorf("storage upload failed: %w", err)
+	}
+
+	// Step 5: Save the track
+	if err := uc.trackRepo.Create(ctx, track); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to save track: %v", err))
+		_ = uc.backends.Delete(ctx, track.StorageBackend, track.URL)
+		return nil, fmt.Errorf("database save failed: %w", err)
+	}
+
+	uc.logger.Info(fmt.Sprintf("Track %s added to media: %s", track.ID, media.ID))
+	return track, nil
+}
+
+// Step 1: Validate and normalize input
+func (uc *AddMediaTrackUsecase) validateAndNormalizeInput(req *AddMediaTrackRequest) error {
+	if req.MediaID == "" {
+		return fmt.Errorf("media ID is required")
+	}
+	if req.CreatedBy == "" {
+		return fmt.Errorf("created_by is required")
+	}
+	if req.Kind == "" {
+		req.Kind = entity.TrackKindSubtitles
+	}
+	if !entity.IsTrackKind(req.Kind) {
+		return fmt.Errorf("unknown track kind %s", req.Kind)
+	}
+	if !languageTag.MatchString(req.Language) {
+		return fmt.Errorf("invalid language %q", req.Language)
+	}
+	req.Label = strings.TrimSpace(req.Label)
+	if req.Label == "" {
+		req.Label = req.Language
+	}
+	if len(req.Label) > constants.MaxTrackLabel {
+		return fmt.Errorf("label exceeds %d characters", constants.MaxTrackLabel)
+	}
+	if len(req.Data) == 0 {
+		return fmt.Errorf("track file is required")
+	}
+	if len(req.Data) > constants.MaxTrackFileSize {
+		return fmt.Errorf("track file exceeds %d bytes", constants.MaxTrackFileSize)
+	}
+	return nil
+}
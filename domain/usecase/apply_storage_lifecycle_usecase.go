@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// ApplyStorageLifecycleUsecase moves the media matching the lifecycle rules
+// to the storage tier of each rule
+type ApplyStorageLifecycleUsecase struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+	backends  *blob.Registry
+	rules     []*entity.LifecycleRule
+	mover     *mediaFileMover
+}
+
+// StorageLifecycleResult summarizes one sweep
+type StorageLifecycleResult struct {
+	Moved  int
+	Failed int
+}
+
+// NewApplyStorageLifecycleUsecase creates a new apply storage lifecycle usecase
+func NewApplyStorageLifecycleUsecase(
+	mediaRepo repository.MediaRepository,
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+	rules []*entity.LifecycleRule,
+) *ApplyStorageLifecycleUsecase {
+	return &ApplyStorageLifecycleUsecase{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+		backends:  backends,
+		rules:     rules,
+		mover:     newMediaFileMover(mediaRepo, logger, backends),
+	}
+}
+
+// Execute moves up to constants.LifecycleBatchSize media per rule, in the
+// order of the rules; a media that could not be moved stays where it is and
+// is tried again by the next sweep
+func (uc *ApplyStorageLifecycleUsecase) Execute(ctx context.Context) (*StorageLifecycleResult, error) {
+	result := &StorageLifecycleResult{}
+	for _, rule := range uc.rules {
+		// Step 1: Select the media of the rule
+		target, err := uc.backends.Get(rule.To)
+		if err != nil {
+			return result, fmt.Errorf("lifecycle rule %s: %w", rule.Name, err)
+		}
+		mediaIDs, err := uc.mediaRepo.GetLifecycleCandidates(ctx, lifecycleFilters(rule, time.Now()))
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to select media of lifecycle rule %s: %v", rule.Name, err))
+			return result, fmt.Errorf("database retrieval failed: %w", err)
+		}
+
+		// Step 2: Move them, deleting the files they leave
+		moved := 0
+		for _, mediaID := range mediaIDs {
+			if _, err := uc.mover.move(ctx, mediaID, "", target, true); err != nil {
+				uc.logger.Warn(fmt.Sprintf("Failed to move media %s to storage backend %s by lifecycle rule %s: %v", mediaID, target.Name, rule.Name, err))
+				result.Failed++
+				continue
+			}
+			moved++
+		}
+		result.Moved += moved
+		if len(mediaIDs) > 0 {
+			uc.logger.Info(fmt.Sprintf("Lifecycle rule %s moved %d of %d media to storage backend %s", rule.Name, moved, len(mediaIDs), target.Name))
+		}
+	}
+	return result, nil
+}
+
+// Step 1: Select the media of the rule
+func lifecycleFilters(rule *entity.LifecycleRule, now time.Time) repository.LifecycleFilters {
+	filters := repository.LifecycleFilters{
+		Backends: rule.From,
+		Types:    rule.Types,
+		Limit:    constants.LifecycleBatchSize,
+	}
+	if rule.MinAge > 0 {
+		createdBefore := now.Add(-rule.MinAge)
+		filters.CreatedBefore = &createdBefore
+	}
+	if rule.Idle > 0 {
+		accessedBefore := now.Add(-rule.Idle)
+		filters.AccessedBefore = &accessedBefore
+	}
+	return filters
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/imaging"
+	"media-service/domain/repository"
+	"sync"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// ApplyWatermarkUsecase composites the configured watermark profiles onto variants
+type ApplyWatermarkUsecase struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+	backends  *blob.Registry
+	profiles  map[string]*entity.WatermarkProfile // by variant name
+
+	mu       sync.Mutex
+	overlays map[string]image.Image // by profile key
+}
+
+// NewApplyWatermarkUsecase creates a new apply watermark usecase.
+// Invalid profiles are logged and skipped.
+func NewApplyWatermarkUsecase(
+	mediaRepo repository.MediaRepository,
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+	profiles []*entity.WatermarkProfile,
+) *ApplyWatermarkUsecase {
+	uc := &ApplyWatermarkUsecase{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+		backends:  backends,
+		profiles:  make(map[string]*entity.WatermarkProfile),
+		overlays:  make(map[string]image.Image),
+	}
+	for _, profile := range profiles {
+		if err := uc.validateProfile(profile); err != nil {
+			logger.Error(fmt.Sprintf("Skipping watermark profile %s: %v", profile.Name, err))
+			continue
+		}
+		for _, variant := range profile.Variants {
+			if other, ok := uc.profiles[variant]; ok {
+				logger.Warn(fmt.Sprintf("Variant %s already uses watermark profile %s, ignoring %s", variant, other.Name, profile.Name))
+				continue
+			}
+			uc.profiles[variant] = profile
+		}
+	}
+	return uc
+}
+
+// ProfileKey returns the key of the profile applied to the variant, or "" when none is
+func (uc *ApplyWatermarkUsecase) ProfileKey(variant string) string {
+	profile, ok := uc.profiles[variant]
+	if !ok {
+		return ""
+	}
+	return profile.Key()
+}
+
+// Execute watermarks a rendered variant with its profile, if any, and returns the profile key
+func (uc *ApplyWatermarkUsecase) Execute(ctx context.Context, variant string, img image.Image) (image.Image, string, error) {
+	profile, ok := uc.profiles[variant]
+	if !ok {
+		return img, "", nil
+	}
+
+	overlay, err := uc.loadOverlay(ctx, profile)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load watermark %s: %w", profile.Name, err)
+	}
+
+	return imaging.Watermark(img, overlay, imaging.WatermarkOptions{
+		Position: profile.Position,
+		Opacity:  profile.Opacity,
+		Scale:    profile.Scale,
+		Tile:     profile.Tile,
+		Margin:   profile.Margin,
+	}), profile.Key(), nil
+}
+
+func (uc *ApplyWatermarkUsecase) validateProfile(profile *entity.WatermarkProfile) error {
+	if profile.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if profile.OverlayMediaID == "" {
+		return fmt.Errorf("overlay media ID is required")
+	}
+	if !profile.Tile && !imaging.ValidPosition(profile.Position) {
+		return fmt.Errorf("invalid position: %s", profile.Position)
+	}
+	if profile.Opacity <= 0 || profile.Opacity > 1 {
+		return fmt.Errorf("opacity must be within (0, 1]")
+	}
+	if profile.Scale <= 0 || profile.Scale > 1 {
+		return fmt.Errorf("scale must be within (0, 1]")
+	}
+	for _, variant := range profile.Variants {
+		if variant == entity.VariantOriginal {
+			return fmt.Errorf("the original cannot be watermarked")
+		}
+	}
+	return nil
+}
+
+func (uc *ApplyWatermarkUsecase) loadOverlay(ctx context.Context, profile *entity.WatermarkProfile) (image.Image, error) {
+	key := profile.Key()
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	if overlay, ok := uc.overlays[key]; ok {
+		return overlay, nil
+	}
+
+	media, err := uc.mediaRepo.GetByID(ctx, profile.OverlayMediaID)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		return nil, fmt.Errorf("overlay media %s not found", profile.OverlayMediaID)
+	}
+
+	file, err := uc.backends.Open(ctx, media.StorageBackend, media.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	overlay, err := imaging.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	uc.overlays[key] = overlay
+	return overlay, nil
+}
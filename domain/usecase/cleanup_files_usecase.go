@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"media-service/domain/blob"
+	"media-service/domain/task"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// CleanupFilesUsecase deletes stored files in the background
+type CleanupFilesUsecase struct {
+	logger   *log.LogGRPCImpl
+	backends *blob.Registry
+}
+
+// NewCleanupFilesUsecase creates a new cleanup files usecase
+func NewCleanupFilesUsecase(
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+) *CleanupFilesUsecase {
+	return &CleanupFilesUsecase{
+		logger:   logger,
+		backends: backends,
+	}
+}
+
+// Execute handles a task.CleanupPayload; the task fails, and is retried,
+// when any file could not be deleted
+func (uc *CleanupFilesUsecase) Execute(ctx context.Context, payload []byte) error {
+	var p task.CleanupPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid cleanup payload: %w: %w", err, task.ErrSkipRetry)
+	}
+
+	var errs []error
+	for _, url := range p.URLs {
+		if err := uc.backends.Delete(ctx, p.Backend, url); err != nil {
+			uc.logger.Warn(fmt.Sprintf("Failed to delete file %s: %v", url, err))
+			errs = append(errs, fmt.Errorf("%s: %w", url, err))
+		}
+	}
+	uc.logger.Info(fmt.Sprintf("Deleted %d of %d files", len(p.URLs)-len(errs), len(p.URLs)))
+	return errors.Join(errs...)
+}
@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// CreateClipUsecase queues the extraction of a part of a video into a new media
+type CreateClipUsecase struct {
+	mediaRepo repository.MediaRepository
+	jobRepo   repository.ClipJobRepository
+	logger    *log.LogGRPCImpl
+	uuid      goid.GoUUID
+	enqueuer  task.Enqueuer
+}
+
+// CreateClipRequest selects the part of the source video to cut, in seconds
+type CreateClipRequest struct {
+	SourceID    string
+	Start       float64
+	End         float64
+	Width       int // Output size, 0 keeps the source size; only for accurate clips
+	Height      int
+	Mode        string // entity.ClipModeAccurate (default) or entity.ClipModeKeyframe
+	RequestedBy string
+}
+
+// NewCreateClipUsecase creates a new create clip usecase
+func NewCreateClipUsecase(
+	mediaRepo repository.MediaRepository,
+	jobRepo repository.ClipJobRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	enqueuer task.Enqueuer,
+) *CreateClipUsecase {
+	return &CreateClipUsecase{
+		mediaRepo: mediaRepo,
+		jobRepo:   jobRepo,
+		logger:    logger,
+		uuid:      uuid,
+		enqueuer:  enqueuer,
+	}
+}
+
+// Execute creates a clip job and enqueues it; the clip media is created by the worker
+func (uc *CreateClipUsecase) Execute(ctx context.Context, req *CreateClipRequest) (*entity.ClipJob, error) {
+	uc.logger.Info(fmt.Sprintf("Creating clip of media %s requested by: %s", req.SourceID, req.RequestedBy))
+
+	// Step 1: Validate and normalize input
+	if err := uc.validateAndNormalizeInput(req); err != nil {
+		uc.logger.Error(fmt.Sprintf("Input validation failed: %v", err))
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Step 2: Check the source video
+	source, err := uc.mediaRepo.GetByID(ctx, req.SourceID)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to retrieve media: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("media not found")
+	}
+	if source.CreatedBy != req.RequestedBy {
+		return nil, fmt.Errorf("unauthorized: media belongs to another user")
+	}
+	if err := validateClipSource(source, req); err != nil {
+		uc.logger.Error(fmt.Sprintf("Clip validation failed: %v", err))
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Step 3: Create the job
+	job := uc.createJobEntity(req)
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to save clip job: %v", err))
+		return nil, fmt.Errorf("database save failed: %w", err)
+	}
+
+	// Step 4: Enqueue it, failing the job when it cannot be queued
+	_, err = uc.enqueuer.Enqueue(ctx, constants.JobTypeVideoClip, &task.ClipPayload{
+		JobID: job.ID,
+		Owner: job.RequestedBy,
+	}, task.Options{Queue: constants.QueueMediaProcessing})
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to enqueue clip job %s: %v", job.ID, err))
+		now := time.Now()
+		job.Status = entity.ClipJobStatusFailed
+		job.Error = fmt.Sprintf("enqueue failed: %v", err)
+		job.CompletedAt = &now
+		if err := uc.jobRepo.UpdateStatus(ctx, job); err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to update clip job %s: %v", job.ID, err))
+		}
+		return nil, fmt.Errorf("failed to enqueue clip: %w", err)
+	}
+
+	uc.logger.Info(fmt.Sprintf("Clip job %s queued", job.ID))
+	return job, nil
+}
+
+// Step 1: Validate and normalize input
+func (uc *CreateClipUsecase) validateAndNormalizeInput(req *CreateClipRequest) error {
+	if req.SourceID == "" {
+		return fmt.Errorf("source media ID is required")
+	}
+	if req.RequestedBy == "" {
+		return fmt.Errorf("requester is required")
+	}
+
+	if req.Mode == "" {
+		req.Mode = entity.ClipModeAccurate
+	}
+	if req.Mode != entity.ClipModeAccurate && req.Mode != entity.ClipModeKeyframe {
+		return fmt.Errorf("invalid clip mode: %s", req.Mode)
+	}
+
+	if req.Start < 0 {
+		return fmt.Errorf("start time must not be negative")
+	}
+	if req.End-req.Start < constants.MinClipDuration {
+		return fmt.Errorf("clip must last at least %v seconds", constants.MinClipDuration)
+	}
+	if req.End-req.Start > constants.MaxVideoDuration {
+		return fmt.Errorf("clip too long: %v seconds (max %d)", req.End-req.Start, constants.MaxVideoDuration)
+	}
+
+	if req.Width < 0 || req.Height < 0 {
+		return fmt.Errorf("output resolution must not be negative")
+	}
+	if (req.Width > 0 || req.Height > 0) && req.Mode == entity.ClipModeKeyframe {
+		return fmt.Errorf("keyframe clips keep the source resolution")
+	}
+	return nil
+}
+
+// validateClipSource checks the clip fits the source video, without upscaling it
+func validateClipSource(source *entity.Media, req *CreateClipRequest) error {
+	if source.Type != entity.MediaTypeVideo {
+		return fmt.Errorf("media is not a video")
+	}
+	if source.ProcessingStatus != entity.ProcessingStatusCompleted {
+		return fmt.Errorf("video is still processing")
+	}
+	if source.Duration != nil && req.End > *source.Duration {
+		return fmt.Errorf("end time %v exceeds the video duration %v", req.End, *source.Duration)
+	}
+	if source.Width != nil && req.Width > *source.Width {
+		return fmt.Errorf("output width %d exceeds the video width %d", req.Width, *source.Width)
+	}
+	if source.Height != nil && req.Height > *source.Height {
+		return fmt.Errorf("output height %d exceeds the video height %d", req.Height, *source.Height)
+	}
+	return nil
+}
+
+// Step 3: Create the job
+func (uc *CreateClipUsecase) createJobEntity(req *CreateClipRequest) *entity.ClipJob {
+	return &entity.ClipJob{
+		ID:            uc.uuid.Gen(),
+		SourceMediaID: req.SourceID,
+		RequestedBy:   req.RequestedBy,
+		Start:         req.Start,
+		End:           req.End,
+		Width:         req.Width,
+		Height:        req.Height,
+		Mode:          req.Mode,
+		Status:        entity.ClipJobStatusQueued,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+}
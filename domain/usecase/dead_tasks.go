@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/task"
+	"strings"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// deadTaskPageSize is the page size used to scan the archived tasks
+const deadTaskPageSize = 100
+
+// MediaQueues are the task queues of the service
+var MediaQueues = []string{
+	constants.QueueMediaProcessing,
+	constants.QueueMediaBulk,
+	constants.QueueMediaCleanup,
+}
+
+// DeadTaskFilter selects archived tasks; empty fields match every task
+type DeadTaskFilter struct {
+	Queue         string // Empty searches every media queue
+	ID            string // Requires Queue
+	Type          string
+	MediaID       string // media_id of the payload
+	ErrorContains string
+}
+
+func (f *DeadTaskFilter) validate() error {
+	if f.Queue != "" && !isMediaQueue(f.Queue) {
+		return fmt.Errorf("unknown queue: %s", f.Queue)
+	}
+	if f.ID != "" && f.Queue == "" {
+		return fmt.Errorf("queue is required with a task ID")
+	}
+	return nil
+}
+
+func (f *DeadTaskFilter) empty() bool {
+	return *f == DeadTaskFilter{}
+}
+
+func (f *DeadTaskFilter) match(t *task.DeadTask) bool {
+	if f.ID != "" && t.ID != f.ID {
+		return false
+	}
+	if f.Type != "" && t.Type != f.Type {
+		return false
+	}
+	if f.ErrorContains != "" && !strings.Contains(t.Error, f.ErrorContains) {
+		return false
+	}
+	if f.MediaID != "" {
+		var payload task.MediaPayload
+		if json.Unmarshal(t.Payload, &payload) != nil || payload.MediaID != f.MediaID {
+			return false
+		}
+	}
+	return true
+}
+
+func isMediaQueue(queue string) bool {
+	for _, q := range MediaQueues {
+		if q == queue {
+			return true
+		}
+	}
+	return false
+}
+
+// selectDeadTasks scans the archived tasks of the filtered queues, failing
+// when more than constants.MaxDeadTasks are archived
+func selectDeadTasks(ctx context.Context, inspector task.Inspector, filter *DeadTaskFilter) ([]*task.DeadTask, error) {
+	queues := MediaQueues
+	if filter.Queue != "" {
+		queues = []string{filter.Queue}
+	}
+
+	var selected []*task.DeadTask
+	scanned := 0
+	for _, queue := range queues {
+		for page := 1; ; page++ {
+			tasks, err := inspector.ListDead(ctx, queue, page, deadTaskPageSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list dead tasks of %s: %w", queue, err)
+			}
+			for _, t := range tasks {
+				if filter.match(t) {
+					selected = append(selected, t)
+				}
+			}
+			scanned += len(tasks)
+			if scanned > constants.MaxDeadTasks {
+				return nil, fmt.Errorf("more than %d dead tasks, narrow the queue", constants.MaxDeadTasks)
+			}
+			if len(tasks) < deadTaskPageSize {
+				break
+			}
+		}
+	}
+	return selected, nil
+}
+
+// applyToDeadTasks runs action over the dead tasks matching the filter, going
+// on when one fails, and returns how many succeeded
+func applyToDeadTasks(
+	ctx context.Context,
+	inspector task.Inspector,
+	logger *log.LogGRPCImpl,
+	filter *DeadTaskFilter,
+	name string,
+	action func(ctx context.Context, queue, id string) error,
+) (int, error) {
+	tasks, err := selectDeadTasks(ctx, inspector, filter)
+	if err != nil {
+		logger.Error(fmt.Sprintf("Failed to select dead tasks to %s: %v", name, err))
+		return 0, err
+	}
+	if filter.ID != "" && len(tasks) == 0 {
+		return 0, fmt.Errorf("dead task not found")
+	}
+
+	done := 0
+	for _, t := range tasks {
+		if err := action(ctx, t.Queue, t.ID); err != nil {
+			logger.Warn(fmt.Sprintf("Failed to %s dead task %s: %v", name, t.ID, err))
+			continue
+		}
+		done++
+	}
+	logger.Info(fmt.Sprintf("Dead tasks %s: %d of %d", name, done, len(tasks)))
+	return done, nil
+}
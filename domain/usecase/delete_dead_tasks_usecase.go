@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/task"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// DeleteDeadTasksUsecase discards dead tasks
+type DeleteDeadTasksUsecase struct {
+	inspector task.Inspector
+	logger    *log.LogGRPCImpl
+}
+
+// NewDeleteDeadTasksUsecase creates a new delete dead tasks usecase
+func NewDeleteDeadTasksUsecase(
+	inspector task.Inspector,
+	logger *log.LogGRPCImpl,
+) *DeleteDeadTasksUsecase {
+	return &DeleteDeadTasksUsecase{
+		inspector: inspector,
+		logger:    logger,
+	}
+}
+
+// Execute deletes the dead tasks matching the filter, for admins only, and
+// returns how many were deleted
+func (uc *DeleteDeadTasksUsecase) Execute(ctx context.Context, filter *DeadTaskFilter, isAdmin bool) (int, error) {
+	if !isAdmin {
+		return 0, fmt.Errorf("unauthorized: admin only")
+	}
+	if filter.empty() {
+		return 0, fmt.Errorf("validation failed: a queue, task ID or filter is required")
+	}
+	if err := filter.validate(); err != nil {
+		return 0, fmt.Errorf("validation failed: %w", err)
+	}
+	return applyToDeadTasks(ctx, uc.inspector, uc.logger, filter, "delete", uc.inspector.Delete)
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/blob"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// DeleteMediaTrackUsecase removes a text track from its media
+type DeleteMediaTrackUsecase struct {
+	mediaRepo repository.MediaRepository
+	trackRepo repository.MediaTrackRepository
+	logger    *log.LogGRPCImpl
+	backends  *blob.Registry
+}
+
+// NewDeleteMediaTrackUsecase creates a new delete media track usecase
+func NewDeleteMediaTrackUsecase(
+	mediaRepo repository.MediaRepository,
+	trackRepo repository.MediaTrackRepository,
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+) *DeleteMediaTrackUsecase {
+	return &DeleteMediaTrackUsecase{
+		mediaRepo: mediaRepo,
+		trackRepo: trackRepo,
+		logger:    logger,
+		backends:  backends,
+	}
+}
+
+// Execute deletes the track, if the requester owns its media, and its file
+func (uc *DeleteMediaTrackUsecase) Execute(ctx context.Context, trackID, createdBy string) error {
+	uc.logger.Info(fmt.Sprintf("Deleting track: %s", trackID))
+
+	if trackID == "" || createdBy == "" {
+		return fmt.Errorf("validation failed: track ID and created_by are required")
+	}
+
+	track, err := uc.trackRepo.GetByID(ctx, trackID)
+	if err != nil {
+		return fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if track == nil {
+		return fmt.Errorf("track not found")
+	}
+	media, err := uc.mediaRepo.GetByID(ctx, track.MediaID)
+	if err != nil {
+		return fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if media == nil || media.CreatedBy != createdBy {
+		return fmt.Errorf("unauthorized: user %s does not own track %s", createdBy, trackID)
+	}
+
+	if err := uc.trackRepo.Delete(ctx, trackID); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to delete track from database: %v", err))
+		return fmt.Errorf("failed to delete from database: %w", err)
+	}
+	if err := uc.backends.Delete(ctx, track.StorageBackend, track.URL); err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to delete track file from storage: %v", err))
+	}
+	return nil
+}
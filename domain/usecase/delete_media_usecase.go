@@ -3,28 +3,34 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"media-service/domain/blob"
 	"media-service/domain/entity"
 	"media-service/domain/repository"
 
 	"github.com/anhvanhoa/service-core/domain/log"
-	"github.com/anhvanhoa/service-core/domain/storage"
 )
 
 type DeleteMediaUsecase struct {
-	mediaRepo      repository.MediaRepository
-	logger         *log.LogGRPCImpl
-	storageService storage.StorageI
+	mediaRepo   repository.MediaRepository
+	variantRepo repository.MediaVariantRepository
+	trackRepo   repository.MediaTrackRepository
+	logger      *log.LogGRPCImpl
+	backends    *blob.Registry
 }
 
 func NewDeleteMediaUsecase(
 	mediaRepo repository.MediaRepository,
+	variantRepo repository.MediaVariantRepository,
+	trackRepo repository.MediaTrackRepository,
 	logger *log.LogGRPCImpl,
-	storageService storage.StorageI,
+	backends *blob.Registry,
 ) *DeleteMediaUsecase {
 	return &DeleteMediaUsecase{
-		mediaRepo:      mediaRepo,
-		logger:         logger,
-		storageService: storageService,
+		mediaRepo:   mediaRepo,
+		variantRepo: variantRepo,
+		trackRepo:   trackRepo,
+		logger:      logger,
+		backends:    backends,
 	}
 }
 
@@ -47,10 +53,13 @@ func (uc *DeleteMediaUsecase) Execute(ctx context.Context, mediaID, createdBy st
 		return fmt.Errorf("unauthorized: %w", err)
 	}
 
-	if err := uc.deleteFromStorage(ctx, existingMedia.URL); err != nil {
+	if err := uc.deleteFromStorage(ctx, existingMedia.StorageBackend, existingMedia.URL); err != nil {
 		uc.logger.Warn(fmt.Sprintf("Failed to delete file from storage: %v", err))
 	}
 
+	uc.deleteVariantFiles(ctx, mediaID)
+	uc.deleteTrackFiles(ctx, mediaID)
+
 	if err := uc.deleteFromDatabase(ctx, mediaID); err != nil {
 		uc.logger.Error(fmt.Sprintf("Failed to delete media from database: %v", err))
 		return fmt.Errorf("failed to delete from database: %w", err)
@@ -91,9 +100,37 @@ func (uc *DeleteMediaUsecase) checkOwnership(media *entity.Media, createdBy stri
 	return nil
 }
 
-func (uc *DeleteMediaUsecase) deleteFromStorage(ctx context.Context, url string) error {
+func (uc *DeleteMediaUsecase) deleteFromStorage(ctx context.Context, backend, url string) error {
 	fmt.Println("Đang xóa file: ", url, " - ", "Chưa có xử lý")
-	return uc.storageService.Delete(ctx, url)
+	return uc.backends.Delete(ctx, backend, url)
+}
+
+// Variant rows are removed with the media row (ON DELETE CASCADE), their files are not
+func (uc *DeleteMediaUsecase) deleteVariantFiles(ctx context.Context, mediaID string) {
+	variants, err := uc.variantRepo.GetByMediaID(ctx, mediaID)
+	if err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to retrieve variants: %v", err))
+		return
+	}
+	for _, variant := range variants {
+		if err := uc.backends.Delete(ctx, variant.StorageBackend, variant.URL); err != nil {
+			uc.logger.Warn(fmt.Sprintf("Failed to delete variant file from storage: %v", err))
+		}
+	}
+}
+
+// Track rows are removed with the media row (ON DELETE CASCADE), their files are not
+func (uc *DeleteMediaUsecase) deleteTrackFiles(ctx context.Context, mediaID string) {
+	tracks, err := uc.trackRepo.GetByMediaID(ctx, mediaID)
+	if err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to retrieve tracks: %v", err))
+		return
+	}
+	for _, track := range tracks {
+		if err := uc.backends.Delete(ctx, track.StorageBackend, track.URL); err != nil {
+			uc.logger.Warn(fmt.Sprintf("Failed to delete track file from storage: %v", err))
+		}
+	}
 }
 
 func (uc *DeleteMediaUsecase) deleteFromDatabase(ctx context.Context, mediaID string) error {
@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"media-service/domain/urlsign"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// DeliveryClassTrack is the cache class of text tracks; other files are
+// classed by the type of their media
+const DeliveryClassTrack = "track"
+
+// DeliverMediaUsecase opens the stored file of a media, one of its variants
+// or one of its text tracks for delivery over HTTP, queuing the rehydration
+// of media stored in a colder tier than the default backend
+type DeliverMediaUsecase struct {
+	mediaRepo repository.MediaRepository
+	trackRepo repository.MediaTrackRepository
+	logger    *log.LogGRPCImpl
+	backends  *blob.Registry
+	signer    *urlsign.Signer
+	enqueuer  task.Enqueuer
+	rehydrate entity.RehydrateMode
+}
+
+// DeliverMediaRequest selects the file to deliver
+type DeliverMediaRequest struct {
+	MediaID       string
+	Rendition     string // Variant name; empty or entity.VariantOriginal for the uploaded file
+	TrackID       string // Text track of the media, instead of a rendition
+	AllowOriginal bool   // Originals are only delivered when allowed, or to signed requests
+	Token         *urlsign.Token
+	ClientIP      string
+	RequireSigned bool // Unsigned requests are refused when set
+}
+
+// Delivery is a stored file opened for delivery; the caller closes File
+type Delivery struct {
+	File     io.ReadSeekCloser
+	Name     string // File name offered to downloads
+	MimeType string
+	Class    string // Media type of the media, or DeliveryClassTrack
+	Size     int64
+	ModTime  time.Time
+	ETag     string // Strong entity tag, quoted
+}
+
+// NewDeliverMediaUsecase creates a new deliver media usecase
+func NewDeliverMediaUsecase(
+	mediaRepo repository.MediaRepository,
+	trackRepo repository.MediaTrackRepository,
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+	signer *urlsign.Signer,
+	enqueuer task.Enqueuer,
+	rehydrate entity.RehydrateMode,
+) *DeliverMediaUsecase {
+	return &DeliverMediaUsecase{
+		mediaRepo: mediaRepo,
+		trackRepo: trackRepo,
+		logger:    logger,
+		backends:  backends,
+		signer:    signer,
+		enqueuer:  enqueuer,
+		rehydrate: rehydrate,
+	}
+}
+
+// deliveredFile is the row a delivery reads its file from
+type deliveredFile struct {
+	url, backend, name, mimeType, class string
+}
+
+// Execute opens the requested file
+func (uc *DeliverMediaUsecase) Execute(ctx context.Context, req *DeliverMediaRequest) (*Delivery, error) {
+	// Step 1: Validate input
+	if err := uc.validateInput(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if err := uc.checkSignature(req); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Load the media
+	media, err := uc.mediaRepo.GetByID(ctx, req.MediaID)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to retrieve media: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if media == nil {
+		return nil, fmt.Errorf("media not found")
+	}
+	// Step 3: Resolve the row of the file
+	file, err := uc.resolveFile(ctx, media, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 4: Refuse media stored in a colder tier while they are rehydrated
+	if err := uc.rehydrateMedia(ctx, media); err != nil {
+		return nil, err
+	}
+
+	// Step 5: Open the file in its backend
+	content, obj, err := uc.backends.OpenFile(ctx, file.backend, file.url)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			uc.logger.Warn(fmt.Sprintf("File of media %s is missing: %v", media.ID, err))
+			return nil, fmt.Errorf("file not found")
+		}
+		uc.logger.Error(fmt.Sprintf("Failed to open file of media %s: %v", media.ID, err))
+		return nil, fmt.Errorf("storage read failed: %w", err)
+	}
+
+	// Step 6: Record the access, once the file is served
+	now := time.Now()
+	if err := uc.mediaRepo.TouchAccess(ctx, media.ID, now, now.Add(-constants.AccessTouchInterval*time.Second)); err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to record access to media %s: %v", media.ID, err))
+	}
+
+	return &Delivery{
+		File:     content,
+		Name:     file.name,
+		MimeType: file.mimeType,
+		Class:    file.class,
+		Size:     obj.Size,
+		ModTime:  obj.ModTime,
+		ETag:     entityTag(file.backend, file.url, obj),
+	}, nil
+}
+
+// Step 1: Validate input
+func (uc *DeliverMediaUsecase) validateInput(req *DeliverMediaRequest) error {
+	if req.MediaID == "" {
+		return fmt.Errorf("media ID is required")
+	}
+	if req.TrackID != "" && req.Rendition != "" {
+		return fmt.Errorf("only one of rendition or track can be requested")
+	}
+	if req.Rendition == entity.VariantOriginal {
+		req.Rendition = ""
+	}
+	return nil
+}
+
+// Step 1: Check the signature, which grants access to the original too
+func (uc *DeliverMediaUsecase) checkSignature(req *DeliverMediaRequest) error {
+	if req.Token == nil {
+		if req.RequireSigned {
+			return fmt.Errorf("unauthorized: a signed URL is required")
+		}
+		return nil
+	}
+	if uc.signer == nil {
+		return fmt.Errorf("unauthorized: signed URLs are not configured")
+	}
+	err := uc.signer.Verify(&urlsign.Claims{
+		MediaID:   req.MediaID,
+		Rendition: signedRendition(req.Rendition, req.TrackID),
+		ClientIP:  req.ClientIP,
+	}, req.Token, time.Now())
+	if err != nil {
+		return fmt.Errorf("unauthorized: %w", err)
+	}
+	req.AllowOriginal = true
+	return nil
+}
+
+// Step 3: Resolve the row of the file
+func (uc *DeliverMediaUsecase) resolveFile(ctx context.Context, media *entity.Media, req *DeliverMediaRequest) (*deliveredFile, error) {
+	stem := strings.TrimSuffix(path.Base(media.Name), path.Ext(media.Name))
+	if req.TrackID != "" {
+		track, err := uc.trackRepo.GetByID(ctx, req.TrackID)
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to retrieve track: %v", err))
+			return nil, fmt.Errorf("database retrieval failed: %w", err)
+		}
+		if track == nil || track.MediaID != media.ID {
+			return nil, fmt.Errorf("track not found")
+		}
+		return &deliveredFile{
+			url:      track.URL,
+			backend:  track.StorageBackend,
+			name:     stem + "_" + track.Kind + "_" + track.Language + path.Ext(track.URL),
+			mimeType: track.MimeType,
+			class:    DeliveryClassTrack,
+		}, nil
+	}
+
+	if req.Rendition == "" {
+		if !req.AllowOriginal {
+			return nil, fmt.Errorf("unauthorized: the original is not public")
+		}
+		return &deliveredFile{
+			url:      media.URL,
+			backend:  media.StorageBackend,
+			name:     path.Base(media.Name),
+			mimeType: media.MimeType,
+			class:    string(media.Type),
+		}, nil
+	}
+
+	for _, variant := range media.Variants {
+		if variant.Name == req.Rendition {
+			return &deliveredFile{
+				url:      variant.URL,
+				backend:  variant.StorageBackend,
+				name:     stem + "_" + variant.Name + path.Ext(variant.URL),
+				mimeType: variant.MimeType,
+				class:    string(media.Type),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("rendition not found")
+}
+
+// Step 4: Refuse media stored in a colder tier until a storage restore task
+// moved them back to the default backend; the move is never made in the
+// request, which would hold it for as long as the files take to copy
+func (uc *DeliverMediaUsecase) rehydrateMedia(ctx context.Context, media *entity.Media) error {
+	target := uc.backends.Default()
+	if uc.rehydrate == entity.RehydrateOff || (media.StorageTier == target.Tier && media.TierStatus == "") {
+		return nil
+	}
+
+	started, err := uc.mediaRepo.MarkRestoring(ctx, media.ID, time.Now().Add(-constants.RestoreStalePeriod*time.Second))
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to start restore of media %s: %v", media.ID, err))
+		return fmt.Errorf("database update failed: %w", err)
+	}
+	if started {
+		_, err := uc.enqueuer.Enqueue(ctx, constants.JobTypeStorageRestore, &task.StorageRestorePayload{
+			MediaID: media.ID,
+		}, task.Options{Queue: constants.QueueMediaProcessing})
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to enqueue restore of media %s: %v", media.ID, err))
+		} else {
+			uc.logger.Info(fmt.Sprintf("Restore of media %s from storage tier %s queued", media.ID, media.StorageTier))
+		}
+	}
+	return fmt.Errorf("media is restoring")
+}
+
+// entityTag derives a strong entity tag from the location, size and
+// modification time of the file: a rewritten or moved file gets a new tag
+func entityTag(backend, url string, obj *blob.Object) string {
+	hash := sha256.New()
+	for _, part := range []string{backend, url, strconv.FormatInt(obj.Size, 10), strconv.FormatInt(obj.ModTime.UnixNano(), 10)} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+}
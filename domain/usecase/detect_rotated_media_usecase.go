@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/imaging"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// DetectRotatedMediaUsecase flags image media stored before EXIF orientation
+// was applied on upload: their file still carries an orientation tag, so their
+// pixels, and the dimensions read from them, are not upright
+type DetectRotatedMediaUsecase struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+	backends  *blob.Registry
+}
+
+// NewDetectRotatedMediaUsecase creates a new detect rotated media usecase
+func NewDetectRotatedMediaUsecase(
+	mediaRepo repository.MediaRepository,
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+) *DetectRotatedMediaUsecase {
+	return &DetectRotatedMediaUsecase{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+		backends:  backends,
+	}
+}
+
+// Execute scans every image media, updates its flag and returns the number flagged
+func (uc *DetectRotatedMediaUsecase) Execute(ctx context.Context) (int, error) {
+	uc.logger.Info("Detecting media stored with a rotation")
+
+	flagged := 0
+	filters := repository.MediaFilters{
+		Type:      entity.MediaTypeImage,
+		Limit:     reprocessPageSize,
+		SortBy:    "created_at",
+		SortOrder: "asc",
+	}
+	for {
+		page, _, err := uc.mediaRepo.List(ctx, filters)
+		if err != nil {
+			return flagged, fmt.Errorf("database retrieval failed: %w", err)
+		}
+		for _, media := range page {
+			if err := ctx.Err(); err != nil {
+				return flagged, err
+			}
+			rotated, err := uc.isRotated(ctx, media)
+			if err != nil {
+				uc.logger.Warn(fmt.Sprintf("Could not inspect media %s: %v", media.ID, err))
+				continue
+			}
+			if rotated {
+				flagged++
+			}
+			if rotated == media.Misoriented {
+				continue
+			}
+			if err := uc.mediaRepo.SetMisoriented(ctx, media.ID, rotated); err != nil {
+				return flagged, fmt.Errorf("database update failed: %w", err)
+			}
+		}
+		if len(page) < filters.Limit {
+			break
+		}
+		filters.Offset += filters.Limit
+	}
+
+	uc.logger.Info(fmt.Sprintf("Rotation detection completed: %d media flagged", flagged))
+	return flagged, nil
+}
+
+// isRotated reports whether the stored file has an orientation other than
+// normal. Its recorded dimensions come from the stored pixels, so they are
+// swapped for rotations by 90 degrees.
+func (uc *DetectRotatedMediaUsecase) isRotated(ctx context.Context, media *entity.Media) (bool, error) {
+	file, err := uc.backends.Open(ctx, media.StorageBackend, media.URL)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return false, err
+	}
+	return imaging.Orientation(data) != imaging.OrientationNormal, nil
+}
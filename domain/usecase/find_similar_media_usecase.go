@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"io"
+	"media-service/constants"
+	"media-service/domain/entity"
+	"media-service/domain/imaging"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// FindSimilarMediaUsecase finds near-duplicate images by perceptual hash
+type FindSimilarMediaUsecase struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+}
+
+// FindSimilarMediaRequest represents a near-duplicate search.
+// Exactly one of MediaID or ProbeData identifies the image to compare against.
+type FindSimilarMediaRequest struct {
+	MediaID     string
+	ProbeData   io.Reader
+	MaxDistance int
+	Limit       int
+	RequestedBy string
+	IsAdmin     bool // admins search across every owner
+}
+
+// SimilarMedia is a search hit with its Hamming distance to the probe
+type SimilarMedia struct {
+	Media    *entity.Media
+	Distance int
+}
+
+// NewFindSimilarMediaUsecase creates a new find similar media usecase
+func NewFindSimilarMediaUsecase(
+	mediaRepo repository.MediaRepository,
+	logger *log.LogGRPCImpl,
+) *FindSimilarMediaUsecase {
+	return &FindSimilarMediaUsecase{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+	}
+}
+
+// Execute returns media whose perceptual hash is within the requested distance
+func (uc *FindSimilarMediaUsecase) Execute(ctx context.Context, req *FindSimilarMediaRequest) ([]*SimilarMedia, error) {
+	uc.logger.Info(fmt.Sprintf("Finding media similar to: %s", req.MediaID))
+
+	// Step 1: Validate and normalize input
+	if err := uc.validateAndNormalizeInput(req); err != nil {
+		uc.logger.Error(fmt.Sprintf("Input validation failed: %v", err))
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Step 2: Resolve the probe hash
+	hash, err := uc.resolveProbeHash(ctx, req)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to resolve probe hash: %v", err))
+		return nil, err
+	}
+
+	// Step 3: Search within the caller's scope
+	filters := repository.SimilarMediaFilters{
+		PHash:       hash,
+		MaxDistance: req.MaxDistance,
+		ExcludeID:   req.MediaID,
+		Limit:       req.Limit,
+	}
+	if !req.IsAdmin {
+		filters.CreatedBy = req.RequestedBy
+	}
+	media, err := uc.mediaRepo.FindSimilar(ctx, filters)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to search similar media: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+
+	// Step 4: Build response
+	results := make([]*SimilarMedia, 0, len(media))
+	for _, m := range media {
+		results = append(results, &SimilarMedia{
+			Media:    m,
+			Distance: imaging.HammingDistance(uint64(hash), uint64(*m.PHash)),
+		})
+	}
+
+	uc.logger.Info(fmt.Sprintf("Found %d similar media", len(results)))
+	return results, nil
+}
+
+// Step 1: Validate and normalize input
+func (uc *FindSimilarMediaUsecase) validateAndNormalizeInput(req *FindSimilarMediaRequest) error {
+	if req.MediaID == "" && req.ProbeData == nil {
+		return fmt.Errorf("media ID or probe image is required")
+	}
+	if req.MediaID != "" && req.ProbeData != nil {
+		return fmt.Errorf("only one of media ID or probe image can be set")
+	}
+	if req.RequestedBy == "" && !req.IsAdmin {
+		return fmt.Errorf("created_by is required")
+	}
+
+	if req.MaxDistance <= 0 {
+		req.MaxDistance = constants.DefaultSimilarDistance
+	}
+	if req.MaxDistance > constants.MaxSimilarDistance {
+		return fmt.Errorf("invalid max distance: %d (max %d)", req.MaxDistance, constants.MaxSimilarDistance)
+	}
+
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	return nil
+}
+
+// Step 2: Resolve the probe hash
+func (uc *FindSimilarMediaUsecase) resolveProbeHash(ctx context.Context, req *FindSimilarMediaRequest) (int64, error) {
+	if req.ProbeData != nil {
+		return probeHash(req.ProbeData)
+	}
+
+	media, err := uc.mediaRepo.GetByID(ctx, req.MediaID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to retrieve media: %w", err)
+	}
+	if media == nil {
+		return 0, fmt.Errorf("media not found")
+	}
+	if !req.IsAdmin && media.CreatedBy != req.RequestedBy {
+		return 0, fmt.Errorf("unauthorized: user %s does not own media %s", req.RequestedBy, media.ID)
+	}
+	if media.PHash == nil {
+		return 0, fmt.Errorf("media %s has no perceptual hash", media.ID)
+	}
+	return *media.PHash, nil
+}
+
+// probeHash hashes an uploaded probe image, checking its size and dimensions
+// before decoding its pixels
+func probeHash(r io.Reader) (int64, error) {
+	data, err := io.ReadAll(io.LimitReader(r, constants.MaxFileSize+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read probe image: %w", err)
+	}
+	if len(data) > constants.MaxFileSize {
+		return 0, fmt.Errorf("validation failed: probe image exceeds %d bytes", constants.MaxFileSize)
+	}
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("validation failed: unsupported probe image: %w", err)
+	}
+	if int64(config.Width)*int64(config.Height) > constants.MaxProbePixels {
+		return 0, fmt.Errorf("validation failed: probe image exceeds %d pixels", constants.MaxProbePixels)
+	}
+
+	img, err := imaging.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("validation failed: %w", err)
+	}
+	return int64(imaging.DHash(img)), nil
+}
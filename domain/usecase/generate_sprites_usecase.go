@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/imaging"
+	"media-service/domain/repository"
+	"media-service/domain/video"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+	"github.com/anhvanhoa/service-core/domain/processing"
+	"github.com/anhvanhoa/service-core/domain/storage"
+	"github.com/anhvanhoa/service-core/utils"
+)
+
+// GenerateSpritesUsecase renders the scrubbing sprite sheets of a video and the
+// WebVTT thumbnail track mapping time ranges to their tiles
+type GenerateSpritesUsecase struct {
+	variantRepo repository.MediaVariantRepository
+	logger      *log.LogGRPCImpl
+	uuid        goid.GoUUID
+	processing  processing.ProcessingI
+	backends    *blob.Registry
+	extractor   video.FrameExtractor
+}
+
+// NewGenerateSpritesUsecase creates a new generate sprites usecase
+func NewGenerateSpritesUsecase(
+	variantRepo repository.MediaVariantRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	processing processing.ProcessingI,
+	backends *blob.Registry,
+	extractor video.FrameExtractor,
+) *GenerateSpritesUsecase {
+	return &GenerateSpritesUsecase{
+		variantRepo: variantRepo,
+		logger:      logger,
+		uuid:        uuid,
+		processing:  processing,
+		backends:    backends,
+		extractor:   extractor,
+	}
+}
+
+// Regenerate renders the sprites again from the stored video
+func (uc *GenerateSpritesUsecase) Regenerate(ctx context.Context, media *entity.Media) ([]*entity.MediaVariant, error) {
+	path, err := copyOriginal(ctx, uc.backends, media, "video-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+	return uc.Execute(ctx, media, path)
+}
+
+// Execute samples a frame every constants.SpriteInterval seconds of the video
+// at path, tiles them into sprite sheets and replaces the previous sprites
+func (uc *GenerateSpritesUsecase) Execute(ctx context.Context, media *entity.Media, path string) ([]*entity.MediaVariant, error) {
+	uc.logger.Info(fmt.Sprintf("Generating sprites for media: %s", media.ID))
+
+	// Step 1: Sample frames
+	tileWidth, tileHeight := spriteTileSize(media)
+	frames, err := uc.extractor.ExtractFrames(ctx, path, constants.SpriteInterval, tileWidth, tileHeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sample frames: %w", err)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("no frames sampled")
+	}
+
+	// Step 2: Render the sheets and the track
+	variants, err := uc.renderSprites(ctx, media, frames, tileWidth, tileHeight)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to render sprites: %v", err))
+		uc.deleteFiles(ctx, variants)
+		return nil, fmt.Errorf("failed to render sprites: %w", err)
+	}
+
+	// Step 3: Replace previous sprites, keeping them served until the new ones are saved
+	previous, err := uc.variantRepo.GetByMediaID(ctx, media.ID)
+	if err != nil {
+		uc.deleteFiles(ctx, variants)
+		return nil, fmt.Errorf("failed to retrieve variants: %w", err)
+	}
+	if err := uc.variantRepo.Upsert(ctx, variants); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to save sprites: %v", err))
+		uc.deleteFiles(ctx, variants)
+		return nil, fmt.Errorf("database save failed: %w", err)
+	}
+
+	// Step 4: Delete replaced sprites, and the sheets of a longer previous render
+	current := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		current[variant.Name] = variant.URL
+	}
+	var stale []*entity.MediaVariant
+	var removed []string
+	for _, variant := range previous {
+		if !entity.IsSpriteVariant(variant.Name) {
+			continue
+		}
+		url, ok := current[variant.Name]
+		if !ok {
+			removed = append(removed, variant.Name)
+		}
+		if url != variant.URL {
+			stale = append(stale, variant)
+		}
+	}
+	if err := uc.variantRepo.DeleteByNames(ctx, media.ID, removed); err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to delete stale sprites of media %s: %v", media.ID, err))
+	} else {
+		uc.deleteFiles(ctx, stale)
+	}
+
+	uc.logger.Info(fmt.Sprintf("Generated %d sprite sheets for media: %s", len(variants)-1, media.ID))
+	return uc.variantRepo.GetByMediaID(ctx, media.ID)
+}
+
+// Step 2: Render the sheets and the track
+func (uc *GenerateSpritesUsecase) renderSprites(ctx context.Context, media *entity.Media, frames []image.Image, tileWidth, tileHeight int) ([]*entity.MediaVariant, error) {
+	perSheet := constants.SpriteColumns * constants.SpriteRows
+	revision := strconv.FormatInt(time.Now().UnixNano(), 36)
+	var duration float64
+	if media.Duration != nil {
+		duration = *media.Duration
+	}
+
+	var (
+		variants []*entity.MediaVariant
+		cues     []video.ThumbnailCue
+	)
+	for first := 0; first < len(frames); first += perSheet {
+		batch := frames[first:min(first+perSheet, len(frames))]
+		sheet := imaging.TileSheet(batch, constants.SpriteColumns, tileWidth, tileHeight)
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, sheet); err != nil {
+			return variants, fmt.Errorf("failed to encode sprite sheet: %w", err)
+		}
+		name := entity.SpriteSheetName(len(variants))
+		outFile := utils.ConvertToSlug(media.ID+"_"+name+"_"+revision) + entity.ExtWebP
+		url, err := uc.processing.ConvertWebPBufferToFile(ctx, &buf, outFile)
+		if err != nil {
+			return variants, fmt.Errorf("failed to store %s: %w", name, err)
+		}
+		variants = append(variants, uc.newVariant(media, name, uc.backends.Default().Name, url, string(entity.MimeTypeWebP), sheet.Bounds().Dx(), sheet.Bounds().Dy()))
+
+		for i := range batch {
+			index := first + i
+			start := float64(index) * constants.SpriteInterval
+			end := start + constants.SpriteInterval
+			if duration > start && duration < end {
+				end = duration
+			}
+			// Sheets are referenced by variant name, relative to the track at
+			// /media/{id}/sprite_track, so the cues stay valid when the
+			// sheets move between storage backends
+			cues = append(cues, video.ThumbnailCue{
+				Start:  start,
+				End:    end,
+				URL:    name,
+				X:      (i % constants.SpriteColumns) * tileWidth,
+				Y:      (i / constants.SpriteColumns) * tileHeight,
+				Width:  tileWidth,
+				Height: tileHeight,
+			})
+		}
+	}
+
+	var track bytes.Buffer
+	if err := video.WriteThumbnailTrack(&track, cues); err != nil {
+		return variants, fmt.Errorf("failed to write thumbnail track: %w", err)
+	}
+	backend, url, err := uc.backends.Upload(ctx, &storage.UploadRequest{
+		FileData:   &track,
+		OutputPath: utils.ConvertToSlug(media.ID+"_"+entity.VariantSpriteTrack+"_"+revision) + entity.ExtVTT,
+	})
+	if err != nil {
+		return variants, fmt.Errorf("failed to store thumbnail track: %w", err)
+	}
+	variants = append(variants, uc.newVariant(media, entity.VariantSpriteTrack, backend, url, string(entity.MimeTypeVTT), tileWidth, tileHeight))
+	return variants, nil
+}
+
+func (uc *GenerateSpritesUsecase) newVariant(media *entity.Media, name, backend, url, mimeType string, width, height int) *entity.MediaVariant {
+	return &entity.MediaVariant{
+		ID:             uc.uuid.Gen(),
+		MediaID:        media.ID,
+		Name:           name,
+		URL:            url,
+		StorageBackend: backend,
+		MimeType:       mimeType,
+		Width:          width,
+		Height:         height,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+}
+
+func (uc *GenerateSpritesUsecase) deleteFiles(ctx context.Context, variants []*entity.MediaVariant) {
+	for _, variant := range variants {
+		if err := uc.backends.Delete(ctx, variant.StorageBackend, variant.URL); err != nil {
+			uc.logger.Warn(fmt.Sprintf("Failed to delete sprite file %s: %v", variant.URL, err))
+		}
+	}
+}
+
+// spriteTileSize keeps the video aspect ratio at the tile width, with an even
+// height as required by the scaler
+func spriteTileSize(media *entity.Media) (int, int) {
+	width := constants.SpriteTileWidth
+	if media.Width == nil || media.Height == nil || *media.Width <= 0 || *media.Height <= 0 {
+		return width, width * 9 / 16
+	}
+	height := int(math.Round(float64(width) * float64(*media.Height) / float64(*media.Width) / 2))
+	return width, max(2, height*2)
+}
+
+// posterOffset is the second of the poster frame, within short videos
+func posterOffset(duration float64) float64 {
+	if duration > 0 && duration < 2*constants.PosterFrameOffset {
+		return duration / 2
+	}
+	return constants.PosterFrameOffset
+}
@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/document"
+	"media-service/domain/entity"
+	"media-service/domain/imaging"
+	"media-service/domain/repository"
+	"media-service/domain/svg"
+	"media-service/domain/video"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+	"github.com/anhvanhoa/service-core/domain/processing"
+	"github.com/anhvanhoa/service-core/utils"
+)
+
+type variantSpec struct {
+	name string
+	size int
+	crop bool // Crop to a square around the focal point, otherwise fit within size
+}
+
+var variantSpecs = []variantSpec{
+	{name: entity.VariantPreview, size: constants.PreviewMaxSize},
+	{name: entity.VariantThumbnailSmall, size: constants.ThumbnailSmallSize, crop: true},
+	{name: entity.VariantThumbnailMedium, size: constants.ThumbnailMediumSize, crop: true},
+	{name: entity.VariantThumbnailLarge, size: constants.ThumbnailLargeSize, crop: true},
+}
+
+// VariantNames returns the names of the variants rendered for every image
+func VariantNames() []string {
+	names := make([]string, len(variantSpecs))
+	for i, spec := range variantSpecs {
+		names[i] = spec.name
+	}
+	return names
+}
+
+// GenerateVariantsUsecase renders the variants (preview, thumbnails) of an image media
+type GenerateVariantsUsecase struct {
+	variantRepo repository.MediaVariantRepository
+	logger      *log.LogGRPCImpl
+	uuid        goid.GoUUID
+	processing  processing.ProcessingI
+	backends    *blob.Registry
+	renderer    document.Renderer
+	rasterizer  svg.Rasterizer
+	extractor   video.FrameExtractor
+	watermark   *ApplyWatermarkUsecase
+}
+
+// NewGenerateVariantsUsecase creates a new generate variants usecase
+func NewGenerateVariantsUsecase(
+	variantRepo repository.MediaVariantRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	processing processing.ProcessingI,
+	backends *blob.Registry,
+	renderer document.Renderer,
+	rasterizer svg.Rasterizer,
+	extractor video.FrameExtractor,
+	watermark *ApplyWatermarkUsecase,
+) *GenerateVariantsUsecase {
+	return &GenerateVariantsUsecase{
+		variantRepo: variantRepo,
+		logger:      logger,
+		uuid:        uuid,
+		processing:  processing,
+		backends:    backends,
+		renderer:    renderer,
+		rasterizer:  rasterizer,
+		extractor:   extractor,
+		watermark:   watermark,
+	}
+}
+
+// Regenerate decodes the stored original of the media and renders its variants again
+func (uc *GenerateVariantsUsecase) Regenerate(ctx context.Context, media *entity.Media) ([]*entity.MediaVariant, error) {
+	var (
+		img image.Image
+		err error
+	)
+	switch {
+	case media.MimeType == string(entity.MimeTypeSVG):
+		img, err = uc.rasterizeSVG(ctx, media)
+	case media.Type == entity.MediaTypeImage:
+		img, err = uc.decodeImage(ctx, media)
+	case media.Type == entity.MediaTypeDocument:
+		img, err = uc.renderDocument(ctx, media)
+	case media.Type == entity.MediaTypeVideo:
+		img, err = uc.extractPoster(ctx, media)
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.Execute(ctx, media, img)
+}
+
+// Execute renders the variants from the decoded image and replaces the previous ones.
+// Crops follow the media focal point, or a smart crop when none is set, and
+// variants with a watermark profile get the overlay applied.
+func (uc *GenerateVariantsUsecase) Execute(ctx context.Context, media *entity.Media, img image.Image) ([]*entity.MediaVariant, error) {
+	uc.logger.Info(fmt.Sprintf("Generating variants for media: %s", media.ID))
+
+	// Step 1: Render variants
+	variants, err := uc.renderVariants(ctx, media, img)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to render variants: %v", err))
+		uc.deleteFiles(ctx, variants)
+		return nil, fmt.Errorf("failed to render variants: %w", err)
+	}
+
+	// Step 2: Replace previous variants, keeping them served until the new ones are saved
+	previous, err := uc.variantRepo.GetByMediaID(ctx, media.ID)
+	if err != nil {
+		uc.deleteFiles(ctx, variants)
+		return nil, fmt.Errorf("failed to retrieve variants: %w", err)
+	}
+	if err := uc.variantRepo.Upsert(ctx, variants); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to save variants: %v", err))
+		uc.deleteFiles(ctx, variants)
+		return nil, fmt.Errorf("database save failed: %w", err)
+	}
+
+	// Step 3: Delete files of replaced variants, leaving those of other renditions (sprites)
+	current := make(map[string]string, len(variants))
+	for _, variant := range variants {
+		current[variant.Name] = variant.URL
+	}
+	var stale []*entity.MediaVariant
+	for _, variant := range previous {
+		if url, ok := current[variant.Name]; ok && url != variant.URL {
+			stale = append(stale, variant)
+		}
+	}
+	uc.deleteFiles(ctx, stale)
+
+	uc.logger.Info(fmt.Sprintf("Generated %d variants for media: %s", len(variants), media.ID))
+	return uc.variantRepo.GetByMediaID(ctx, media.ID)
+}
+
+// Step 1: Render variants
+func (uc *GenerateVariantsUsecase) renderVariants(ctx context.Context, media *entity.Media, img image.Image) ([]*entity.MediaVariant, error) {
+	focal := media.FocalPoint()
+	cropKey := "auto"
+	if focal != nil {
+		cropKey = fmt.Sprintf("%03d-%03d", int(math.Round(focal.X*1000)), int(math.Round(focal.Y*1000)))
+	}
+	// Every render gets new file names, so the files of the current variants are
+	// never overwritten and stay served until the new rows replace them
+	revision := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	variants := make([]*entity.MediaVariant, 0, len(variantSpecs))
+	for _, spec := range variantSpecs {
+		var rendered image.Image
+		if spec.crop {
+			rendered = imaging.Thumbnail(img, spec.size, spec.size, focal)
+		} else {
+			rendered = imaging.Fit(img, spec.size, spec.size)
+		}
+
+		rendered, watermark, err := uc.watermark.Execute(ctx, spec.name, rendered)
+		if err != nil {
+			return variants, err
+		}
+
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, rendered); err != nil {
+			return variants, fmt.Errorf("failed to encode %s: %w", spec.name, err)
+		}
+
+		name := media.ID + "_" + spec.name
+		if spec.crop {
+			name += "_" + cropKey
+		}
+		if watermark != "" {
+			name += "_" + watermark
+		}
+		name += "_" + revision
+		outFile := utils.ConvertToSlug(name) + entity.ExtWebP
+		url, err := uc.processing.ConvertWebPBufferToFile(ctx, &buf, outFile)
+		if err != nil {
+			return variants, fmt.Errorf("failed to store %s: %w", spec.name, err)
+		}
+
+		variants = append(variants, &entity.MediaVariant{
+			ID:             uc.uuid.Gen(),
+			MediaID:        media.ID,
+			Name:           spec.name,
+			URL:            url,
+			StorageBackend: uc.backends.Default().Name,
+			MimeType:       string(entity.MimeTypeWebP),
+			Width:          rendered.Bounds().Dx(),
+			Height:         rendered.Bounds().Dy(),
+			Watermark:      watermark,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
+		})
+	}
+	return variants, nil
+}
+
+func (uc *GenerateVariantsUsecase) decodeImage(ctx context.Context, media *entity.Media) (image.Image, error) {
+	file, err := uc.backends.Open(ctx, media.StorageBackend, media.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open original: %w", err)
+	}
+	defer file.Close()
+	return imaging.Decode(file)
+}
+
+func (uc *GenerateVariantsUsecase) renderDocument(ctx context.Context, media *entity.Media) (image.Image, error) {
+	path, err := copyOriginal(ctx, uc.backends, media, "document-*"+entity.ExtPDF)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+	return uc.renderer.RenderPage(ctx, path, 1)
+}
+
+// rasterizeSVG renders the stored SVG, already sanitized at upload
+func (uc *GenerateVariantsUsecase) rasterizeSVG(ctx context.Context, media *entity.Media) (image.Image, error) {
+	path, err := copyOriginal(ctx, uc.backends, media, "vector-*"+entity.ExtSVG)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	var width, height int
+	if media.Width != nil && media.Height != nil {
+		width, height = *media.Width, *media.Height
+	}
+	width, height = rasterSize(width, height)
+	return uc.rasterizer.Rasterize(ctx, path, width, height)
+}
+
+func (uc *GenerateVariantsUsecase) extractPoster(ctx context.Context, media *entity.Media) (image.Image, error) {
+	path, err := copyOriginal(ctx, uc.backends, media, "video-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	var duration float64
+	if media.Duration != nil {
+		duration = *media.Duration
+	}
+	return uc.extractor.ExtractFrame(ctx, path, posterOffset(duration))
+}
+
+// copyOriginal copies the stored original to a local temp file for the
+// external tools; the caller removes it
+func copyOriginal(ctx context.Context, backends *blob.Registry, media *entity.Media, pattern string) (string, error) {
+	file, err := backends.Open(ctx, media.StorageBackend, media.URL)
+	if err != nil {
+		return "", fmt.Errorf("failed to open original: %w", err)
+	}
+	defer file.Close()
+
+	tmpFile, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("cannot create temp file: %w", err)
+	}
+	defer tmpFile.Close()
+
+	if _, err := io.Copy(tmpFile, file); err != nil {
+		os.Remove(tmpFile.Name())
+		return "", fmt.Errorf("failed to copy original: %w", err)
+	}
+	return tmpFile.Name(), nil
+}
+
+func (uc *GenerateVariantsUsecase) deleteFiles(ctx context.Context, variants []*entity.MediaVariant) {
+	for _, variant := range variants {
+		if err := uc.backends.Delete(ctx, variant.StorageBackend, variant.URL); err != nil {
+			uc.logger.Warn(fmt.Sprintf("Failed to delete variant file %s: %v", variant.URL, err))
+		}
+	}
+}
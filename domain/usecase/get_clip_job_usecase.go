@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// GetClipJobUsecase reports the progress of a clip job
+type GetClipJobUsecase struct {
+	jobRepo repository.ClipJobRepository
+	logger  *log.LogGRPCImpl
+}
+
+// NewGetClipJobUsecase creates a new get clip job usecase
+func NewGetClipJobUsecase(
+	jobRepo repository.ClipJobRepository,
+	logger *log.LogGRPCImpl,
+) *GetClipJobUsecase {
+	return &GetClipJobUsecase{
+		jobRepo: jobRepo,
+		logger:  logger,
+	}
+}
+
+// Execute retrieves a job, visible to its requester and to admins
+func (uc *GetClipJobUsecase) Execute(ctx context.Context, id, requestedBy string, isAdmin bool) (*entity.ClipJob, error) {
+	if id == "" {
+		return nil, fmt.Errorf("validation failed: job ID is required")
+	}
+
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to retrieve clip job: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if job == nil {
+		return nil, fmt.Errorf("clip job not found")
+	}
+	if !isAdmin && job.RequestedBy != requestedBy {
+		return nil, fmt.Errorf("unauthorized: job belongs to another user")
+	}
+	return job, nil
+}
@@ -0,0 +1,44 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/task"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// GetQueueStatsUsecase reports the depth of the media task queues
+type GetQueueStatsUsecase struct {
+	inspector task.Inspector
+	logger    *log.LogGRPCImpl
+}
+
+// NewGetQueueStatsUsecase creates a new get queue stats usecase
+func NewGetQueueStatsUsecase(
+	inspector task.Inspector,
+	logger *log.LogGRPCImpl,
+) *GetQueueStatsUsecase {
+	return &GetQueueStatsUsecase{
+		inspector: inspector,
+		logger:    logger,
+	}
+}
+
+// Execute counts the tasks of every media queue by state, for admins only
+func (uc *GetQueueStatsUsecase) Execute(ctx context.Context, isAdmin bool) ([]*task.QueueStats, error) {
+	if !isAdmin {
+		return nil, fmt.Errorf("unauthorized: admin only")
+	}
+
+	stats := make([]*task.QueueStats, 0, len(MediaQueues))
+	for _, queue := range MediaQueues {
+		s, err := uc.inspector.Stats(ctx, queue)
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to get stats of queue %s: %v", queue, err))
+			return nil, fmt.Errorf("failed to get stats of queue %s: %w", queue, err)
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// GetReprocessJobUsecase reports the progress of a reprocess job
+type GetReprocessJobUsecase struct {
+	jobRepo repository.ReprocessJobRepository
+	logger  *log.LogGRPCImpl
+}
+
+// NewGetReprocessJobUsecase creates a new get reprocess job usecase
+func NewGetReprocessJobUsecase(
+	jobRepo repository.ReprocessJobRepository,
+	logger *log.LogGRPCImpl,
+) *GetReprocessJobUsecase {
+	return &GetReprocessJobUsecase{
+		jobRepo: jobRepo,
+		logger:  logger,
+	}
+}
+
+// Execute retrieves a job, visible to its requester and to admins
+func (uc *GetReprocessJobUsecase) Execute(ctx context.Context, id, requestedBy string, isAdmin bool) (*entity.ReprocessJob, error) {
+	if id == "" {
+		return nil, fmt.Errorf("validation failed: job ID is required")
+	}
+
+	job, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to retrieve reprocess job: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if job == nil {
+		return nil, fmt.Errorf("reprocess job not found")
+	}
+	if !isAdmin && job.RequestedBy != requestedBy {
+		return nil, fmt.Errorf("unauthorized: job belongs to another user")
+	}
+	return job, nil
+}
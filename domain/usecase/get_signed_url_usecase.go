@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/urlsign"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// SignedURLConfig configures the signed delivery URLs; a nil config
+// disables them
+type SignedURLConfig struct {
+	Signer     *urlsign.Signer
+	BaseURL    string // Public address of the delivery server
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// GetSignedURLUsecase issues expiring delivery URLs for a media, one of its
+// variants or one of its text tracks
+type GetSignedURLUsecase struct {
+	mediaRepo repository.MediaRepository
+	trackRepo repository.MediaTrackRepository
+	logger    *log.LogGRPCImpl
+	config    *SignedURLConfig
+}
+
+// GetSignedURLRequest selects the file to sign a URL for
+type GetSignedURLRequest struct {
+	MediaID     string
+	Rendition   string // Variant name; empty or entity.VariantOriginal for the uploaded file
+	TrackID     string // Text track of the media, instead of a rendition
+	TTL         time.Duration
+	ClientIP    string // Binds the URL to one client address when set
+	RequestedBy string
+	IsAdmin     bool
+}
+
+// SignedURL is an issued delivery URL
+type SignedURL struct {
+	URL       string
+	ExpiresAt time.Time
+}
+
+// NewGetSignedURLUsecase creates a new get signed URL usecase
+func NewGetSignedURLUsecase(
+	mediaRepo repository.MediaRepository,
+	trackRepo repository.MediaTrackRepository,
+	logger *log.LogGRPCImpl,
+	config *SignedURLConfig,
+) *GetSignedURLUsecase {
+	return &GetSignedURLUsecase{
+		mediaRepo: mediaRepo,
+		trackRepo: trackRepo,
+		logger:    logger,
+		config:    config,
+	}
+}
+
+// Execute signs a URL for a file of a media owned by the requester, or of
+// any media for admins
+func (uc *GetSignedURLUsecase) Execute(ctx context.Context, req *GetSignedURLRequest) (*SignedURL, error) {
+	if uc.config == nil {
+		return nil, fmt.Errorf("signed URLs are not configured")
+	}
+
+	// Step 1: Validate input
+	if err := uc.validateInput(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Step 2: Check the media and the file
+	media, err := uc.mediaRepo.GetByID(ctx, req.MediaID)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to retrieve media: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if media == nil {
+		return nil, fmt.Errorf("media not found")
+	}
+	if !req.IsAdmin && media.CreatedBy != req.RequestedBy {
+		return nil, fmt.Errorf("unauthorized: media belongs to another user")
+	}
+	if err := uc.checkFile(ctx, media, req); err != nil {
+		return nil, err
+	}
+
+	// Step 3: Sign the URL
+	expires := time.Now().Add(req.TTL).Truncate(time.Second)
+	rendition := signedRendition(req.Rendition, req.TrackID)
+	query := uc.config.Signer.Sign(&urlsign.Claims{
+		MediaID:   media.ID,
+		Rendition: rendition,
+		ClientIP:  req.ClientIP,
+		Expires:   expires,
+	})
+
+	uc.logger.Info(fmt.Sprintf("Signed URL for %s of media %s issued to %s until %s", rendition, media.ID, req.RequestedBy, expires.Format(time.RFC3339)))
+	return &SignedURL{
+		URL:       strings.TrimSuffix(uc.config.BaseURL, "/") + deliveryPath(media.ID, req.Rendition, req.TrackID) + "?" + query.Encode(),
+		ExpiresAt: expires,
+	}, nil
+}
+
+// Step 1: Validate input
+func (uc *GetSignedURLUsecase) validateInput(req *GetSignedURLRequest) error {
+	if req.MediaID == "" {
+		return fmt.Errorf("media ID is required")
+	}
+	if req.TrackID != "" && req.Rendition != "" {
+		return fmt.Errorf("only one of rendition or track can be signed")
+	}
+	if req.Rendition == entity.VariantOriginal {
+		req.Rendition = ""
+	}
+	if req.TTL == 0 {
+		req.TTL = uc.config.DefaultTTL
+	}
+	if req.TTL < time.Second {
+		return fmt.Errorf("TTL must be at least one second")
+	}
+	if req.TTL > uc.config.MaxTTL {
+		return fmt.Errorf("TTL cannot exceed %s", uc.config.MaxTTL)
+	}
+	if req.ClientIP != "" {
+		ip := net.ParseIP(req.ClientIP)
+		if ip == nil {
+			return fmt.Errorf("invalid client IP %q", req.ClientIP)
+		}
+		req.ClientIP = ip.String()
+	}
+	return nil
+}
+
+// Step 2: Check the media and the file
+func (uc *GetSignedURLUsecase) checkFile(ctx context.Context, media *entity.Media, req *GetSignedURLRequest) error {
+	if req.TrackID != "" {
+		track, err := uc.trackRepo.GetByID(ctx, req.TrackID)
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to retrieve track: %v", err))
+			return fmt.Errorf("database retrieval failed: %w", err)
+		}
+		if track == nil || track.MediaID != media.ID {
+			return fmt.Errorf("track not found")
+		}
+		return nil
+	}
+	if req.Rendition == "" {
+		return nil
+	}
+	for _, variant := range media.Variants {
+		if variant.Name == req.Rendition {
+			return nil
+		}
+	}
+	return fmt.Errorf("rendition not found")
+}
+
+// signedRendition names the file a signature grants: a variant, the
+// original or tracks/{id}
+func signedRendition(rendition, trackID string) string {
+	if trackID != "" {
+		return "tracks/" + trackID
+	}
+	if rendition == "" {
+		return entity.VariantOriginal
+	}
+	return rendition
+}
+
+// deliveryPath is the path the delivery server serves a file at
+func deliveryPath(mediaID, rendition, trackID string) string {
+	p := "/media/" + url.PathEscape(mediaID)
+	if trackID != "" {
+		return p + "/tracks/" + url.PathEscape(trackID)
+	}
+	if rendition != "" {
+		p += "/" + url.PathEscape(rendition)
+	}
+	return p
+}
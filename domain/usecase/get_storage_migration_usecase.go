@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// GetStorageMigrationUsecase reports the progress of a storage migration
+type GetStorageMigrationUsecase struct {
+	migrationRepo repository.StorageMigrationRepository
+	logger        *log.LogGRPCImpl
+}
+
+// NewGetStorageMigrationUsecase creates a new get storage migration usecase
+func NewGetStorageMigrationUsecase(
+	migrationRepo repository.StorageMigrationRepository,
+	logger *log.LogGRPCImpl,
+) *GetStorageMigrationUsecase {
+	return &GetStorageMigrationUsecase{
+		migrationRepo: migrationRepo,
+		logger:        logger,
+	}
+}
+
+// Execute retrieves a migration, for admins only
+func (uc *GetStorageMigrationUsecase) Execute(ctx context.Context, id string, isAdmin bool) (*entity.StorageMigration, error) {
+	if !isAdmin {
+		return nil, fmt.Errorf("unauthorized: admin only")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("validation failed: migration ID is required")
+	}
+
+	migration, err := uc.migrationRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to retrieve storage migration: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if migration == nil {
+		return nil, fmt.Errorf("storage migration not found")
+	}
+	return migration, nil
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/task"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// ListDeadTasksUsecase lists the tasks archived after exhausting their retries
+type ListDeadTasksUsecase struct {
+	inspector task.Inspector
+	logger    *log.LogGRPCImpl
+}
+
+type ListDeadTasksRequest struct {
+	Filter  DeadTaskFilter
+	Limit   int
+	Offset  int
+	IsAdmin bool
+}
+
+type ListDeadTasksResponse struct {
+	Tasks []*task.DeadTask
+	Total int
+}
+
+// NewListDeadTasksUsecase creates a new list dead tasks usecase
+func NewListDeadTasksUsecase(
+	inspector task.Inspector,
+	logger *log.LogGRPCImpl,
+) *ListDeadTasksUsecase {
+	return &ListDeadTasksUsecase{
+		inspector: inspector,
+		logger:    logger,
+	}
+}
+
+// Execute lists a page of the dead tasks matching the filter, for admins only
+func (uc *ListDeadTasksUsecase) Execute(ctx context.Context, req *ListDeadTasksRequest) (*ListDeadTasksResponse, error) {
+	// Step 1: Validate and normalize input
+	if !req.IsAdmin {
+		return nil, fmt.Errorf("unauthorized: admin only")
+	}
+	if err := req.Filter.validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20 // Default limit
+	}
+	if req.Limit > 100 {
+		req.Limit = 100 // Max limit
+	}
+	if req.Offset < 0 {
+		req.Offset = 0
+	}
+
+	// Step 2: Select the tasks
+	tasks, err := selectDeadTasks(ctx, uc.inspector, &req.Filter)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to list dead tasks: %v", err))
+		return nil, err
+	}
+
+	// Step 3: Paginate
+	response := &ListDeadTasksResponse{Total: len(tasks)}
+	if req.Offset < len(tasks) {
+		response.Tasks = tasks[req.Offset:min(req.Offset+req.Limit, len(tasks))]
+	}
+	return response, nil
+}
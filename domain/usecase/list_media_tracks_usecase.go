@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// ListMediaTracksUsecase lists the text tracks of a media
+type ListMediaTracksUsecase struct {
+	mediaRepo repository.MediaRepository
+	trackRepo repository.MediaTrackRepository
+	logger    *log.LogGRPCImpl
+}
+
+// NewListMediaTracksUsecase creates a new list media tracks usecase
+func NewListMediaTracksUsecase(
+	mediaRepo repository.MediaRepository,
+	trackRepo repository.MediaTrackRepository,
+	logger *log.LogGRPCImpl,
+) *ListMediaTracksUsecase {
+	return &ListMediaTracksUsecase{
+		mediaRepo: mediaRepo,
+		trackRepo: trackRepo,
+		logger:    logger,
+	}
+}
+
+// Execute returns the tracks of the media, ordered by kind, language and label
+func (uc *ListMediaTracksUsecase) Execute(ctx context.Context, mediaID string) ([]*entity.MediaTrack, error) {
+	if mediaID == "" {
+		return nil, fmt.Errorf("validation failed: media ID is required")
+	}
+
+	media, err := uc.mediaRepo.GetByID(ctx, mediaID)
+	if err != nil {
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if media == nil {
+		return nil, fmt.Errorf("media not found")
+	}
+
+	tracks, err := uc.trackRepo.GetByMediaID(ctx, mediaID)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to retrieve tracks: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	return tracks, nil
+}
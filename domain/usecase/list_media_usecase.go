@@ -3,7 +3,9 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"media-service/constants"
 	"media-service/domain/entity"
+	"media-service/domain/imaging"
 	"media-service/domain/repository"
 
 	"github.com/anhvanhoa/service-core/domain/log"
@@ -20,6 +22,9 @@ type ListMediaRequest struct {
 	CreatedBy string
 	Type      entity.MediaType
 	MimeType  string
+	Color     string  // hex color to match against image palettes
+	ColorDist float64 // max CIEDE2000 distance, defaults to constants.DefaultColorDistance
+	Search    string  // full-text query over document text
 	Limit     int
 	Offset    int
 	SortBy    string // created_at, name, size
@@ -110,6 +115,19 @@ func (uc *ListMediaUsecase) validateAndNormalizeInput(req *ListMediaRequest) err
 		return fmt.Errorf("invalid sort order: %s (must be 'asc' or 'desc')", req.SortOrder)
 	}
 
+	// Validate color filter
+	if req.Color != "" {
+		if _, _, _, err := imaging.ParseHex(req.Color); err != nil {
+			return err
+		}
+		if req.ColorDist <= 0 {
+			req.ColorDist = constants.DefaultColorDistance
+		}
+		if req.ColorDist > constants.MaxColorDistance {
+			return fmt.Errorf("invalid color distance: %v (max %v)", req.ColorDist, constants.MaxColorDistance)
+		}
+	}
+
 	return nil
 }
 
@@ -119,6 +137,9 @@ func (uc *ListMediaUsecase) buildRepositoryFilters(req *ListMediaRequest) reposi
 		CreatedBy: req.CreatedBy,
 		Type:      req.Type,
 		MimeType:  req.MimeType,
+		Color:     req.Color,
+		ColorDist: req.ColorDist,
+		Search:    req.Search,
 		Limit:     req.Limit,
 		Offset:    req.Offset,
 		SortBy:    req.SortBy,
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+	"github.com/anhvanhoa/service-core/domain/storage"
+)
+
+// mediaFileMover moves the files of a media, its variants and tracks between
+// storage backends, for storage migrations and tier changes
+type mediaFileMover struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+	backends  *blob.Registry
+}
+
+func newMediaFileMover(mediaRepo repository.MediaRepository, logger *log.LogGRPCImpl, backends *blob.Registry) *mediaFileMover {
+	return &mediaFileMover{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+		backends:  backends,
+	}
+}
+
+// move copies the files of the media stored in the source backend, or in any
+// backend but the target when source is empty, points their rows at the
+// copies, all at once, and deletes the originals when asked. The media takes
+// the tier of the target once its own file moved. Copies left behind by a
+// failure are overwritten by the next attempt or deleted by the storage
+// reconciliation. It returns the number of files moved.
+func (m *mediaFileMover) move(ctx context.Context, mediaID, source string, target *blob.Backend, deleteSource bool) (int, error) {
+	refs, err := m.mediaRepo.GetMediaFileReferences(ctx, mediaID)
+	if err != nil {
+		return 0, fmt.Errorf("database retrieval failed: %w", err)
+	}
+
+	var moves []*repository.FileMove
+	originalMoved := false
+	for _, ref := range refs {
+		if ref.Backend == target.Name || (source != "" && ref.Backend != source) {
+			continue
+		}
+		from, err := m.backends.Get(ref.Backend)
+		if err != nil {
+			return 0, fmt.Errorf("%s %s: %w", ref.Source, ref.RowID, err)
+		}
+		url, err := copyFile(ctx, from, target, ref.URL)
+		if err != nil {
+			return 0, fmt.Errorf("%s %s: %w", ref.Source, ref.RowID, err)
+		}
+		moves = append(moves, &repository.FileMove{From: ref, Backend: target.Name, URL: url})
+		originalMoved = originalMoved || ref.Source == entity.FileSourceMedia
+	}
+	if len(moves) == 0 {
+		return 0, nil
+	}
+	if err := m.mediaRepo.MoveFiles(ctx, moves); err != nil {
+		return 0, fmt.Errorf("database update failed: %w", err)
+	}
+	if originalMoved {
+		if err := m.mediaRepo.UpdateStorageTier(ctx, mediaID, target.Tier); err != nil {
+			m.logger.Warn(fmt.Sprintf("Failed to record storage tier of media %s: %v", mediaID, err))
+		}
+	}
+
+	if deleteSource {
+		for _, move := range moves {
+			if err := m.backends.Delete(ctx, move.From.Backend, move.From.URL); err != nil {
+				m.logger.Warn(fmt.Sprintf("Failed to delete moved file %s from storage backend %s: %v", move.From.URL, move.From.Backend, err))
+			}
+		}
+	}
+	return len(moves), nil
+}
+
+// copyFile copies a file under the same key, verifying the copy by reading
+// it back and comparing the SHA-256 checksums
+func copyFile(ctx context.Context, source, target *blob.Backend, url string) (string, error) {
+	key, err := source.Lister.Key(url)
+	if err != nil {
+		return "", err
+	}
+	file, err := source.Reader.Open(ctx, url)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", key, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	copied, err := target.Storage.Upload(ctx, &storage.UploadRequest{
+		FileData:   io.TeeReader(file, hash),
+		OutputPath: key,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", key, err)
+	}
+
+	stored, err := checksum(ctx, target, copied)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify %s: %w", key, err)
+	}
+	if !bytes.Equal(stored, hash.Sum(nil)) {
+		return "", fmt.Errorf("checksum mismatch of %s", key)
+	}
+	return copied, nil
+}
+
+func checksum(ctx context.Context, backend *blob.Backend, url string) ([]byte, error) {
+	file, err := backend.Reader.Open(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
@@ -2,13 +2,22 @@ package usecase
 
 import (
 	"context"
+	"media-service/domain/blob"
+	"media-service/domain/document"
 	"media-service/domain/entity"
+	"media-service/domain/heif"
+	"media-service/domain/pipeline"
+	"media-service/domain/progress"
 	"media-service/domain/repository"
+	"media-service/domain/svg"
+	"media-service/domain/task"
+	"media-service/domain/urlsign"
+	"media-service/domain/video"
+	"time"
 
 	"github.com/anhvanhoa/service-core/domain/goid"
 	"github.com/anhvanhoa/service-core/domain/log"
 	"github.com/anhvanhoa/service-core/domain/processing"
-	"github.com/anhvanhoa/service-core/domain/storage"
 )
 
 type MediaUsecases struct {
@@ -18,6 +27,38 @@ type MediaUsecases struct {
 	ListUC         *ListMediaUsecase
 	UpdateUC       *UpdateMediaUsecase
 	DeleteUC       *DeleteMediaUsecase
+	FindSimilarUC  *FindSimilarMediaUsecase
+	VariantsUC     *GenerateVariantsUsecase
+	SpritesUC      *GenerateSpritesUsecase
+	WatermarksUC   *SyncWatermarksUsecase
+	ReprocessUC    *ReprocessMediaUsecase
+	ReprocessJobUC *GetReprocessJobUsecase
+	ReprocessRunUC *RunReprocessTaskUsecase
+	MediaTaskUC    *RunMediaTaskUsecase
+	CleanupUC      *CleanupFilesUsecase
+	ReaperUC       *ReapStuckMediaUsecase
+	ReconcileUC    *ReconcileStorageUsecase
+	DeadTasksUC    *ListDeadTasksUsecase
+	RequeueUC      *RequeueDeadTasksUsecase
+	DeleteTasksUC  *DeleteDeadTasksUsecase
+	QueueStatsUC   *GetQueueStatsUsecase
+	WatchUC        *WatchProcessingUsecase
+	RotationUC     *DetectRotatedMediaUsecase
+	AddTrackUC     *AddMediaTrackUsecase
+	ListTracksUC   *ListMediaTracksUsecase
+	DeleteTrackUC  *DeleteMediaTrackUsecase
+	ClipUC         *CreateClipUsecase
+	ClipJobUC      *GetClipJobUsecase
+	ClipRunUC      *RunClipTaskUsecase
+	MigrationUC    *StartStorageMigrationUsecase
+	MigrationGetUC *GetStorageMigrationUsecase
+	MigrationResUC *ResumeStorageMigrationUsecase
+	MigrationRunUC *RunStorageMigrationTaskUsecase
+	DeliverUC      *DeliverMediaUsecase
+	SignedURLUC    *GetSignedURLUsecase
+	LifecycleUC    *ApplyStorageLifecycleUsecase
+	RestoreRunUC   *RunStorageRestoreTaskUsecase
+	VariantsRunUC  *RunVariantsTaskUsecase
 }
 
 type MediaUsecaseInterfaces interface {
@@ -32,29 +73,175 @@ type MediaUsecaseInterfaces interface {
 	Update(ctx context.Context, id, createdBy string, req *UpdateMediaRequest) (*entity.Media, error)
 
 	Delete(ctx context.Context, id, createdBy string) error
+
+	FindSimilar(ctx context.Context, req *FindSimilarMediaRequest) ([]*SimilarMedia, error)
+
+	SyncWatermarks(ctx context.Context) (int, error)
+
+	ReprocessMedia(ctx context.Context, req *ReprocessMediaRequest) (*entity.ReprocessJob, error)
+
+	GetReprocessJob(ctx context.Context, id, requestedBy string, isAdmin bool) (*entity.ReprocessJob, error)
+
+	RunReprocessTask(ctx context.Context, payload []byte) error
+
+	RunMediaTask(ctx context.Context, taskType string, payload []byte) error
+
+	CleanupFiles(ctx context.Context, payload []byte) error
+
+	ReapStuckMedia(ctx context.Context, timeout time.Duration) (*ReapStuckMediaResult, error)
+
+	ReconcileStorage(ctx context.Context, req *ReconcileStorageRequest) (*ReconcileStorageReport, error)
+
+	ListDeadTasks(ctx context.Context, req *ListDeadTasksRequest) (*ListDeadTasksResponse, error)
+
+	RequeueDeadTasks(ctx context.Context, filter *DeadTaskFilter, isAdmin bool) (int, error)
+
+	DeleteDeadTasks(ctx context.Context, filter *DeadTaskFilter, isAdmin bool) (int, error)
+
+	GetQueueStats(ctx context.Context, isAdmin bool) ([]*task.QueueStats, error)
+
+	WatchProcessing(ctx context.Context, req *WatchProcessingRequest, send func(*progress.Event) error) error
+
+	DetectRotatedMedia(ctx context.Context) (int, error)
+
+	AddMediaTrack(ctx context.Context, req *AddMediaTrackRequest) (*entity.MediaTrack, error)
+
+	ListMediaTracks(ctx context.Context, mediaID string) ([]*entity.MediaTrack, error)
+
+	DeleteMediaTrack(ctx context.Context, trackID, createdBy string) error
+
+	CreateClip(ctx context.Context, req *CreateClipRequest) (*entity.ClipJob, error)
+
+	GetClipJob(ctx context.Context, id, requestedBy string, isAdmin bool) (*entity.ClipJob, error)
+
+	RunClipTask(ctx context.Context, payload []byte) error
+
+	StartStorageMigration(ctx context.Context, req *StartStorageMigrationRequest) (*entity.StorageMigration, error)
+
+	GetStorageMigration(ctx context.Context, id string, isAdmin bool) (*entity.StorageMigration, error)
+
+	ResumeStorageMigration(ctx context.Context, id string, isAdmin bool) (*entity.StorageMigration, error)
+
+	RunStorageMigrationTask(ctx context.Context, payload []byte) error
+
+	DeliverMedia(ctx context.Context, req *DeliverMediaRequest) (*Delivery, error)
+
+	GetSignedURL(ctx context.Context, req *GetSignedURLRequest) (*SignedURL, error)
+
+	ApplyStorageLifecycle(ctx context.Context) (*StorageLifecycleResult, error)
+
+	RunStorageRestoreTask(ctx context.Context, payload []byte) error
+
+	RunVariantsTask(ctx context.Context, payload []byte) error
 }
 
 func NewMediaUsecases(
 	mediaRepo repository.MediaRepository,
+	variantRepo repository.MediaVariantRepository,
+	reprocessJobRepo repository.ReprocessJobRepository,
+	pipelineRunRepo repository.PipelineRunRepository,
+	trackRepo repository.MediaTrackRepository,
+	clipJobRepo repository.ClipJobRepository,
+	processingJobRepo repository.ProcessingJobRepository,
+	migrationRepo repository.StorageMigrationRepository,
 	logger *log.LogGRPCImpl,
 	processing processing.ProcessingI,
-	storage storage.StorageI,
+	backends *blob.Registry,
+	documentInspector document.Inspector,
+	documentRenderer document.Renderer,
+	svgRasterizer svg.Rasterizer,
+	heifDecoder heif.Decoder,
+	videoProber video.Prober,
+	frameExtractor video.FrameExtractor,
+	videoClipper video.Clipper,
+	watermarkProfiles []*entity.WatermarkProfile,
+	enqueuer task.Enqueuer,
+	taskInspector task.Inspector,
+	progressBroker progress.Broker,
+	pipelineRegistry *pipeline.Registry,
+	pipelineConfigs map[string][]pipeline.StepConfig,
+	signedURLs *SignedURLConfig,
+	lifecycleRules []*entity.LifecycleRule,
+	rehydrate entity.RehydrateMode,
 ) MediaUsecaseInterfaces {
 	goid := goid.NewGoId().UUID()
+	var signer *urlsign.Signer
+	if signedURLs != nil {
+		signer = signedURLs.Signer
+	}
+	watermarkUC := NewApplyWatermarkUsecase(
+		mediaRepo,
+		logger,
+		backends,
+		watermarkProfiles,
+	)
+	variantsUC := NewGenerateVariantsUsecase(
+		variantRepo,
+		logger,
+		goid,
+		processing,
+		backends,
+		documentRenderer,
+		svgRasterizer,
+		frameExtractor,
+		watermarkUC,
+	)
+	spritesUC := NewGenerateSpritesUsecase(
+		variantRepo,
+		logger,
+		goid,
+		processing,
+		backends,
+		frameExtractor,
+	)
+	mediaTaskUC := NewRunMediaTaskUsecase(
+		mediaRepo,
+		processingJobRepo,
+		logger,
+		goid,
+		enqueuer,
+		progressBroker,
+		variantsUC,
+		spritesUC,
+	)
+	steps := &pipelineSteps{
+		mediaRepo:   mediaRepo,
+		logger:      logger,
+		processing:  processing,
+		backends:    backends,
+		inspector:   documentInspector,
+		renderer:    documentRenderer,
+		rasterizer:  svgRasterizer,
+		heifDecoder: heifDecoder,
+		prober:      videoProber,
+		extractor:   frameExtractor,
+		sprites:     spritesUC,
+		variants:    variantsUC,
+	}
+	if pipelineRegistry == nil {
+		pipelineRegistry = pipeline.NewRegistry()
+	}
+	steps.register(pipelineRegistry)
+	processUC := NewProcessUploadUsecase(
+		mediaRepo,
+		pipelineRunRepo,
+		processingJobRepo,
+		logger,
+		goid,
+		progressBroker,
+		pipelineRegistry,
+		pipelineConfigs,
+	)
 	return &MediaUsecases{
 		UploadUC: NewUploadMediaUsecase(
-			mediaRepo,
 			logger,
-			goid,
 			processing,
-			storage,
+			processUC,
 		),
 		UploadStreamUC: NewUploadMediaStreamUsecase(
-			mediaRepo,
 			logger,
-			goid,
 			processing,
-			storage,
+			processUC,
 		),
 		GetUC: NewGetMediaUsecase(
 			mediaRepo,
@@ -67,11 +254,180 @@ func NewMediaUsecases(
 		UpdateUC: NewUpdateMediaUsecase(
 			mediaRepo,
 			logger,
+			enqueuer,
 		),
 		DeleteUC: NewDeleteMediaUsecase(
 			mediaRepo,
+			variantRepo,
+			trackRepo,
+			logger,
+			backends,
+		),
+		FindSimilarUC: NewFindSimilarMediaUsecase(
+			mediaRepo,
+			logger,
+		),
+		VariantsUC: variantsUC,
+		SpritesUC:  spritesUC,
+		WatermarksUC: NewSyncWatermarksUsecase(
+			mediaRepo,
+			variantRepo,
+			logger,
+			watermarkUC,
+			variantsUC,
+		),
+		ReprocessUC: NewReprocessMediaUsecase(
+			mediaRepo,
+			reprocessJobRepo,
+			logger,
+			goid,
+			enqueuer,
+		),
+		ReprocessJobUC: NewGetReprocessJobUsecase(
+			reprocessJobRepo,
+			logger,
+		),
+		ReprocessRunUC: NewRunReprocessTaskUsecase(
+			reprocessJobRepo,
+			logger,
+			mediaTaskUC,
+		),
+		MediaTaskUC: mediaTaskUC,
+		CleanupUC: NewCleanupFilesUsecase(
+			logger,
+			backends,
+		),
+		ReaperUC: NewReapStuckMediaUsecase(
+			mediaRepo,
+			logger,
+			enqueuer,
+			progressBroker,
+		),
+		ReconcileUC: NewReconcileStorageUsecase(
+			mediaRepo,
+			logger,
+			backends,
+			enqueuer,
+		),
+		DeadTasksUC: NewListDeadTasksUsecase(
+			taskInspector,
+			logger,
+		),
+		RequeueUC: NewRequeueDeadTasksUsecase(
+			taskInspector,
+			logger,
+		),
+		DeleteTasksUC: NewDeleteDeadTasksUsecase(
+			taskInspector,
+			logger,
+		),
+		QueueStatsUC: NewGetQueueStatsUsecase(
+			taskInspector,
+			logger,
+		),
+		WatchUC: NewWatchProcessingUsecase(
+			mediaRepo,
+			progressBroker,
+			logger,
+		),
+		RotationUC: NewDetectRotatedMediaUsecase(
+			mediaRepo,
+			logger,
+			backends,
+		),
+		AddTrackUC: NewAddMediaTrackUsecase(
+			mediaRepo,
+			trackRepo,
+			logger,
+			goid,
+			backends,
+		),
+		ListTracksUC: NewListMediaTracksUsecase(
+			mediaRepo,
+			trackRepo,
+			logger,
+		),
+		DeleteTrackUC: NewDeleteMediaTrackUsecase(
+			mediaRepo,
+			trackRepo,
+			logger,
+			backends,
+		),
+		ClipUC: NewCreateClipUsecase(
+			mediaRepo,
+			clipJobRepo,
+			logger,
+			goid,
+			enqueuer,
+		),
+		ClipJobUC: NewGetClipJobUsecase(
+			clipJobRepo,
+			logger,
+		),
+		ClipRunUC: NewRunClipTaskUsecase(
+			mediaRepo,
+			clipJobRepo,
+			logger,
+			goid,
+			backends,
+			videoClipper,
+			processUC,
+		),
+		MigrationUC: NewStartStorageMigrationUsecase(
+			mediaRepo,
+			migrationRepo,
+			logger,
+			goid,
+			backends,
+			enqueuer,
+		),
+		MigrationGetUC: NewGetStorageMigrationUsecase(
+			migrationRepo,
+			logger,
+		),
+		MigrationResUC: NewResumeStorageMigrationUsecase(
+			mediaRepo,
+			migrationRepo,
 			logger,
-			storage,
+			enqueuer,
+		),
+		MigrationRunUC: NewRunStorageMigrationTaskUsecase(
+			mediaRepo,
+			migrationRepo,
+			logger,
+			backends,
+			enqueuer,
+		),
+		DeliverUC: NewDeliverMediaUsecase(
+			mediaRepo,
+			trackRepo,
+			logger,
+			backends,
+			signer,
+			enqueuer,
+			rehydrate,
+		),
+		SignedURLUC: NewGetSignedURLUsecase(
+			mediaRepo,
+			trackRepo,
+			logger,
+			signedURLs,
+		),
+		LifecycleUC: NewApplyStorageLifecycleUsecase(
+			mediaRepo,
+			logger,
+			backends,
+			lifecycleRules,
+		),
+		RestoreRunUC: NewRunStorageRestoreTaskUsecase(
+			mediaRepo,
+			logger,
+			backends,
+		),
+		VariantsRunUC: NewRunVariantsTaskUsecase(
+			mediaRepo,
+			logger,
+			variantsUC,
 		),
 	}
 }
@@ -100,3 +456,123 @@ func (m *MediaUsecases) Update(ctx context.Context, id, createdBy string, req *U
 func (m *MediaUsecases) Delete(ctx context.Context, id, createdBy string) error {
 	return m.DeleteUC.Execute(ctx, id, createdBy)
 }
+
+func (m *MediaUsecases) FindSimilar(ctx context.Context, req *FindSimilarMediaRequest) ([]*SimilarMedia, error) {
+	return m.FindSimilarUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) SyncWatermarks(ctx context.Context) (int, error) {
+	return m.WatermarksUC.Execute(ctx)
+}
+
+func (m *MediaUsecases) ReprocessMedia(ctx context.Context, req *ReprocessMediaRequest) (*entity.ReprocessJob, error) {
+	return m.ReprocessUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) GetReprocessJob(ctx context.Context, id, requestedBy string, isAdmin bool) (*entity.ReprocessJob, error) {
+	return m.ReprocessJobUC.Execute(ctx, id, requestedBy, isAdmin)
+}
+
+func (m *MediaUsecases) RunReprocessTask(ctx context.Context, payload []byte) error {
+	return m.ReprocessRunUC.Execute(ctx, payload)
+}
+
+func (m *MediaUsecases) RunMediaTask(ctx context.Context, taskType string, payload []byte) error {
+	return m.MediaTaskUC.Execute(ctx, taskType, payload)
+}
+
+func (m *MediaUsecases) CleanupFiles(ctx context.Context, payload []byte) error {
+	return m.CleanupUC.Execute(ctx, payload)
+}
+
+func (m *MediaUsecases) ReapStuckMedia(ctx context.Context, timeout time.Duration) (*ReapStuckMediaResult, error) {
+	return m.ReaperUC.Execute(ctx, timeout)
+}
+
+func (m *MediaUsecases) ReconcileStorage(ctx context.Context, req *ReconcileStorageRequest) (*ReconcileStorageReport, error) {
+	return m.ReconcileUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) ListDeadTasks(ctx context.Context, req *ListDeadTasksRequest) (*ListDeadTasksResponse, error) {
+	return m.DeadTasksUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) RequeueDeadTasks(ctx context.Context, filter *DeadTaskFilter, isAdmin bool) (int, error) {
+	return m.RequeueUC.Execute(ctx, filter, isAdmin)
+}
+
+func (m *MediaUsecases) DeleteDeadTasks(ctx context.Context, filter *DeadTaskFilter, isAdmin bool) (int, error) {
+	return m.DeleteTasksUC.Execute(ctx, filter, isAdmin)
+}
+
+func (m *MediaUsecases) GetQueueStats(ctx context.Context, isAdmin bool) ([]*task.QueueStats, error) {
+	return m.QueueStatsUC.Execute(ctx, isAdmin)
+}
+
+func (m *MediaUsecases) WatchProcessing(ctx context.Context, req *WatchProcessingRequest, send func(*progress.Event) error) error {
+	return m.WatchUC.Execute(ctx, req, send)
+}
+
+func (m *MediaUsecases) DetectRotatedMedia(ctx context.Context) (int, error) {
+	return m.RotationUC.Execute(ctx)
+}
+
+func (m *MediaUsecases) AddMediaTrack(ctx context.Context, req *AddMediaTrackRequest) (*entity.MediaTrack, error) {
+	return m.AddTrackUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) ListMediaTracks(ctx context.Context, mediaID string) ([]*entity.MediaTrack, error) {
+	return m.ListTracksUC.Execute(ctx, mediaID)
+}
+
+func (m *MediaUsecases) DeleteMediaTrack(ctx context.Context, trackID, createdBy string) error {
+	return m.DeleteTrackUC.Execute(ctx, trackID, createdBy)
+}
+
+func (m *MediaUsecases) CreateClip(ctx context.Context, req *CreateClipRequest) (*entity.ClipJob, error) {
+	return m.ClipUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) GetClipJob(ctx context.Context, id, requestedBy string, isAdmin bool) (*entity.ClipJob, error) {
+	return m.ClipJobUC.Execute(ctx, id, requestedBy, isAdmin)
+}
+
+func (m *MediaUsecases) RunClipTask(ctx context.Context, payload []byte) error {
+	return m.ClipRunUC.Execute(ctx, payload)
+}
+
+func (m *MediaUsecases) StartStorageMigration(ctx context.Context, req *StartStorageMigrationRequest) (*entity.StorageMigration, error) {
+	return m.MigrationUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) GetStorageMigration(ctx context.Context, id string, isAdmin bool) (*entity.StorageMigration, error) {
+	return m.MigrationGetUC.Execute(ctx, id, isAdmin)
+}
+
+func (m *MediaUsecases) ResumeStorageMigration(ctx context.Context, id string, isAdmin bool) (*entity.StorageMigration, error) {
+	return m.MigrationResUC.Execute(ctx, id, isAdmin)
+}
+
+func (m *MediaUsecases) RunStorageMigrationTask(ctx context.Context, payload []byte) error {
+	return m.MigrationRunUC.Execute(ctx, payload)
+}
+
+func (m *MediaUsecases) DeliverMedia(ctx context.Context, req *DeliverMediaRequest) (*Delivery, error) {
+	return m.DeliverUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) GetSignedURL(ctx context.Context, req *GetSignedURLRequest) (*SignedURL, error) {
+	return m.SignedURLUC.Execute(ctx, req)
+}
+
+func (m *MediaUsecases) ApplyStorageLifecycle(ctx context.Context) (*StorageLifecycleResult, error) {
+	return m.LifecycleUC.Execute(ctx)
+}
+
+func (m *MediaUsecases) RunStorageRestoreTask(ctx context.Context, payload []byte) error {
+	return m.RestoreRunUC.Execute(ctx, payload)
+}
+
+func (m *MediaUsecases) RunVariantsTask(ctx context.Context, payload []byte) error {
+	return m.VariantsRunUC.Execute(ctx, payload)
+}
@@ -0,0 +1,830 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/document"
+	"media-service/domain/entity"
+	"media-service/domain/heif"
+	"media-service/domain/imaging"
+	"media-service/domain/pipeline"
+	"media-service/domain/repository"
+	"media-service/domain/svg"
+	"media-service/domain/video"
+	"os"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+	"github.com/anhvanhoa/service-core/domain/processing"
+	"github.com/anhvanhoa/service-core/domain/storage"
+	"github.com/anhvanhoa/service-core/utils"
+)
+
+// Pipeline families, selected from the sniffed MIME type of an upload
+const (
+	PipelineFamilyImage    = "image"
+	PipelineFamilyDocument = "document"
+	PipelineFamilySVG      = "svg"
+	PipelineFamilyHEIF     = "heif"
+	PipelineFamilyVideo    = "video"
+)
+
+// Built-in pipeline steps
+const (
+	StepValidate      = "validate"
+	StepStripMetadata = "strip-metadata"
+	StepDecode        = "decode"
+	StepResize        = "resize"
+	StepEncode        = "encode"
+	StepPalette       = "palette"
+	StepHash          = "hash"
+	StepSave          = "save"
+	StepThumbnail     = "thumbnail"
+	StepInspect       = "inspect"
+	StepExtractText   = "extract-text"
+	StepStore         = "store"
+	StepRenderPreview = "render-preview"
+	StepSanitize      = "sanitize"
+	StepRasterize     = "rasterize"
+	StepDecodeHEIF    = "decode-heif"
+	StepProbe         = "probe"
+	StepPoster        = "poster"
+	StepSprites       = "sprites"
+)
+
+// PipelineUpload describes the uploaded file a pipeline processes
+type PipelineUpload struct {
+	ID        string
+	FileName  string
+	CreatedBy string
+	Metadata  map[string]string
+	Size      int64
+	Type      entity.MediaType
+	SourceID  string // Media the upload was derived from, such as the video of a clip
+	Priority  entity.Priority
+}
+
+// FileFormat is the container format of an upload stored as-is
+type FileFormat struct {
+	MimeType string
+	Ext      string
+}
+
+// StoredFile is the stored rendition of an upload
+type StoredFile struct {
+	URL      string
+	Backend  string // Storage backend holding the file
+	MimeType string
+	Width    int
+	Height   int
+}
+
+// State keys shared by the built-in steps, available to custom ones
+var (
+	KeyUpload      = pipeline.Key[*PipelineUpload]("upload")
+	KeySource      = pipeline.Key[string]("source") // Path of the local copy of the upload
+	KeyDimensions  = pipeline.Key[*MediaMetadata]("dimensions")
+	KeyImage       = pipeline.Key[image.Image]("image")
+	KeyStored      = pipeline.Key[*StoredFile]("stored")
+	KeyPalette     = pipeline.Key[[]entity.PaletteColor]("palette")
+	KeyPHash       = pipeline.Key[int64]("phash")
+	KeyDocument    = pipeline.Key[*document.Info]("document")
+	KeyContent     = pipeline.Key[string]("content")
+	KeyMedia       = pipeline.Key[*entity.Media]("media")
+	KeyVideo       = pipeline.Key[*video.Info]("video")
+	KeyFormat      = pipeline.Key[*FileFormat]("format")
+	KeyOrientation = pipeline.Key[int]("orientation") // EXIF orientation of the upload, read before its metadata is stripped
+)
+
+// DefaultPipelines returns the step graphs used for families without configuration
+func DefaultPipelines() map[string][]pipeline.StepConfig {
+	return map[string][]pipeline.StepConfig{
+		PipelineFamilyImage: {
+			{Name: StepValidate},
+			{Name: StepStripMetadata},
+			{Name: StepDecode, Optional: true},
+			{Name: StepEncode},
+			{Name: StepPalette, Optional: true},
+			{Name: StepHash, Optional: true},
+			{Name: StepSave},
+			{Name: StepThumbnail, Optional: true},
+		},
+		PipelineFamilyDocument: {
+			{Name: StepInspect},
+			{Name: StepExtractText, Optional: true},
+			{Name: StepStore, After: []string{StepInspect}, Params: map[string]string{
+				"mime_type": string(entity.MimeTypePDF),
+				"ext":       entity.ExtPDF,
+			}},
+			{Name: StepSave},
+			{Name: StepRenderPreview, Optional: true},
+			{Name: StepThumbnail, Optional: true},
+		},
+		PipelineFamilyHEIF: {
+			{Name: StepDecodeHEIF},
+			{Name: StepEncode},
+			{Name: StepPalette, Optional: true},
+			{Name: StepHash, Optional: true},
+			{Name: StepSave},
+			{Name: StepThumbnail, Optional: true},
+		},
+		PipelineFamilyVideo: {
+			{Name: StepProbe},
+			{Name: StepStore},
+			{Name: StepPoster, Optional: true},
+			{Name: StepSave},
+			{Name: StepThumbnail, Optional: true},
+			{Name: StepSprites, Optional: true, After: []string{StepThumbnail}},
+		},
+		PipelineFamilySVG: {
+			{Name: StepSanitize},
+			{Name: StepStore, Params: map[string]string{
+				"mime_type": string(entity.MimeTypeSVG),
+				"ext":       entity.ExtSVG,
+			}},
+			{Name: StepRasterize, Optional: true},
+			{Name: StepPalette, Optional: true},
+			{Name: StepHash, Optional: true},
+			{Name: StepSave},
+			{Name: StepThumbnail, Optional: true},
+		},
+	}
+}
+
+// stepFunc adapts a function to pipeline.Step
+type stepFunc struct {
+	spec pipeline.Spec
+	run  func(ctx context.Context, state *pipeline.State) error
+}
+
+func (s *stepFunc) Spec() pipeline.Spec {
+	return s.spec
+}
+
+func (s *stepFunc) Run(ctx context.Context, state *pipeline.State) error {
+	return s.run(ctx, state)
+}
+
+// pipelineSteps implements the built-in steps over the usecase dependencies
+type pipelineSteps struct {
+	mediaRepo   repository.MediaRepository
+	logger      *log.LogGRPCImpl
+	processing  processing.ProcessingI
+	backends    *blob.Registry
+	inspector   document.Inspector
+	renderer    document.Renderer
+	rasterizer  svg.Rasterizer
+	heifDecoder heif.Decoder
+	prober      video.Prober
+	extractor   video.FrameExtractor
+	sprites     *GenerateSpritesUsecase
+	variants    *GenerateVariantsUsecase
+}
+
+// register adds the built-in steps to the registry, except those already
+// registered under the same name, which replace them
+func (s *pipelineSteps) register(registry *pipeline.Registry) {
+	factories := map[string]pipeline.Factory{
+		StepValidate:      s.validate,
+		StepStripMetadata: s.stripMetadata,
+		StepDecode:        s.decode,
+		StepResize:        s.resize,
+		StepEncode:        s.encode,
+		StepPalette:       s.palette,
+		StepHash:          s.hash,
+		StepSave:          s.save,
+		StepThumbnail:     s.thumbnail,
+		StepInspect:       s.inspect,
+		StepExtractText:   s.extractText,
+		StepStore:         s.store,
+		StepRenderPreview: s.renderPreview,
+		StepSanitize:      s.sanitize,
+		StepRasterize:     s.rasterize,
+		StepDecodeHEIF:    s.decodeHEIF,
+		StepProbe:         s.probe,
+		StepPoster:        s.poster,
+		StepSprites:       s.generateSprites,
+	}
+	for name, factory := range factories {
+		if registry.Has(name) {
+			s.logger.Info(fmt.Sprintf("Pipeline step %s is overridden", name))
+			continue
+		}
+		_ = registry.Register(name, factory)
+	}
+}
+
+// validate checks the upload size and reads its dimensions
+func (s *pipelineSteps) validate(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeySource.String()}, Outputs: []string{KeyDimensions.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			info, err := file.Stat()
+			if err != nil {
+				return err
+			}
+			if info.Size() > constants.MaxFileSize {
+				return fmt.Errorf("validation failed: file exceeds %d bytes", constants.MaxFileSize)
+			}
+			data, err := io.ReadAll(file)
+			if err != nil {
+				return err
+			}
+
+			meta, err := s.processing.ExtractImageMetadata(ctx, bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("could not extract metadata: %w", err)
+			}
+			// Dimensions are those of the upright image
+			width, height := meta.Width, meta.Height
+			if imaging.SwapsAxes(imaging.Orientation(data)) {
+				width, height = height, width
+			}
+			pipeline.Set(state, KeyDimensions, &MediaMetadata{Width: width, Height: height})
+			return nil
+		},
+	}, nil
+}
+
+// stripMetadata replaces the source with a copy without EXIF, XMP and text
+// metadata, keeping its orientation in the state for decode
+func (s *pipelineSteps) stripMetadata(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs:  []string{KeySource.String()},
+			Outputs: []string{KeySource.String(), KeyOrientation.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			pipeline.Set(state, KeyOrientation, imaging.Orientation(data))
+			stripped, ok, err := imaging.StripMetadata(data)
+			if err != nil {
+				return fmt.Errorf("failed to strip metadata: %w", err)
+			}
+			if !ok || len(stripped) == len(data) {
+				return nil
+			}
+			return setSourceBytes(state, stripped)
+		},
+	}, nil
+}
+
+// decode decodes the source pixels upright, with the orientation of the
+// source or the one read before its metadata was stripped
+func (s *pipelineSteps) decode(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs:   []string{KeySource.String()},
+			Optional: []string{KeyOrientation.String()},
+			Outputs:  []string{KeyImage.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			orientation, ok := pipeline.Get(state, KeyOrientation)
+			if !ok {
+				orientation = imaging.OrientationNormal
+			}
+			img, err := imaging.DecodeOriented(file, orientation)
+			if err != nil {
+				return fmt.Errorf("could not decode image: %w", err)
+			}
+			pipeline.Set(state, KeyImage, img)
+			return nil
+		},
+	}, nil
+}
+
+// resize scales the pixels down to max_width x max_height
+func (s *pipelineSteps) resize(params map[string]string) (pipeline.Step, error) {
+	maxWidth, err := intParam(params, "max_width", constants.MaxImageWidth)
+	if err != nil {
+		return nil, err
+	}
+	maxHeight, err := intParam(params, "max_height", constants.MaxImageHeight)
+	if err != nil {
+		return nil, err
+	}
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeyImage.String()}, Outputs: []string{KeyImage.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			img, _ := pipeline.Get(state, KeyImage)
+			pipeline.Set(state, KeyImage, imaging.Fit(img, maxWidth, maxHeight))
+			return nil
+		},
+	}, nil
+}
+
+// encode stores the image as WebP, from the decoded pixels when available
+func (s *pipelineSteps) encode(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs:   []string{KeyUpload.String(), KeySource.String()},
+			Optional: []string{KeyImage.String(), KeyDimensions.String()},
+			Outputs:  []string{KeyStored.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			upload, _ := pipeline.Get(state, KeyUpload)
+			// The processing service writes to the default backend
+			stored := &StoredFile{Backend: s.backends.Default().Name, MimeType: string(entity.MimeTypeWebP)}
+
+			var data io.Reader
+			if img, ok := pipeline.Get(state, KeyImage); ok {
+				// The pixels are streamed to the converter as they are encoded
+				// rather than buffered as a whole PNG
+				r, w := io.Pipe()
+				defer r.Close()
+				go func() {
+					encoder := png.Encoder{CompressionLevel: png.BestSpeed}
+					w.CloseWithError(encoder.Encode(w, img))
+				}()
+				data = r
+				stored.Width, stored.Height = img.Bounds().Dx(), img.Bounds().Dy()
+			} else {
+				path, _ := pipeline.Get(state, KeySource)
+				file, err := os.Open(path)
+				if err != nil {
+					return err
+				}
+				defer file.Close()
+				data = file
+				if dims, ok := pipeline.Get(state, KeyDimensions); ok {
+					stored.Width, stored.Height = dims.Width, dims.Height
+				}
+			}
+
+			url, err := s.processing.ConvertWebPBufferToFile(ctx, data, utils.ConvertToSlug(upload.FileName)+entity.ExtWebP)
+			if err != nil {
+				return fmt.Errorf("storage upload failed: %w", err)
+			}
+			stored.URL = url
+			pipeline.Set(state, KeyStored, stored)
+			return nil
+		},
+	}, nil
+}
+
+// palette extracts the dominant colors
+func (s *pipelineSteps) palette(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeyImage.String()}, Outputs: []string{KeyPalette.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			img, _ := pipeline.Get(state, KeyImage)
+			pipeline.Set(state, KeyPalette, imaging.ExtractPalette(img, constants.PaletteSize))
+			return nil
+		},
+	}, nil
+}
+
+// hash computes the perceptual hash used by near-duplicate search
+func (s *pipelineSteps) hash(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeyImage.String()}, Outputs: []string{KeyPHash.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			img, _ := pipeline.Get(state, KeyImage)
+			pipeline.Set(state, KeyPHash, int64(imaging.DHash(img)))
+			return nil
+		},
+	}, nil
+}
+
+// save creates the media record from the stored file and the collected features
+func (s *pipelineSteps) save(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs: []string{KeyUpload.String(), KeyStored.String()},
+			Optional: []string{
+				KeyPalette.String(),
+				KeyPHash.String(),
+				KeyDocument.String(),
+				KeyContent.String(),
+				KeyVideo.String(),
+			},
+			Outputs: []string{KeyMedia.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			upload, _ := pipeline.Get(state, KeyUpload)
+			stored, _ := pipeline.Get(state, KeyStored)
+
+			media := &entity.Media{
+				ID:               upload.ID,
+				Name:             upload.FileName,
+				Size:             upload.Size,
+				URL:              stored.URL,
+				StorageBackend:   stored.Backend,
+				StorageTier:      s.backends.Tier(stored.Backend),
+				MimeType:         stored.MimeType,
+				Type:             upload.Type,
+				ProcessingStatus: entity.ProcessingStatusProcessing, // Completed once the remaining steps ran
+				Attempts:         1,
+				Priority:         upload.Priority,
+				CreatedBy:        upload.CreatedBy,
+				Metadata:         upload.Metadata,
+				CreatedAt:        time.Now(),
+				UpdatedAt:        time.Now(),
+			}
+			if stored.Width > 0 && stored.Height > 0 {
+				media.Width, media.Height = &stored.Width, &stored.Height
+			}
+			if upload.SourceID != "" {
+				media.SourceMediaID = &upload.SourceID
+			}
+			if palette, ok := pipeline.Get(state, KeyPalette); ok {
+				media.Palette = palette
+			}
+			if hash, ok := pipeline.Get(state, KeyPHash); ok {
+				media.PHash = &hash
+			}
+			if info, ok := pipeline.Get(state, KeyDocument); ok {
+				media.PageCount = &info.PageCount
+				media.Metadata = documentMetadata(info, upload.Metadata)
+			}
+			if content, ok := pipeline.Get(state, KeyContent); ok {
+				media.Content = content
+			}
+			if info, ok := pipeline.Get(state, KeyVideo); ok && info.Duration > 0 {
+				media.Duration = &info.Duration
+			}
+
+			if err := s.mediaRepo.Create(ctx, media); err != nil {
+				_ = s.backends.Delete(ctx, stored.Backend, stored.URL)
+				return fmt.Errorf("database save failed: %w", err)
+			}
+			pipeline.Set(state, KeyMedia, media)
+			return nil
+		},
+	}, nil
+}
+
+// thumbnail renders the preview and thumbnail variants of the saved media
+func (s *pipelineSteps) thumbnail(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeyMedia.String(), KeyImage.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			media, _ := pipeline.Get(state, KeyMedia)
+			img, _ := pipeline.Get(state, KeyImage)
+			variants, err := s.variants.Execute(ctx, media, img)
+			if err != nil {
+				return err
+			}
+			media.Variants = variants
+			return nil
+		},
+	}, nil
+}
+
+// inspect validates a document and reads its page count, title and author
+func (s *pipelineSteps) inspect(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeySource.String()}, Outputs: []string{KeyDocument.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			info, err := s.inspector.Inspect(ctx, path)
+			if err != nil {
+				return fmt.Errorf("validation failed: %w", err)
+			}
+			pipeline.Set(state, KeyDocument, info)
+			return nil
+		},
+	}, nil
+}
+
+// extractText extracts the searchable text of a document
+func (s *pipelineSteps) extractText(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeySource.String()}, Outputs: []string{KeyContent.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			content, err := s.inspector.ExtractText(ctx, path)
+			if err != nil {
+				return fmt.Errorf("could not extract document text: %w", err)
+			}
+			pipeline.Set(state, KeyContent, truncateText(content, constants.MaxDocumentTextLength))
+			return nil
+		},
+	}, nil
+}
+
+// store uploads the source as-is, with the format of the mime_type and ext
+// params or else the one detected by an earlier step
+func (s *pipelineSteps) store(params map[string]string) (pipeline.Step, error) {
+	configured := &FileFormat{MimeType: params["mime_type"], Ext: params["ext"]}
+	if (configured.MimeType == "") != (configured.Ext == "") {
+		return nil, fmt.Errorf("mime_type and ext params go together")
+	}
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs:   []string{KeyUpload.String(), KeySource.String()},
+			Optional: []string{KeyDimensions.String(), KeyFormat.String()},
+			Outputs:  []string{KeyStored.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			upload, _ := pipeline.Get(state, KeyUpload)
+			path, _ := pipeline.Get(state, KeySource)
+			format := configured
+			if format.MimeType == "" {
+				detected, ok := pipeline.Get(state, KeyFormat)
+				if !ok {
+					return fmt.Errorf("unknown file format")
+				}
+				format = detected
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+
+			backend, url, err := s.backends.Upload(ctx, &storage.UploadRequest{
+				FileData:   file,
+				OutputPath: utils.ConvertToSlug(upload.ID+"_"+upload.FileName) + format.Ext,
+			})
+			if err != nil {
+				return fmt.Errorf("storage upload failed: %w", err)
+			}
+			stored := &StoredFile{URL: url, Backend: backend, MimeType: format.MimeType}
+			if dims, ok := pipeline.Get(state, KeyDimensions); ok {
+				stored.Width, stored.Height = dims.Width, dims.Height
+			}
+			pipeline.Set(state, KeyStored, stored)
+			return nil
+		},
+	}, nil
+}
+
+// renderPreview renders the first page of a document
+func (s *pipelineSteps) renderPreview(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeySource.String()}, Outputs: []string{KeyImage.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			img, err := s.renderer.RenderPage(ctx, path, 1)
+			if err != nil {
+				return fmt.Errorf("could not render document preview: %w", err)
+			}
+			pipeline.Set(state, KeyImage, img)
+			return nil
+		},
+	}, nil
+}
+
+// sanitize rewrites an SVG without active content and reads its intrinsic size
+func (s *pipelineSteps) sanitize(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs:  []string{KeySource.String()},
+			Outputs: []string{KeySource.String(), KeyDimensions.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			if err := checkFileSize(path); err != nil {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			sanitized, err := svg.Sanitize(data)
+			if err != nil {
+				return fmt.Errorf("validation failed: %w", err)
+			}
+			dims, err := svg.Dimensions(sanitized)
+			if err != nil {
+				return fmt.Errorf("validation failed: %w", err)
+			}
+			pipeline.Set(state, KeyDimensions, &MediaMetadata{
+				Width:  int(math.Ceil(dims.Width)),
+				Height: int(math.Ceil(dims.Height)),
+			})
+			return setSourceBytes(state, sanitized)
+		},
+	}, nil
+}
+
+// rasterize renders an SVG with its long side at the preview size
+func (s *pipelineSteps) rasterize(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs:  []string{KeySource.String(), KeyDimensions.String()},
+			Outputs: []string{KeyImage.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			dims, _ := pipeline.Get(state, KeyDimensions)
+			width, height := rasterSize(dims.Width, dims.Height)
+			img, err := s.rasterizer.Rasterize(ctx, path, width, height)
+			if err != nil {
+				return fmt.Errorf("could not rasterize svg: %w", err)
+			}
+			pipeline.Set(state, KeyImage, img)
+			return nil
+		},
+	}, nil
+}
+
+// decodeHEIF decodes the primary image of a HEIF file, upright and in sRGB
+func (s *pipelineSteps) decodeHEIF(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs:  []string{KeySource.String()},
+			Outputs: []string{KeyImage.String(), KeyDimensions.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			if err := checkFileSize(path); err != nil {
+				return err
+			}
+			file, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			info, err := heif.Parse(file)
+			file.Close()
+			if errors.Is(err, heif.ErrUnsupported) {
+				return err
+			}
+			if err != nil {
+				return fmt.Errorf("validation failed: invalid HEIF file: %w", err)
+			}
+
+			img, err := s.heifDecoder.Decode(ctx, path, info)
+			if err != nil {
+				return fmt.Errorf("could not decode HEIF image: %w", err)
+			}
+			img = heif.Normalize(img, info)
+			pipeline.Set(state, KeyImage, img)
+			pipeline.Set(state, KeyDimensions, &MediaMetadata{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()})
+			return nil
+		},
+	}, nil
+}
+
+// probe validates a video and reads its size, duration and container format
+func (s *pipelineSteps) probe(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{
+			Inputs:  []string{KeySource.String()},
+			Outputs: []string{KeyVideo.String(), KeyDimensions.String(), KeyFormat.String()},
+		},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			if err := checkFileSize(path); err != nil {
+				return err
+			}
+			info, err := s.prober.Probe(ctx, path)
+			if errors.Is(err, video.ErrUnsupported) {
+				return err
+			}
+			if err != nil {
+				return fmt.Errorf("validation failed: %w", err)
+			}
+			if info.Duration > constants.MaxVideoDuration {
+				return fmt.Errorf("validation failed: video exceeds %d seconds", constants.MaxVideoDuration)
+			}
+			pipeline.Set(state, KeyVideo, info)
+			pipeline.Set(state, KeyDimensions, &MediaMetadata{
+				Width:    info.Width,
+				Height:   info.Height,
+				Duration: info.Duration,
+				Format:   info.Ext,
+			})
+			pipeline.Set(state, KeyFormat, &FileFormat{MimeType: info.MimeType, Ext: info.Ext})
+			return nil
+		},
+	}, nil
+}
+
+// poster extracts the frame the preview and thumbnails of a video are rendered from
+func (s *pipelineSteps) poster(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeySource.String(), KeyVideo.String()}, Outputs: []string{KeyImage.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			path, _ := pipeline.Get(state, KeySource)
+			info, _ := pipeline.Get(state, KeyVideo)
+			img, err := s.extractor.ExtractFrame(ctx, path, posterOffset(info.Duration))
+			if err != nil {
+				return fmt.Errorf("could not extract poster frame: %w", err)
+			}
+			pipeline.Set(state, KeyImage, img)
+			return nil
+		},
+	}, nil
+}
+
+// generateSprites renders the scrubbing sprite sheets and thumbnail track of a video
+func (s *pipelineSteps) generateSprites(map[string]string) (pipeline.Step, error) {
+	return &stepFunc{
+		spec: pipeline.Spec{Inputs: []string{KeyMedia.String(), KeySource.String()}},
+		run: func(ctx context.Context, state *pipeline.State) error {
+			media, _ := pipeline.Get(state, KeyMedia)
+			path, _ := pipeline.Get(state, KeySource)
+			variants, err := s.sprites.Execute(ctx, media, path)
+			if err != nil {
+				return err
+			}
+			media.Variants = variants
+			return nil
+		},
+	}, nil
+}
+
+func checkFileSize(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.Size() > constants.MaxFileSize {
+		return fmt.Errorf("validation failed: file exceeds %d bytes", constants.MaxFileSize)
+	}
+	return nil
+}
+
+// setSourceBytes writes data to a temp file, removed with the run, and makes it the source
+func setSourceBytes(state *pipeline.State, data []byte) error {
+	tmpFile, err := os.CreateTemp("", "pipeline-*")
+	if err != nil {
+		return fmt.Errorf("cannot create temp file: %w", err)
+	}
+	state.Defer(func() { os.Remove(tmpFile.Name()) })
+	defer tmpFile.Close()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		return err
+	}
+	pipeline.Set(state, KeySource, tmpFile.Name())
+	return nil
+}
+
+// rasterSize scales a vector size so its long side is the preview size
+func rasterSize(width, height int) (int, int) {
+	if width <= 0 || height <= 0 {
+		return constants.PreviewMaxSize, constants.PreviewMaxSize
+	}
+	scale := float64(constants.PreviewMaxSize) / float64(max(width, height))
+	return max(1, int(math.Round(float64(width)*scale))), max(1, int(math.Round(float64(height)*scale)))
+}
+
+func documentMetadata(info *document.Info, uploaded map[string]string) map[string]string {
+	metadata := make(map[string]string, len(uploaded)+2)
+	if info.Title != "" {
+		metadata["title"] = info.Title
+	}
+	if info.Author != "" {
+		metadata["author"] = info.Author
+	}
+	for key, value := range uploaded {
+		metadata[key] = value
+	}
+	return metadata
+}
+
+func intParam(params map[string]string, name string, fallback int) (int, error) {
+	value, ok := params[name]
+	if !ok {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid %s: %s", name, value)
+	}
+	return n, nil
+}
+
+// truncateText cuts s to at most limit bytes without splitting a UTF-8 sequence
+func truncateText(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	s = s[:limit]
+	for len(s) > 0 && !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/document"
+	"media-service/domain/entity"
+	"media-service/domain/heif"
+	"media-service/domain/pipeline"
+	"media-service/domain/progress"
+	"media-service/domain/repository"
+	"media-service/domain/svg"
+	"media-service/domain/video"
+	"os"
+	"strings"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// ProcessUploadUsecase runs the pipeline of the MIME family of an uploaded file
+type ProcessUploadUsecase struct {
+	mediaRepo repository.MediaRepository
+	runRepo   repository.PipelineRunRepository
+	jobRepo   repository.ProcessingJobRepository
+	logger    *log.LogGRPCImpl
+	uuid      goid.GoUUID
+	tracker   *processingTracker
+	pipelines map[string]*pipeline.Pipeline
+}
+
+// NewProcessUploadUsecase builds the pipeline of every family from the registry.
+// A family whose configuration is invalid is logged and falls back to its default graph.
+func NewProcessUploadUsecase(
+	mediaRepo repository.MediaRepository,
+	runRepo repository.PipelineRunRepository,
+	jobRepo repository.ProcessingJobRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	publisher progress.Publisher,
+	registry *pipeline.Registry,
+	configs map[string][]pipeline.StepConfig,
+) *ProcessUploadUsecase {
+	uc := &ProcessUploadUsecase{
+		mediaRepo: mediaRepo,
+		runRepo:   runRepo,
+		jobRepo:   jobRepo,
+		logger:    logger,
+		uuid:      uuid,
+		tracker:   newProcessingTracker(mediaRepo, publisher, logger),
+		pipelines: make(map[string]*pipeline.Pipeline),
+	}
+	defaults := DefaultPipelines()
+	for family, steps := range defaults {
+		if configured, ok := configs[family]; ok {
+			p, err := registry.Build(family, configured)
+			if err == nil {
+				uc.pipelines[family] = p
+				continue
+			}
+			logger.Error(fmt.Sprintf("Invalid pipeline configuration, using the default: %v", err))
+		}
+		p, err := registry.Build(family, steps)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Invalid default pipeline: %v", err))
+			continue
+		}
+		uc.pipelines[family] = p
+	}
+	for family := range configs {
+		if _, ok := defaults[family]; !ok {
+			logger.Warn(fmt.Sprintf("Ignoring pipeline of unknown family %s", family))
+		}
+	}
+	return uc
+}
+
+// Execute processes the local file at path and returns the saved media
+func (uc *ProcessUploadUsecase) Execute(ctx context.Context, upload *PipelineUpload, path string) (*entity.Media, error) {
+	// Step 1: Select the pipeline
+	family, err := detectFamily(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	p, ok := uc.pipelines[family]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format: no %s pipeline", family)
+	}
+	if upload.FileName == "" {
+		upload.FileName = upload.ID
+	}
+	upload.Type = familyMediaType(family)
+
+	// Step 2: Run it
+	state := pipeline.NewState()
+	defer state.Close()
+	pipeline.Set(state, KeyUpload, upload)
+	pipeline.Set(state, KeySource, path)
+	state.OnStep(func(result entity.PipelineStepResult, finished, total int) {
+		uc.tracker.progress(ctx, upload.ID, result.Name, finished*100/total)
+	})
+	run, runErr := p.Run(ctx, state)
+	uc.logRun(upload.ID, run)
+
+	// Step 3: Record the run once the media exists
+	media, saved := pipeline.Get(state, KeyMedia)
+	if saved {
+		run.ID = uc.uuid.Gen()
+		run.MediaID = media.ID
+		if err := uc.runRepo.Create(ctx, run); err != nil {
+			uc.logger.Warn(fmt.Sprintf("Failed to record pipeline run of media %s: %v", media.ID, err))
+		}
+	}
+
+	// Step 4: Finish the processing attempt of the saved media
+	if saved {
+		uc.finishAttempt(ctx, media, run, runErr)
+	}
+	if runErr != nil {
+		return nil, runErr
+	}
+	return media, nil
+}
+
+// finishAttempt records the upload as the first processing attempt of the media
+// and completes or fails it
+func (uc *ProcessUploadUsecase) finishAttempt(ctx context.Context, media *entity.Media, run *entity.PipelineRun, runErr error) {
+	job := &entity.ProcessingJob{
+		ID:        uc.uuid.Gen(),
+		MediaID:   media.ID,
+		Attempt:   media.Attempts,
+		Trigger:   entity.ProcessingTriggerUpload,
+		StartedAt: run.StartedAt,
+	}
+	job.Finish(runErr)
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to record processing attempt of media %s: %v", media.ID, err))
+	}
+
+	to, reason := entity.ProcessingStatusCompleted, ""
+	if runErr != nil {
+		to, reason = entity.ProcessingStatusFailed, runErr.Error()
+	}
+	if err := uc.tracker.transition(ctx, media, to, reason); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to mark media %s as %s: %v", media.ID, to, err))
+	}
+}
+
+func (uc *ProcessUploadUsecase) logRun(mediaID string, run *entity.PipelineRun) {
+	steps := make([]string, len(run.Steps))
+	for i, step := range run.Steps {
+		steps[i] = fmt.Sprintf("%s=%s(%dms)", step.Name, step.Status, step.DurationMs)
+	}
+	message := fmt.Sprintf("Pipeline %s %s for media %s in %dms: %s",
+		run.Family, run.Status, mediaID, run.DurationMs, strings.Join(steps, " "))
+	if run.Status == entity.PipelineRunStatusFailed {
+		uc.logger.Warn(message + ": " + run.Error)
+		return
+	}
+	uc.logger.Info(message)
+}
+
+// detectFamily sniffs the file; anything but a document, an SVG, a HEIF
+// image or a video is handed to the image pipeline, whose validation rejects what it cannot read
+func detectFamily(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	isPDF, err := document.IsPDF(file)
+	if err != nil {
+		return "", err
+	}
+	if isPDF {
+		return PipelineFamilyDocument, nil
+	}
+	isSVG, err := svg.IsSVG(file)
+	if err != nil {
+		return "", err
+	}
+	if isSVG {
+		return PipelineFamilySVG, nil
+	}
+	isHEIF, err := heif.IsHEIF(file)
+	if err != nil {
+		return "", err
+	}
+	if isHEIF {
+		return PipelineFamilyHEIF, nil
+	}
+	isVideo, err := video.IsVideo(file)
+	if err != nil {
+		return "", err
+	}
+	if isVideo {
+		return PipelineFamilyVideo, nil
+	}
+	return PipelineFamilyImage, nil
+}
+
+func familyMediaType(family string) entity.MediaType {
+	switch family {
+	case PipelineFamilyDocument:
+		return entity.MediaTypeDocument
+	case PipelineFamilyVideo:
+		return entity.MediaTypeVideo
+	}
+	return entity.MediaTypeImage
+}
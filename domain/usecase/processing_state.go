@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/entity"
+	"media-service/domain/progress"
+	"media-service/domain/repository"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// processingTracker saves the processing status changes of media and
+// publishes them, with the progress of their steps, to the watchers
+type processingTracker struct {
+	mediaRepo repository.MediaRepository
+	publisher progress.Publisher
+	logger    *log.LogGRPCImpl
+}
+
+func newProcessingTracker(mediaRepo repository.MediaRepository, publisher progress.Publisher, logger *log.LogGRPCImpl) *processingTracker {
+	return &processingTracker{
+		mediaRepo: mediaRepo,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// transition moves the media to status to and saves it, failing with
+// entity.ErrInvalidTransition when the media changed concurrently
+func (t *processingTracker) transition(ctx context.Context, media *entity.Media, to entity.ProcessingStatus, reason string) error {
+	from := media.ProcessingStatus
+	if err := media.TransitionProcessing(to, reason); err != nil {
+		return err
+	}
+	if err := t.mediaRepo.UpdateProcessingStatus(ctx, media, from); err != nil {
+		return err
+	}
+
+	event := &progress.Event{
+		MediaID: media.ID,
+		Status:  to,
+		Reason:  media.LastError,
+		At:      time.Now(),
+	}
+	if to == entity.ProcessingStatusCompleted {
+		event.Percent = 100
+	}
+	t.publish(ctx, event)
+	return nil
+}
+
+// progress reports the step a processing media is running
+func (t *processingTracker) progress(ctx context.Context, mediaID, stage string, percent int) {
+	t.publish(ctx, &progress.Event{
+		MediaID: mediaID,
+		Status:  entity.ProcessingStatusProcessing,
+		Stage:   stage,
+		Percent: percent,
+		At:      time.Now(),
+	})
+}
+
+// publish is best effort: watchers fall back to the status saved on the media
+func (t *processingTracker) publish(ctx context.Context, event *progress.Event) {
+	if t.publisher == nil {
+		return
+	}
+	if err := t.publisher.Publish(ctx, event); err != nil {
+		t.logger.Warn(fmt.Sprintf("Failed to publish progress of media %s: %v", event.MediaID, err))
+	}
+}
+
+// retryDelay is the exponential backoff after the given failed attempt
+func retryDelay(attempt int) time.Duration {
+	delay := constants.ProcessingRetryBaseDelay * time.Second
+	limit := constants.ProcessingRetryMaxDelay * time.Second
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	return min(delay, limit)
+}
+
+// processingQueue is the weighted queue of the processing tasks of a priority
+func processingQueue(priority entity.Priority) string {
+	if priority == entity.PriorityBulk {
+		return constants.QueueMediaBulk
+	}
+	return constants.QueueMediaProcessing
+}
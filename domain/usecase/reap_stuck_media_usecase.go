@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/entity"
+	"media-service/domain/progress"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// stuckReason is recorded on media whose attempt was abandoned, typically by a crashed worker
+const stuckReason = "processing timed out"
+
+// ReapStuckMediaUsecase requeues media left pending or processing, or fails
+// them once they used all their attempts
+type ReapStuckMediaUsecase struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+	enqueuer  task.Enqueuer
+	tracker   *processingTracker
+}
+
+// ReapStuckMediaResult summarizes one sweep
+type ReapStuckMediaResult struct {
+	Found    int
+	Requeued int
+	Failed   int
+	Errors   int
+}
+
+// NewReapStuckMediaUsecase creates a new reap stuck media usecase
+func NewReapStuckMediaUsecase(
+	mediaRepo repository.MediaRepository,
+	logger *log.LogGRPCImpl,
+	enqueuer task.Enqueuer,
+	publisher progress.Publisher,
+) *ReapStuckMediaUsecase {
+	return &ReapStuckMediaUsecase{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+		enqueuer:  enqueuer,
+		tracker:   newProcessingTracker(mediaRepo, publisher, logger),
+	}
+}
+
+// Execute handles up to constants.ReaperBatchSize media unchanged for longer than timeout
+func (uc *ReapStuckMediaUsecase) Execute(ctx context.Context, timeout time.Duration) (*ReapStuckMediaResult, error) {
+	// Step 1: Find the stuck media
+	stuck, err := uc.mediaRepo.GetPendingProcessing(ctx, time.Now().Add(-timeout), constants.ReaperBatchSize)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to find stuck media: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+
+	// Step 2: Requeue or fail each of them
+	result := &ReapStuckMediaResult{Found: len(stuck)}
+	for _, media := range stuck {
+		failed, err := uc.reap(ctx, media)
+		switch {
+		case err != nil:
+			uc.logger.Warn(fmt.Sprintf("Failed to reap media %s: %v", media.ID, err))
+			result.Errors++
+		case failed:
+			result.Failed++
+		default:
+			result.Requeued++
+		}
+	}
+
+	uc.logger.Info(fmt.Sprintf("Stuck media sweep: %d found, %d requeued, %d failed, %d errors",
+		result.Found, result.Requeued, result.Failed, result.Errors))
+	return result, nil
+}
+
+// reap fails the media when it has no attempts left and requeues it otherwise
+func (uc *ReapStuckMediaUsecase) reap(ctx context.Context, media *entity.Media) (bool, error) {
+	if media.Attempts >= constants.MaxProcessingAttempts {
+		reason := media.LastError
+		if media.ProcessingStatus == entity.ProcessingStatusProcessing || reason == "" {
+			reason = stuckReason
+		}
+		return true, uc.tracker.transition(ctx, media, entity.ProcessingStatusFailed, reason)
+	}
+
+	if media.ProcessingStatus == entity.ProcessingStatusProcessing {
+		if err := uc.tracker.transition(ctx, media, entity.ProcessingStatusPending, stuckReason); err != nil {
+			return false, err
+		}
+	} else if err := uc.mediaRepo.UpdateProcessingStatus(ctx, media, entity.ProcessingStatusPending); err != nil {
+		// Saving the unchanged pending status restarts its timeout
+		return false, err
+	}
+
+	_, err := uc.enqueuer.Enqueue(ctx, constants.JobTypeMediaProcess, &task.MediaPayload{
+		MediaID: media.ID,
+		Owner:   media.CreatedBy,
+	}, task.Options{Queue: processingQueue(media.Priority)})
+	if err != nil {
+		return false, fmt.Errorf("enqueue failed: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"sort"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// ReconcileStorageRequest configures a reconciliation run
+type ReconcileStorageRequest struct {
+	Delete      bool          // Enqueue the deletion of orphan files; dry run otherwise
+	GracePeriod time.Duration // Zero is constants.DefaultOrphanGracePeriod
+}
+
+// ReconcileStorageReport lists the drift between storage and the media tables
+type ReconcileStorageReport struct {
+	Files        int                     // Stored files scanned
+	References   int                     // Rows referencing a file scanned
+	OrphanFiles  []*blob.Object          // Files no row references
+	MissingFiles []*entity.FileReference // Rows whose file is not stored
+	Deleted      int                     // Orphan files enqueued for deletion
+}
+
+// ReconcileStorageUsecase compares the files stored in every backend with the
+// files referenced by media, variants and tracks. Only orphan files are ever
+// deleted, through cleanup_files tasks; rows without a file are reported for
+// inspection.
+type ReconcileStorageUsecase struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+	backends  *blob.Registry
+	enqueuer  task.Enqueuer
+}
+
+// NewReconcileStorageUsecase creates a new reconcile storage usecase
+func NewReconcileStorageUsecase(
+	mediaRepo repository.MediaRepository,
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+	enqueuer task.Enqueuer,
+) *ReconcileStorageUsecase {
+	return &ReconcileStorageUsecase{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+		backends:  backends,
+		enqueuer:  enqueuer,
+	}
+}
+
+// Execute reports the files and rows older than the grace period that have no
+// counterpart, and deletes the orphan files when asked
+func (uc *ReconcileStorageUsecase) Execute(ctx context.Context, req *ReconcileStorageRequest) (*ReconcileStorageReport, error) {
+	// Step 1: Validate and normalize input
+	if err := uc.validateAndNormalizeInput(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	cutoff := time.Now().Add(-req.GracePeriod)
+	report := &ReconcileStorageReport{}
+
+	// Step 2: Collect the referenced files; rows are read before the files are
+	// listed so a file uploaded in between is not reported without its row
+	refs, err := uc.collectReferences(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range uc.backends.Names() {
+		backend, _ := uc.backends.Get(name)
+
+		// Step 3: List the stored files of the backend, keeping the old
+		// unreferenced ones
+		stored := make(map[string]bool)
+		err = backend.Lister.List(ctx, func(obj *blob.Object) error {
+			report.Files++
+			stored[obj.Key] = true
+			if len(refs[name][obj.Key]) == 0 && obj.ModTime.Before(cutoff) {
+				obj.Backend = name
+				report.OrphanFiles = append(report.OrphanFiles, obj)
+			}
+			return nil
+		})
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to list files of storage backend %s: %v", name, err))
+			return nil, fmt.Errorf("storage listing failed: %w", err)
+		}
+
+		// Step 4: Keep the old rows whose file is not stored
+		for key, rows := range refs[name] {
+			if stored[key] {
+				continue
+			}
+			for _, ref := range rows {
+				if ref.CreatedAt.Before(cutoff) {
+					report.MissingFiles = append(report.MissingFiles, ref)
+				}
+			}
+		}
+	}
+	sort.Slice(report.MissingFiles, func(i, j int) bool {
+		a, b := report.MissingFiles[i], report.MissingFiles[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.RowID < b.RowID
+	})
+
+	// Step 5: Enqueue the deletion of orphan files
+	if req.Delete {
+		report.Deleted = uc.enqueueCleanup(ctx, report.OrphanFiles)
+	}
+
+	uc.logger.Info(fmt.Sprintf("Storage reconciled: %d files, %d references, %d orphan files, %d missing files, %d deletions enqueued",
+		report.Files, report.References, len(report.OrphanFiles), len(report.MissingFiles), report.Deleted))
+	return report, nil
+}
+
+// Step 1: Validate and normalize input
+func (uc *ReconcileStorageUsecase) validateAndNormalizeInput(req *ReconcileStorageRequest) error {
+	if req.GracePeriod < 0 {
+		return fmt.Errorf("grace period cannot be negative")
+	}
+	if req.GracePeriod == 0 {
+		req.GracePeriod = constants.DefaultOrphanGracePeriod * time.Second
+	}
+	if req.Delete && req.GracePeriod < constants.MinOrphanGracePeriod*time.Second {
+		return fmt.Errorf("grace period must be at least %v to delete", constants.MinOrphanGracePeriod*time.Second)
+	}
+	return nil
+}
+
+// Step 2: Collect the referenced files by backend and key; a URL outside of
+// its backend is keyed by itself, so its row is reported as missing its file.
+// Rows of a backend no longer configured are skipped.
+func (uc *ReconcileStorageUsecase) collectReferences(ctx context.Context, report *ReconcileStorageReport) (map[string]map[string][]*entity.FileReference, error) {
+	refs := make(map[string]map[string][]*entity.FileReference)
+	unknown := make(map[string]bool)
+	var after *entity.FileReference
+	for {
+		page, err := uc.mediaRepo.GetFileReferences(ctx, after, constants.ReconcilePageSize)
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to retrieve file references: %v", err))
+			return nil, fmt.Errorf("database retrieval failed: %w", err)
+		}
+		for _, ref := range page {
+			backend, err := uc.backends.Get(ref.Backend)
+			if err != nil {
+				if !unknown[ref.Backend] {
+					unknown[ref.Backend] = true
+					uc.logger.Warn(fmt.Sprintf("Skipping rows of unconfigured storage backend %s", ref.Backend))
+				}
+				continue
+			}
+			key, err := backend.Lister.Key(ref.URL)
+			if err != nil {
+				uc.logger.Warn(fmt.Sprintf("Unresolvable URL of %s %s: %v", ref.Source, ref.RowID, err))
+				key = ref.URL
+			}
+			if refs[backend.Name] == nil {
+				refs[backend.Name] = make(map[string][]*entity.FileReference)
+			}
+			refs[backend.Name][key] = append(refs[backend.Name][key], ref)
+		}
+		report.References += len(page)
+		if len(page) < constants.ReconcilePageSize {
+			return refs, nil
+		}
+		after = page[len(page)-1]
+	}
+}
+
+// Step 5: Enqueue cleanup_files tasks in batches of a single backend,
+// returning the number of files enqueued
+func (uc *ReconcileStorageUsecase) enqueueCleanup(ctx context.Context, orphans []*blob.Object) int {
+	byBackend := make(map[string][]string)
+	for _, obj := range orphans {
+		byBackend[obj.Backend] = append(byBackend[obj.Backend], obj.URL)
+	}
+	enqueued := 0
+	for _, name := range uc.backends.Names() {
+		enqueued += uc.enqueueBackendCleanup(ctx, name, byBackend[name])
+	}
+	return enqueued
+}
+
+func (uc *ReconcileStorageUsecase) enqueueBackendCleanup(ctx context.Context, backend string, orphans []string) int {
+	enqueued := 0
+	for start := 0; start < len(orphans); start += constants.CleanupBatchSize {
+		urls := orphans[start:min(start+constants.CleanupBatchSize, len(orphans))]
+		_, err := uc.enqueuer.Enqueue(ctx, constants.JobTypeCleanupFiles, &task.CleanupPayload{
+			URLs:    urls,
+			Backend: backend,
+		}, task.Options{Queue: constants.QueueMediaCleanup})
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to enqueue deletion of %d orphan files: %v", len(urls), err))
+			continue
+		}
+		enqueued += len(urls)
+	}
+	return enqueued
+}
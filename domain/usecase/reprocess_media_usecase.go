@@ -0,0 +1,222 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/entity"
+	"media-service/domain/imaging"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"strconv"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+const reprocessPageSize = 100
+
+// ReprocessMediaUsecase enqueues the regeneration of the variants of one media or a filtered set
+type ReprocessMediaUsecase struct {
+	mediaRepo repository.MediaRepository
+	jobRepo   repository.ReprocessJobRepository
+	logger    *log.LogGRPCImpl
+	uuid      goid.GoUUID
+	enqueuer  task.Enqueuer
+}
+
+// ReprocessMediaRequest selects the media to reprocess.
+// Exactly one of MediaID or Filters is set; Filters paging and sorting are ignored.
+type ReprocessMediaRequest struct {
+	MediaID     string
+	Filters     *repository.MediaFilters
+	RequestedBy string
+	IsAdmin     bool            // admins reprocess media of every owner
+	Priority    entity.Priority // Empty is bulk for filters, interactive for one media
+}
+
+// NewReprocessMediaUsecase creates a new reprocess media usecase
+func NewReprocessMediaUsecase(
+	mediaRepo repository.MediaRepository,
+	jobRepo repository.ReprocessJobRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	enqueuer task.Enqueuer,
+) *ReprocessMediaUsecase {
+	return &ReprocessMediaUsecase{
+		mediaRepo: mediaRepo,
+		jobRepo:   jobRepo,
+		logger:    logger,
+		uuid:      uuid,
+		enqueuer:  enqueuer,
+	}
+}
+
+// Execute creates a reprocess job and enqueues one task per matched media.
+// Variants are replaced by the workers, the current ones stay served meanwhile.
+func (uc *ReprocessMediaUsecase) Execute(ctx context.Context, req *ReprocessMediaRequest) (*entity.ReprocessJob, error) {
+	uc.logger.Info(fmt.Sprintf("Reprocessing media requested by: %s", req.RequestedBy))
+
+	// Step 1: Validate and normalize input
+	if err := uc.validateAndNormalizeInput(req); err != nil {
+		uc.logger.Error(fmt.Sprintf("Input validation failed: %v", err))
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Step 2: Collect the media to reprocess
+	mediaIDs, err := uc.collectMediaIDs(ctx, req)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to collect media: %v", err))
+		return nil, err
+	}
+
+	// Step 3: Create the job
+	job := uc.createJobEntity(req, len(mediaIDs))
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to save reprocess job: %v", err))
+		return nil, fmt.Errorf("database save failed: %w", err)
+	}
+
+	// Step 4: Enqueue one task per media, counting enqueue failures as failed media
+	for _, id := range mediaIDs {
+		_, err := uc.enqueuer.Enqueue(ctx, constants.JobTypeMediaReprocess, &task.ReprocessPayload{
+			JobID:   job.ID,
+			MediaID: id,
+			Owner:   req.RequestedBy,
+		}, task.Options{Queue: processingQueue(req.Priority)})
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to enqueue reprocessing of media %s: %v", id, err))
+			if err := uc.jobRepo.RecordResult(ctx, job.ID, fmt.Errorf("enqueue failed: %w", err)); err != nil {
+				uc.logger.Error(fmt.Sprintf("Failed to update reprocess job %s: %v", job.ID, err))
+			}
+		}
+	}
+
+	uc.logger.Info(fmt.Sprintf("Reprocess job %s queued with %d media", job.ID, job.Total))
+	return uc.jobRepo.GetByID(ctx, job.ID)
+}
+
+// Step 1: Validate and normalize input
+func (uc *ReprocessMediaUsecase) validateAndNormalizeInput(req *ReprocessMediaRequest) error {
+	if req.RequestedBy == "" {
+		return fmt.Errorf("requester is required")
+	}
+	if (req.MediaID == "") == (req.Filters == nil) {
+		return fmt.Errorf("exactly one of media ID or filters is required")
+	}
+	if req.Priority == "" && req.Filters != nil {
+		req.Priority = entity.PriorityBulk
+	}
+	priority, err := entity.ParsePriority(string(req.Priority))
+	if err != nil {
+		return err
+	}
+	req.Priority = priority
+	if req.Filters == nil {
+		return nil
+	}
+
+	if !req.IsAdmin {
+		req.Filters.CreatedBy = req.RequestedBy
+	}
+	if req.Filters.Color != "" {
+		if _, _, _, err := imaging.ParseHex(req.Filters.Color); err != nil {
+			return err
+		}
+		if req.Filters.ColorDist <= 0 {
+			req.Filters.ColorDist = constants.DefaultColorDistance
+		}
+		if req.Filters.ColorDist > constants.MaxColorDistance {
+			return fmt.Errorf("invalid color distance: %v (max %v)", req.Filters.ColorDist, constants.MaxColorDistance)
+		}
+	}
+	req.Filters.Limit = reprocessPageSize
+	req.Filters.Offset = 0
+	req.Filters.SortBy = "created_at"
+	req.Filters.SortOrder = "asc"
+	return nil
+}
+
+// Step 2: Collect the media to reprocess
+func (uc *ReprocessMediaUsecase) collectMediaIDs(ctx context.Context, req *ReprocessMediaRequest) ([]string, error) {
+	if req.MediaID != "" {
+		media, err := uc.mediaRepo.GetByID(ctx, req.MediaID)
+		if err != nil {
+			return nil, fmt.Errorf("database retrieval failed: %w", err)
+		}
+		if media == nil {
+			return nil, fmt.Errorf("media not found")
+		}
+		if !req.IsAdmin && media.CreatedBy != req.RequestedBy {
+			return nil, fmt.Errorf("unauthorized: media belongs to another user")
+		}
+		return []string{media.ID}, nil
+	}
+
+	var mediaIDs []string
+	filters := *req.Filters
+	for {
+		page, total, err := uc.mediaRepo.List(ctx, filters)
+		if err != nil {
+			return nil, fmt.Errorf("database retrieval failed: %w", err)
+		}
+		if total > constants.MaxReprocessMedia {
+			return nil, fmt.Errorf("validation failed: %d media match the filters (max %d)", total, constants.MaxReprocessMedia)
+		}
+		for _, media := range page {
+			mediaIDs = append(mediaIDs, media.ID)
+		}
+		if len(page) < filters.Limit {
+			return mediaIDs, nil
+		}
+		filters.Offset += filters.Limit
+	}
+}
+
+// Step 3: Create the job
+func (uc *ReprocessMediaUsecase) createJobEntity(req *ReprocessMediaRequest, total int) *entity.ReprocessJob {
+	job := &entity.ReprocessJob{
+		ID:          uc.uuid.Gen(),
+		RequestedBy: req.RequestedBy,
+		Status:      entity.ReprocessJobStatusQueued,
+		Total:       total,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if req.MediaID != "" {
+		job.Filters = map[string]string{"id": req.MediaID}
+	} else {
+		job.Filters = describeFilters(req.Filters)
+	}
+	if total == 0 {
+		now := time.Now()
+		job.Status = entity.ReprocessJobStatusCompleted
+		job.CompletedAt = &now
+	}
+	return job
+}
+
+func describeFilters(filters *repository.MediaFilters) map[string]string {
+	described := make(map[string]string)
+	if filters.CreatedBy != "" {
+		described["created_by"] = filters.CreatedBy
+	}
+	if filters.Type != "" {
+		described["type"] = string(filters.Type)
+	}
+	if filters.MimeType != "" {
+		described["mime_type"] = filters.MimeType
+	}
+	if filters.Color != "" {
+		described["color"] = filters.Color
+		described["color_distance"] = strconv.FormatFloat(filters.ColorDist, 'f', -1, 64)
+	}
+	if filters.Search != "" {
+		described["search"] = filters.Search
+	}
+	if filters.Misoriented {
+		described["misoriented"] = "true"
+	}
+	return described
+}
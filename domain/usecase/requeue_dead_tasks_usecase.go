@@ -0,0 +1,41 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/task"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// RequeueDeadTasksUsecase moves dead tasks back to their queue for another round of retries
+type RequeueDeadTasksUsecase struct {
+	inspector task.Inspector
+	logger    *log.LogGRPCImpl
+}
+
+// NewRequeueDeadTasksUsecase creates a new requeue dead tasks usecase
+func NewRequeueDeadTasksUsecase(
+	inspector task.Inspector,
+	logger *log.LogGRPCImpl,
+) *RequeueDeadTasksUsecase {
+	return &RequeueDeadTasksUsecase{
+		inspector: inspector,
+		logger:    logger,
+	}
+}
+
+// Execute requeues the dead tasks matching the filter, for admins only, and
+// returns how many were requeued
+func (uc *RequeueDeadTasksUsecase) Execute(ctx context.Context, filter *DeadTaskFilter, isAdmin bool) (int, error) {
+	if !isAdmin {
+		return 0, fmt.Errorf("unauthorized: admin only")
+	}
+	if filter.empty() {
+		return 0, fmt.Errorf("validation failed: a queue, task ID or filter is required")
+	}
+	if err := filter.validate(); err != nil {
+		return 0, fmt.Errorf("validation failed: %w", err)
+	}
+	return applyToDeadTasks(ctx, uc.inspector, uc.logger, filter, "requeue", uc.inspector.Requeue)
+}
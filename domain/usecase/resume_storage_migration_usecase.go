@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// ResumeStorageMigrationUsecase restarts a stopped storage migration
+type ResumeStorageMigrationUsecase struct {
+	mediaRepo     repository.MediaRepository
+	migrationRepo repository.StorageMigrationRepository
+	logger        *log.LogGRPCImpl
+	enqueuer      task.Enqueuer
+}
+
+// NewResumeStorageMigrationUsecase creates a new resume storage migration usecase
+func NewResumeStorageMigrationUsecase(
+	mediaRepo repository.MediaRepository,
+	migrationRepo repository.StorageMigrationRepository,
+	logger *log.LogGRPCImpl,
+	enqueuer task.Enqueuer,
+) *ResumeStorageMigrationUsecase {
+	return &ResumeStorageMigrationUsecase{
+		mediaRepo:     mediaRepo,
+		migrationRepo: migrationRepo,
+		logger:        logger,
+		enqueuer:      enqueuer,
+	}
+}
+
+// Execute resumes a failed migration, or a running one whose task was lost,
+// for admins only. Migrated media left the source, so the migration restarts
+// from the first media still there and retries the failed ones.
+func (uc *ResumeStorageMigrationUsecase) Execute(ctx context.Context, id string, isAdmin bool) (*entity.StorageMigration, error) {
+	// Step 1: Validate input
+	if !isAdmin {
+		return nil, fmt.Errorf("unauthorized: admin only")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("validation failed: migration ID is required")
+	}
+
+	// Step 2: Check the migration is stopped
+	migration, err := uc.migrationRepo.GetByID(ctx, id)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to retrieve storage migration: %v", err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if migration == nil {
+		return nil, fmt.Errorf("storage migration not found")
+	}
+	if err := uc.checkResumable(migration); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Step 3: Reset the position and the failures
+	remaining, err := uc.mediaRepo.CountMediaOnBackend(ctx, migration.Source)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to count media of storage backend %s: %v", migration.Source, err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	migration.Status = entity.StorageMigrationStatusQueued
+	migration.LastMediaID = ""
+	migration.Total = migration.Migrated + remaining
+	migration.Failed = 0
+	migration.LastError = ""
+	if err := uc.migrationRepo.Update(ctx, migration); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to update storage migration %s: %v", migration.ID, err))
+		return nil, fmt.Errorf("database save failed: %w", err)
+	}
+
+	// Step 4: Enqueue the next batch
+	if err := enqueueStorageMigration(ctx, uc.enqueuer, migration.ID); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to enqueue storage migration %s: %v", migration.ID, err))
+		return nil, fmt.Errorf("enqueue failed: %w", err)
+	}
+
+	uc.logger.Info(fmt.Sprintf("Storage migration %s resumed with %d media left", migration.ID, remaining))
+	return migration, nil
+}
+
+// Step 2: A queued or running migration may only be resumed once stale, so
+// two tasks never migrate the same media
+func (uc *ResumeStorageMigrationUsecase) checkResumable(migration *entity.StorageMigration) error {
+	switch migration.Status {
+	case entity.StorageMigrationStatusFailed:
+		return nil
+	case entity.StorageMigrationStatusCompleted:
+		return fmt.Errorf("storage migration %s is completed", migration.ID)
+	}
+	if time.Since(migration.UpdatedAt) < constants.StorageMigrationStalePeriod*time.Second {
+		return fmt.Errorf("storage migration %s is %s", migration.ID, migration.Status)
+	}
+	return nil
+}
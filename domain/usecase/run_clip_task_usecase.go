@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"media-service/domain/video"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// Extensions of the stored video formats, for keyframe clips that keep the source container
+var videoExts = map[entity.MimeType]string{
+	entity.MimeTypeVideo: entity.ExtMP4,
+	entity.MimeTypeMOV:   entity.ExtMOV,
+	entity.MimeTypeWebM:  entity.ExtWebM,
+}
+
+// RunClipTaskUsecase cuts the clip of a clip job and processes it as a new video media
+type RunClipTaskUsecase struct {
+	mediaRepo repository.MediaRepository
+	jobRepo   repository.ClipJobRepository
+	logger    *log.LogGRPCImpl
+	uuid      goid.GoUUID
+	backends  *blob.Registry
+	clipper   video.Clipper
+	processUC *ProcessUploadUsecase
+}
+
+// NewRunClipTaskUsecase creates a new run clip task usecase
+func NewRunClipTaskUsecase(
+	mediaRepo repository.MediaRepository,
+	jobRepo repository.ClipJobRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	backends *blob.Registry,
+	clipper video.Clipper,
+	processUC *ProcessUploadUsecase,
+) *RunClipTaskUsecase {
+	return &RunClipTaskUsecase{
+		mediaRepo: mediaRepo,
+		jobRepo:   jobRepo,
+		logger:    logger,
+		uuid:      uuid,
+		backends:  backends,
+		clipper:   clipper,
+		processUC: processUC,
+	}
+}
+
+// Execute handles a task.ClipPayload. Failures are recorded on the job rather
+// than returned, so a clip is not cut twice by retries.
+func (uc *RunClipTaskUsecase) Execute(ctx context.Context, payload []byte) error {
+	var p task.ClipPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid clip payload: %w: %w", err, task.ErrSkipRetry)
+	}
+
+	// Step 1: Load the job
+	job, err := uc.jobRepo.GetByID(ctx, p.JobID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve clip job %s: %w", p.JobID, err)
+	}
+	if job == nil || job.Finished() {
+		uc.logger.Warn(fmt.Sprintf("Skipping clip job %s: missing or already finished", p.JobID))
+		return nil
+	}
+
+	// Step 2: Mark it running
+	job.Status = entity.ClipJobStatusRunning
+	if err := uc.jobRepo.UpdateStatus(ctx, job); err != nil {
+		return fmt.Errorf("failed to update clip job %s: %w", job.ID, err)
+	}
+
+	// Step 3: Cut and process the clip
+	clip, failure := uc.clip(ctx, job)
+
+	// Step 4: Record the outcome
+	now := time.Now()
+	job.CompletedAt = &now
+	if failure != nil {
+		uc.logger.Warn(fmt.Sprintf("Clip job %s failed: %v", job.ID, failure))
+		job.Status = entity.ClipJobStatusFailed
+		job.Error = failure.Error()
+	} else {
+		uc.logger.Info(fmt.Sprintf("Clip job %s created media %s", job.ID, clip.ID))
+		job.Status = entity.ClipJobStatusCompleted
+		job.ClipMediaID = &clip.ID
+	}
+	if err := uc.jobRepo.UpdateStatus(ctx, job); err != nil {
+		return fmt.Errorf("failed to update clip job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+func (uc *RunClipTaskUsecase) clip(ctx context.Context, job *entity.ClipJob) (*entity.Media, error) {
+	source, err := uc.mediaRepo.GetByID(ctx, job.SourceMediaID)
+	if err != nil {
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if source == nil {
+		return nil, fmt.Errorf("media %s not found", job.SourceMediaID)
+	}
+
+	ext := entity.ExtMP4
+	if job.Mode == entity.ClipModeKeyframe {
+		ext = videoExts[entity.MimeType(source.MimeType)]
+		if ext == "" {
+			return nil, fmt.Errorf("unsupported format: %s", source.MimeType)
+		}
+	}
+
+	src, err := copyOriginal(ctx, uc.backends, source, "clip-source-*"+videoExts[entity.MimeType(source.MimeType)])
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(src)
+
+	dir, err := os.MkdirTemp("", "clip-*")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	dst := filepath.Join(dir, "clip"+ext)
+
+	err = uc.clipper.Clip(ctx, src, dst, video.ClipOptions{
+		Start:    job.Start,
+		End:      job.End,
+		Width:    job.Width,
+		Height:   job.Height,
+		Accurate: job.Mode == entity.ClipModeAccurate,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to cut clip: %w", err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clip: %w", err)
+	}
+
+	return uc.processUC.Execute(ctx, &PipelineUpload{
+		ID:        uc.uuid.Gen(),
+		FileName:  clipName(source.Name, ext),
+		CreatedBy: job.RequestedBy,
+		Metadata: map[string]string{
+			"clip_start": strconv.FormatFloat(job.Start, 'f', -1, 64),
+			"clip_end":   strconv.FormatFloat(job.End, 'f', -1, 64),
+		},
+		Size:     info.Size(),
+		SourceID: source.ID,
+		Priority: entity.PriorityInteractive,
+	}, dst)
+}
+
+// clipName derives the name of a clip from the name of its source video
+func clipName(name, ext string) string {
+	return strings.TrimSuffix(name, filepath.Ext(name)) + "-clip" + ext
+}
@@ -0,0 +1,187 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/entity"
+	"media-service/domain/progress"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// Stages reported while processing a media in the worker
+const (
+	stageVariants = "variants"
+	stageSprites  = "sprites"
+)
+
+// errMediaGone is returned for tasks of media deleted since they were enqueued
+var errMediaGone = errors.New("media not found")
+
+// RunMediaTaskUsecase processes one media in the worker: it regenerates its
+// variants, and the sprites of videos, retrying failed attempts with exponential backoff
+type RunMediaTaskUsecase struct {
+	mediaRepo repository.MediaRepository
+	jobRepo   repository.ProcessingJobRepository
+	logger    *log.LogGRPCImpl
+	uuid      goid.GoUUID
+	enqueuer  task.Enqueuer
+	tracker   *processingTracker
+	variants  *GenerateVariantsUsecase
+	sprites   *GenerateSpritesUsecase
+}
+
+// NewRunMediaTaskUsecase creates a new run media task usecase
+func NewRunMediaTaskUsecase(
+	mediaRepo repository.MediaRepository,
+	jobRepo repository.ProcessingJobRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	enqueuer task.Enqueuer,
+	publisher progress.Publisher,
+	variants *GenerateVariantsUsecase,
+	sprites *GenerateSpritesUsecase,
+) *RunMediaTaskUsecase {
+	return &RunMediaTaskUsecase{
+		mediaRepo: mediaRepo,
+		jobRepo:   jobRepo,
+		logger:    logger,
+		uuid:      uuid,
+		enqueuer:  enqueuer,
+		tracker:   newProcessingTracker(mediaRepo, publisher, logger),
+		variants:  variants,
+		sprites:   sprites,
+	}
+}
+
+// Execute handles a task.MediaPayload of the given task type. Failed attempts
+// are retried by enqueuing a delayed media_process task rather than by
+// returning the error; errors are only returned when the state cannot be saved.
+func (uc *RunMediaTaskUsecase) Execute(ctx context.Context, taskType string, payload []byte) error {
+	var p task.MediaPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid media payload: %w: %w", err, task.ErrSkipRetry)
+	}
+
+	// Step 1: Load the media
+	media, err := uc.mediaRepo.GetByID(ctx, p.MediaID)
+	if err != nil {
+		return fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if media == nil {
+		uc.logger.Warn(fmt.Sprintf("Skipping %s task: %v: %s", taskType, errMediaGone, p.MediaID))
+		return nil
+	}
+
+	// Step 2: Start the attempt
+	job, err := uc.startAttempt(ctx, media, taskType)
+	if errors.Is(err, entity.ErrInvalidTransition) {
+		uc.logger.Warn(fmt.Sprintf("Skipping %s task of media %s: %v", taskType, media.ID, err))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Step 3: Process
+	failure := uc.process(ctx, media, func(stage string, percent int) {
+		uc.tracker.progress(ctx, media.ID, stage, percent)
+	})
+
+	// Step 4: Record the outcome, scheduling a retry while attempts remain
+	job.Finish(failure)
+	if err := uc.jobRepo.Finish(ctx, job); err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to record processing attempt of media %s: %v", media.ID, err))
+	}
+	return uc.finishAttempt(ctx, media, failure)
+}
+
+// Regenerate replaces the renditions of a media from its original, leaving
+// its processing status untouched
+func (uc *RunMediaTaskUsecase) Regenerate(ctx context.Context, mediaID string) error {
+	media, err := uc.mediaRepo.GetByID(ctx, mediaID)
+	if err != nil {
+		return fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if media == nil {
+		return fmt.Errorf("%w: %s", errMediaGone, mediaID)
+	}
+	return uc.process(ctx, media, func(string, int) {})
+}
+
+// process renders the variants, then the sprites of videos, reporting the
+// stage it starts and its overall percentage
+func (uc *RunMediaTaskUsecase) process(ctx context.Context, media *entity.Media, report func(stage string, percent int)) error {
+	video := media.Type == entity.MediaTypeVideo
+	report(stageVariants, 0)
+	if _, err := uc.variants.Regenerate(ctx, media); err != nil {
+		return err
+	}
+	if video {
+		report(stageSprites, 50)
+		_, err := uc.sprites.Regenerate(ctx, media)
+		return err
+	}
+	return nil
+}
+
+// Step 2: Start the attempt; finished media are requested again, media
+// already processing are left to the running attempt
+func (uc *RunMediaTaskUsecase) startAttempt(ctx context.Context, media *entity.Media, taskType string) (*entity.ProcessingJob, error) {
+	if media.ProcessingStatus == entity.ProcessingStatusCompleted || media.ProcessingStatus == entity.ProcessingStatusFailed {
+		if err := uc.tracker.transition(ctx, media, entity.ProcessingStatusPending, ""); err != nil {
+			return nil, err
+		}
+	}
+	if err := uc.tracker.transition(ctx, media, entity.ProcessingStatusProcessing, ""); err != nil {
+		return nil, err
+	}
+
+	job := &entity.ProcessingJob{
+		ID:        uc.uuid.Gen(),
+		MediaID:   media.ID,
+		Attempt:   media.Attempts,
+		Trigger:   taskType,
+		Status:    entity.ProcessingJobStatusRunning,
+		StartedAt: time.Now(),
+	}
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to record processing attempt of media %s: %v", media.ID, err))
+	}
+	return job, nil
+}
+
+// Step 4: Record the outcome
+func (uc *RunMediaTaskUsecase) finishAttempt(ctx context.Context, media *entity.Media, failure error) error {
+	if failure == nil {
+		uc.logger.Info(fmt.Sprintf("Processed media %s in attempt %d", media.ID, media.Attempts))
+		return uc.tracker.transition(ctx, media, entity.ProcessingStatusCompleted, "")
+	}
+
+	if media.Attempts >= constants.MaxProcessingAttempts {
+		uc.logger.Error(fmt.Sprintf("Processing of media %s failed after %d attempts: %v", media.ID, media.Attempts, failure))
+		return uc.tracker.transition(ctx, media, entity.ProcessingStatusFailed, failure.Error())
+	}
+
+	delay := retryDelay(media.Attempts)
+	uc.logger.Warn(fmt.Sprintf("Processing attempt %d of media %s failed, retrying in %v: %v", media.Attempts, media.ID, delay, failure))
+	if err := uc.tracker.transition(ctx, media, entity.ProcessingStatusPending, failure.Error()); err != nil {
+		return err
+	}
+	_, err := uc.enqueuer.Enqueue(ctx, constants.JobTypeMediaProcess, &task.MediaPayload{
+		MediaID: media.ID,
+		Owner:   media.CreatedBy,
+	}, task.Options{Queue: processingQueue(media.Priority), Delay: delay})
+	if err != nil {
+		// The media stays pending until the stuck media reaper requeues it
+		uc.logger.Error(fmt.Sprintf("Failed to enqueue retry of media %s: %v", media.ID, err))
+	}
+	return nil
+}
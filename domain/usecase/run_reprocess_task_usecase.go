@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// RunReprocessTaskUsecase regenerates the variants, and the sprites of videos,
+// of one media of a reprocess job
+type RunReprocessTaskUsecase struct {
+	jobRepo   repository.ReprocessJobRepository
+	logger    *log.LogGRPCImpl
+	mediaTask *RunMediaTaskUsecase
+}
+
+// NewRunReprocessTaskUsecase creates a new run reprocess task usecase
+func NewRunReprocessTaskUsecase(
+	jobRepo repository.ReprocessJobRepository,
+	logger *log.LogGRPCImpl,
+	mediaTask *RunMediaTaskUsecase,
+) *RunReprocessTaskUsecase {
+	return &RunReprocessTaskUsecase{
+		jobRepo:   jobRepo,
+		logger:    logger,
+		mediaTask: mediaTask,
+	}
+}
+
+// Execute handles a task.ReprocessPayload. Failures are recorded on the job
+// rather than returned, so a media is counted exactly once.
+func (uc *RunReprocessTaskUsecase) Execute(ctx context.Context, payload []byte) error {
+	var p task.ReprocessPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid reprocess payload: %w: %w", err, task.ErrSkipRetry)
+	}
+
+	failure := uc.mediaTask.Regenerate(ctx, p.MediaID)
+	if failure != nil {
+		uc.logger.Warn(fmt.Sprintf("Reprocessing of media %s failed: %v", p.MediaID, failure))
+	}
+	if err := uc.jobRepo.RecordResult(ctx, p.JobID, failure); err != nil {
+		return fmt.Errorf("failed to update reprocess job %s: %w", p.JobID, err)
+	}
+	return nil
+}
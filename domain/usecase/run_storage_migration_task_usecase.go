@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// RunStorageMigrationTaskUsecase migrates a batch of media of a storage
+// migration, then enqueues the next batch
+type RunStorageMigrationTaskUsecase struct {
+	mediaRepo     repository.MediaRepository
+	migrationRepo repository.StorageMigrationRepository
+	logger        *log.LogGRPCImpl
+	backends      *blob.Registry
+	enqueuer      task.Enqueuer
+	mover         *mediaFileMover
+}
+
+// NewRunStorageMigrationTaskUsecase creates a new run storage migration task usecase
+func NewRunStorageMigrationTaskUsecase(
+	mediaRepo repository.MediaRepository,
+	migrationRepo repository.StorageMigrationRepository,
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+	enqueuer task.Enqueuer,
+) *RunStorageMigrationTaskUsecase {
+	return &RunStorageMigrationTaskUsecase{
+		mediaRepo:     mediaRepo,
+		migrationRepo: migrationRepo,
+		logger:        logger,
+		backends:      backends,
+		enqueuer:      enqueuer,
+		mover:         newMediaFileMover(mediaRepo, logger, backends),
+	}
+}
+
+// Execute handles a task.StorageMigrationPayload. A media whose files could
+// not be migrated is counted as failed and stays on the source; database
+// errors fail the task, which is retried from the last migrated media.
+func (uc *RunStorageMigrationTaskUsecase) Execute(ctx context.Context, payload []byte) error {
+	var p task.StorageMigrationPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid storage migration payload: %w: %w", err, task.ErrSkipRetry)
+	}
+
+	// Step 1: Load the migration
+	migration, err := uc.migrationRepo.GetByID(ctx, p.MigrationID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve storage migration %s: %w", p.MigrationID, err)
+	}
+	if migration == nil {
+		uc.logger.Warn(fmt.Sprintf("Storage migration %s not found", p.MigrationID))
+		return nil
+	}
+	if migration.Status == entity.StorageMigrationStatusCompleted || migration.Status == entity.StorageMigrationStatusFailed {
+		return nil
+	}
+	source, target, err := uc.resolveBackends(migration)
+	if err != nil {
+		return uc.fail(ctx, migration, err)
+	}
+	migration.Status = entity.StorageMigrationStatusRunning
+
+	// Step 2: Select the next batch
+	mediaIDs, err := uc.mediaRepo.GetMediaIDsOnBackend(ctx, migration.Source, migration.LastMediaID, constants.StorageMigrationBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to list media of storage backend %s: %w", migration.Source, err)
+	}
+
+	// Step 3: Migrate the media one by one, saving the position after each
+	for _, mediaID := range mediaIDs {
+		if err := uc.migrateMedia(ctx, migration, source, target, mediaID); err != nil {
+			uc.logger.Warn(fmt.Sprintf("Failed to migrate media %s to storage backend %s: %v", mediaID, target.Name, err))
+			migration.Failed++
+			migration.LastError = fmt.Sprintf("media %s: %v", mediaID, err)
+		} else {
+			migration.Migrated++
+		}
+		migration.LastMediaID = mediaID
+		if err := uc.migrationRepo.Update(ctx, migration); err != nil {
+			return fmt.Errorf("failed to update storage migration %s: %w", migration.ID, err)
+		}
+	}
+
+	// Step 4: Complete the migration or enqueue the next batch
+	if len(mediaIDs) < constants.StorageMigrationBatchSize {
+		now := time.Now()
+		migration.Status = entity.StorageMigrationStatusCompleted
+		migration.CompletedAt = &now
+		if err := uc.migrationRepo.Update(ctx, migration); err != nil {
+			return fmt.Errorf("failed to update storage migration %s: %w", migration.ID, err)
+		}
+		uc.logger.Info(fmt.Sprintf("Storage migration %s completed: %d media migrated, %d failed", migration.ID, migration.Migrated, migration.Failed))
+		return nil
+	}
+	if err := enqueueStorageMigration(ctx, uc.enqueuer, migration.ID); err != nil {
+		return uc.fail(ctx, migration, fmt.Errorf("enqueue failed: %w", err))
+	}
+	return nil
+}
+
+func (uc *RunStorageMigrationTaskUsecase) resolveBackends(migration *entity.StorageMigration) (*blob.Backend, *blob.Backend, error) {
+	source, err := uc.backends.Get(migration.Source)
+	if err != nil {
+		return nil, nil, err
+	}
+	target, err := uc.backends.Get(migration.Target)
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, target, nil
+}
+
+// fail stops the migration until it is resumed
+func (uc *RunStorageMigrationTaskUsecase) fail(ctx context.Context, migration *entity.StorageMigration, cause error) error {
+	uc.logger.Error(fmt.Sprintf("Storage migration %s failed: %v", migration.ID, cause))
+	migration.Status = entity.StorageMigrationStatusFailed
+	migration.LastError = cause.Error()
+	if err := uc.migrationRepo.Update(ctx, migration); err != nil {
+		return fmt.Errorf("failed to update storage migration %s: %w", migration.ID, err)
+	}
+	return nil
+}
+
+// Step 3: Move the files of the media stored in the source
+func (uc *RunStorageMigrationTaskUsecase) migrateMedia(ctx context.Context, migration *entity.StorageMigration, source, target *blob.Backend, mediaID string) error {
+	_, err := uc.mover.move(ctx, mediaID, source.Name, target, migration.DeleteSource)
+	return err
+}
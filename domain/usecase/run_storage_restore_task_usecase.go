@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// RunStorageRestoreTaskUsecase moves the files of a restoring media back to
+// the default storage backend
+type RunStorageRestoreTaskUsecase struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+	backends  *blob.Registry
+	mover     *mediaFileMover
+}
+
+// NewRunStorageRestoreTaskUsecase creates a new run storage restore task usecase
+func NewRunStorageRestoreTaskUsecase(
+	mediaRepo repository.MediaRepository,
+	logger *log.LogGRPCImpl,
+	backends *blob.Registry,
+) *RunStorageRestoreTaskUsecase {
+	return &RunStorageRestoreTaskUsecase{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+		backends:  backends,
+		mover:     newMediaFileMover(mediaRepo, logger, backends),
+	}
+}
+
+// Execute handles a task.StorageRestorePayload; a failed move fails the task,
+// which is retried, and the media stays restoring until a later delivery
+// starts a new restore once the current one is stale
+func (uc *RunStorageRestoreTaskUsecase) Execute(ctx context.Context, payload []byte) error {
+	var p task.StorageRestorePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid storage restore payload: %w: %w", err, task.ErrSkipRetry)
+	}
+
+	// Step 1: Load the media
+	media, err := uc.mediaRepo.GetByID(ctx, p.MediaID)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve media %s: %w", p.MediaID, err)
+	}
+	if media == nil {
+		uc.logger.Warn(fmt.Sprintf("Restored media %s not found", p.MediaID))
+		return nil
+	}
+	if media.TierStatus != entity.TierStatusRestoring {
+		return nil
+	}
+
+	// Step 2: Move its files and end the restore
+	target := uc.backends.Default()
+	moved, err := uc.mover.move(ctx, media.ID, "", target, true)
+	if err != nil {
+		return fmt.Errorf("failed to restore media %s: %w", media.ID, err)
+	}
+	if err := uc.mediaRepo.UpdateStorageTier(ctx, media.ID, target.Tier); err != nil {
+		return fmt.Errorf("failed to update media %s: %w", media.ID, err)
+	}
+	// The delivery that asked for the restore counts as an access, or the next
+	// sweep could move the media out again before it is served
+	now := time.Now()
+	if err := uc.mediaRepo.TouchAccess(ctx, media.ID, now, now); err != nil {
+		uc.logger.Warn(fmt.Sprintf("Failed to record access to media %s: %v", media.ID, err))
+	}
+
+	uc.logger.Info(fmt.Sprintf("Media %s restored to storage backend %s, %d files moved", media.ID, target.Name, moved))
+	return nil
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// RunVariantsTaskUsecase regenerates the crop-based variants of a media whose
+// focal point moved
+type RunVariantsTaskUsecase struct {
+	mediaRepo repository.MediaRepository
+	logger    *log.LogGRPCImpl
+	variants  *GenerateVariantsUsecase
+}
+
+// NewRunVariantsTaskUsecase creates a new run variants task usecase
+func NewRunVariantsTaskUsecase(
+	mediaRepo repository.MediaRepository,
+	logger *log.LogGRPCImpl,
+	variants *GenerateVariantsUsecase,
+) *RunVariantsTaskUsecase {
+	return &RunVariantsTaskUsecase{
+		mediaRepo: mediaRepo,
+		logger:    logger,
+		variants:  variants,
+	}
+}
+
+// Execute handles a task.MediaPayload. The variants are rendered around the
+// focal point the media has when the task runs, so a task queued before a
+// later move still renders the latest one; a failure fails the task, which
+// is retried.
+func (uc *RunVariantsTaskUsecase) Execute(ctx context.Context, payload []byte) error {
+	var p task.MediaPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return fmt.Errorf("invalid media payload: %w: %w", err, task.ErrSkipRetry)
+	}
+
+	// Step 1: Load the media
+	media, err := uc.mediaRepo.GetByID(ctx, p.MediaID)
+	if err != nil {
+		return fmt.Errorf("database retrieval failed: %w", err)
+	}
+	if media == nil {
+		uc.logger.Warn(fmt.Sprintf("Skipping variants task: %v: %s", errMediaGone, p.MediaID))
+		return nil
+	}
+
+	// Step 2: Regenerate the variants from the stored original
+	variants, err := uc.variants.Regenerate(ctx, media)
+	if err != nil {
+		return fmt.Errorf("failed to regenerate variants of media %s: %w", media.ID, err)
+	}
+
+	uc.logger.Info(fmt.Sprintf("Regenerated %d variants of media %s", len(variants), media.ID))
+	return nil
+}
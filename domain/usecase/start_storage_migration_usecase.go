@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/blob"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+	"media-service/domain/task"
+	"strings"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/goid"
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// StartStorageMigrationUsecase starts copying the files of every media stored
+// in one backend to another
+type StartStorageMigrationUsecase struct {
+	mediaRepo     repository.MediaRepository
+	migrationRepo repository.StorageMigrationRepository
+	logger        *log.LogGRPCImpl
+	uuid          goid.GoUUID
+	backends      *blob.Registry
+	enqueuer      task.Enqueuer
+}
+
+type StartStorageMigrationRequest struct {
+	Source       string
+	Target       string
+	DeleteSource bool // Delete the files of the source once their rows point at the copies
+	RequestedBy  string
+	IsAdmin      bool
+}
+
+// NewStartStorageMigrationUsecase creates a new start storage migration usecase
+func NewStartStorageMigrationUsecase(
+	mediaRepo repository.MediaRepository,
+	migrationRepo repository.StorageMigrationRepository,
+	logger *log.LogGRPCImpl,
+	uuid goid.GoUUID,
+	backends *blob.Registry,
+	enqueuer task.Enqueuer,
+) *StartStorageMigrationUsecase {
+	return &StartStorageMigrationUsecase{
+		mediaRepo:     mediaRepo,
+		migrationRepo: migrationRepo,
+		logger:        logger,
+		uuid:          uuid,
+		backends:      backends,
+		enqueuer:      enqueuer,
+	}
+}
+
+// Execute creates the migration and enqueues its first batch, for admins only
+func (uc *StartStorageMigrationUsecase) Execute(ctx context.Context, req *StartStorageMigrationRequest) (*entity.StorageMigration, error) {
+	// Step 1: Validate input
+	if !req.IsAdmin {
+		return nil, fmt.Errorf("unauthorized: admin only")
+	}
+	if err := uc.validateInput(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	// Step 2: Count the media to migrate
+	total, err := uc.mediaRepo.CountMediaOnBackend(ctx, req.Source)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to count media of storage backend %s: %v", req.Source, err))
+		return nil, fmt.Errorf("database retrieval failed: %w", err)
+	}
+
+	// Step 3: Create the migration
+	migration := uc.createMigrationEntity(req, total)
+	if err := uc.migrationRepo.Create(ctx, migration); err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to save storage migration: %v", err))
+		return nil, fmt.Errorf("database save failed: %w", err)
+	}
+
+	// Step 4: Enqueue the first batch
+	if migration.Status != entity.StorageMigrationStatusCompleted {
+		if err := enqueueStorageMigration(ctx, uc.enqueuer, migration.ID); err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to enqueue storage migration %s: %v", migration.ID, err))
+			migration.Status = entity.StorageMigrationStatusFailed
+			migration.LastError = err.Error()
+			if err := uc.migrationRepo.Update(ctx, migration); err != nil {
+				uc.logger.Error(fmt.Sprintf("Failed to update storage migration %s: %v", migration.ID, err))
+			}
+			return nil, fmt.Errorf("enqueue failed: %w", err)
+		}
+	}
+
+	uc.logger.Info(fmt.Sprintf("Storage migration %s from %s to %s queued with %d media", migration.ID, req.Source, req.Target, total))
+	return migration, nil
+}
+
+// Step 1: Validate input
+func (uc *StartStorageMigrationUsecase) validateInput(req *StartStorageMigrationRequest) error {
+	// Backend names are configured as map keys, which are read lowercased
+	req.Source, req.Target = strings.ToLower(req.Source), strings.ToLower(req.Target)
+	if req.Source == "" || req.Target == "" {
+		return fmt.Errorf("source and target backends are required")
+	}
+	if req.Source == req.Target {
+		return fmt.Errorf("source and target backends must differ")
+	}
+	// The source may be a backend no longer written to, but it must still be readable
+	for _, name := range []string{req.Source, req.Target} {
+		if _, err := uc.backends.Get(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Step 3: Create the migration
+func (uc *StartStorageMigrationUsecase) createMigrationEntity(req *StartStorageMigrationRequest, total int) *entity.StorageMigration {
+	migration := &entity.StorageMigration{
+		ID:           uc.uuid.Gen(),
+		RequestedBy:  req.RequestedBy,
+		Source:       req.Source,
+		Target:       req.Target,
+		DeleteSource: req.DeleteSource,
+		Status:       entity.StorageMigrationStatusQueued,
+		Total:        total,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+	if total == 0 {
+		now := time.Now()
+		migration.Status = entity.StorageMigrationStatusCompleted
+		migration.CompletedAt = &now
+	}
+	return migration
+}
+
+// enqueueStorageMigration enqueues the next batch of a migration; storage
+// migrations share the cleanup queue, away from the processing workers
+func enqueueStorageMigration(ctx context.Context, enqueuer task.Enqueuer, id string) error {
+	_, err := enqueuer.Enqueue(ctx, constants.JobTypeStorageMigrate, &task.StorageMigrationPayload{
+		MigrationID: id,
+	}, task.Options{Queue: constants.QueueMediaCleanup})
+	return err
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// SyncWatermarksUsecase regenerates variants rendered with an outdated watermark profile
+type SyncWatermarksUsecase struct {
+	mediaRepo   repository.MediaRepository
+	variantRepo repository.MediaVariantRepository
+	logger      *log.LogGRPCImpl
+	watermark   *ApplyWatermarkUsecase
+	variants    *GenerateVariantsUsecase
+}
+
+// NewSyncWatermarksUsecase creates a new sync watermarks usecase
+func NewSyncWatermarksUsecase(
+	mediaRepo repository.MediaRepository,
+	variantRepo repository.MediaVariantRepository,
+	logger *log.LogGRPCImpl,
+	watermark *ApplyWatermarkUsecase,
+	variants *GenerateVariantsUsecase,
+) *SyncWatermarksUsecase {
+	return &SyncWatermarksUsecase{
+		mediaRepo:   mediaRepo,
+		variantRepo: variantRepo,
+		logger:      logger,
+		watermark:   watermark,
+		variants:    variants,
+	}
+}
+
+// Execute finds variants whose watermark key differs from the current profile
+// (added, changed or removed) and regenerates them. It returns the number of media regenerated.
+func (uc *SyncWatermarksUsecase) Execute(ctx context.Context) (int, error) {
+	uc.logger.Info("Syncing watermarked variants")
+
+	// Step 1: Collect media with stale variants
+	stale := make(map[string]bool)
+	for _, name := range VariantNames() {
+		mediaIDs, err := uc.variantRepo.GetMediaIDsByStaleWatermark(ctx, name, uc.watermark.ProfileKey(name))
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to find stale %s variants: %v", name, err))
+			return 0, fmt.Errorf("database retrieval failed: %w", err)
+		}
+		for _, id := range mediaIDs {
+			stale[id] = true
+		}
+	}
+
+	// Step 2: Regenerate their variants
+	regenerated := 0
+	for id := range stale {
+		if err := ctx.Err(); err != nil {
+			return regenerated, err
+		}
+		media, err := uc.mediaRepo.GetByID(ctx, id)
+		if err != nil || media == nil {
+			uc.logger.Warn(fmt.Sprintf("Could not load media %s: %v", id, err))
+			continue
+		}
+		if _, err := uc.variants.Regenerate(ctx, media); err != nil {
+			uc.logger.Warn(fmt.Sprintf("Could not regenerate variants of media %s: %v", id, err))
+			continue
+		}
+		regenerated++
+	}
+
+	uc.logger.Info(fmt.Sprintf("Watermark sync completed: %d/%d media regenerated", regenerated, len(stale)))
+	return regenerated, nil
+}
@@ -15,12 +15,15 @@ type UploadMediaRequest struct {
 	CreatedBy string
 	Metadata  map[string]string
 	Ext       string
+	Priority  entity.Priority // Empty is interactive
 }
 
 // UpdateMediaRequest represents an update request
 type UpdateMediaRequest struct {
-	Name     *string
-	Metadata map[string]string
+	Name            *string
+	Metadata        map[string]string
+	FocalPoint      *entity.FocalPoint
+	ClearFocalPoint bool // Reset to automatic (smart) cropping
 }
 
 type MediaMetadata struct {
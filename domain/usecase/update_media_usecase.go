@@ -3,8 +3,10 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"media-service/constants"
 	"media-service/domain/entity"
 	"media-service/domain/repository"
+	"media-service/domain/task"
 	"time"
 
 	"github.com/anhvanhoa/service-core/domain/log"
@@ -14,16 +16,19 @@ import (
 type UpdateMediaUsecase struct {
 	mediaRepo repository.MediaRepository
 	logger    *log.LogGRPCImpl
+	enqueuer  task.Enqueuer
 }
 
 // NewUpdateMediaUsecase creates a new update media usecase
 func NewUpdateMediaUsecase(
 	mediaRepo repository.MediaRepository,
 	logger *log.LogGRPCImpl,
+	enqueuer task.Enqueuer,
 ) *UpdateMediaUsecase {
 	return &UpdateMediaUsecase{
 		mediaRepo: mediaRepo,
 		logger:    logger,
+		enqueuer:  enqueuer,
 	}
 }
 
@@ -59,7 +64,15 @@ func (uc *UpdateMediaUsecase) Execute(ctx context.Context, mediaID, createdBy st
 		return nil, fmt.Errorf("failed to save changes: %w", err)
 	}
 
-	// Step 6: Log successful update
+	// Step 6: Queue the regeneration of crop-based variants when the focal point moved
+	if !sameFocalPoint(existingMedia.FocalPoint(), updatedMedia.FocalPoint()) {
+		if err := uc.enqueueVariants(ctx, updatedMedia); err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to enqueue regeneration of variants: %v", err))
+			return nil, fmt.Errorf("media updated, but regenerating its variants could not be queued: %w", err)
+		}
+	}
+
+	// Step 7: Log successful update
 	uc.logger.Info(fmt.Sprintf("Media updated successfully: %s", mediaID))
 
 	return updatedMedia, nil
@@ -84,6 +97,16 @@ func (uc *UpdateMediaUsecase) validateInput(mediaID, createdBy string, req *Upda
 		return fmt.Errorf("name cannot be empty")
 	}
 
+	// Validate focal point
+	if req.FocalPoint != nil {
+		if req.ClearFocalPoint {
+			return fmt.Errorf("focal point cannot be set and cleared at once")
+		}
+		if req.FocalPoint.X < 0 || req.FocalPoint.X > 1 || req.FocalPoint.Y < 0 || req.FocalPoint.Y > 1 {
+			return fmt.Errorf("focal point must be within 0..1")
+		}
+	}
+
 	// Validate metadata keys
 	if req.Metadata != nil {
 		for key := range req.Metadata {
@@ -142,6 +165,17 @@ func (uc *UpdateMediaUsecase) updateMediaFields(media *entity.Media, req *Update
 		}
 	}
 
+	// Update focal point if provided
+	if req.FocalPoint != nil {
+		x, y := req.FocalPoint.X, req.FocalPoint.Y
+		updatedMedia.FocalX = &x
+		updatedMedia.FocalY = &y
+	}
+	if req.ClearFocalPoint {
+		updatedMedia.FocalX = nil
+		updatedMedia.FocalY = nil
+	}
+
 	// Update timestamp
 	updatedMedia.UpdatedAt = time.Now()
 
@@ -152,3 +186,20 @@ func (uc *UpdateMediaUsecase) updateMediaFields(media *entity.Media, req *Update
 func (uc *UpdateMediaUsecase) saveToDatabase(ctx context.Context, media *entity.Media) error {
 	return uc.mediaRepo.Update(ctx, media)
 }
+
+// Step 6: Queue the regeneration of crop-based variants; the current ones
+// stay served until the worker replaces them
+func (uc *UpdateMediaUsecase) enqueueVariants(ctx context.Context, media *entity.Media) error {
+	_, err := uc.enqueuer.Enqueue(ctx, constants.JobTypeMediaVariants, &task.MediaPayload{
+		MediaID: media.ID,
+		Owner:   media.CreatedBy,
+	}, task.Options{Queue: processingQueue(media.Priority)})
+	return err
+}
+
+func sameFocalPoint(a, b *entity.FocalPoint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/entity"
+	"media-service/domain/progress"
+	"media-service/domain/repository"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// WatchProcessingRequest represents the media followed by a watcher
+type WatchProcessingRequest struct {
+	MediaIDs    []string
+	RequestedBy string
+	IsAdmin     bool
+}
+
+// WatchProcessingUsecase streams the processing progress of media
+type WatchProcessingUsecase struct {
+	mediaRepo  repository.MediaRepository
+	subscriber progress.Subscriber
+	logger     *log.LogGRPCImpl
+}
+
+// NewWatchProcessingUsecase creates a new watch processing usecase
+func NewWatchProcessingUsecase(
+	mediaRepo repository.MediaRepository,
+	subscriber progress.Subscriber,
+	logger *log.LogGRPCImpl,
+) *WatchProcessingUsecase {
+	return &WatchProcessingUsecase{
+		mediaRepo:  mediaRepo,
+		subscriber: subscriber,
+		logger:     logger,
+	}
+}
+
+// Execute sends the current status of each media, then its progress events,
+// until every media completed or failed or ctx is done
+func (uc *WatchProcessingUsecase) Execute(ctx context.Context, req *WatchProcessingRequest, send func(*progress.Event) error) error {
+	// Step 1: Validate request
+	ids, err := uc.validateRequest(req)
+	if err != nil {
+		return err
+	}
+
+	// Step 2: Subscribe before reading the media so no change is missed in between
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events, err := uc.subscriber.Subscribe(ctx, ids)
+	if err != nil {
+		uc.logger.Error(fmt.Sprintf("Failed to subscribe to processing progress: %v", err))
+		return fmt.Errorf("progress subscription failed: %w", err)
+	}
+
+	// Step 3: Check access and send the current status of each media
+	pending := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		media, err := uc.mediaRepo.GetByID(ctx, id)
+		if err != nil {
+			uc.logger.Error(fmt.Sprintf("Failed to retrieve media %s: %v", id, err))
+			return fmt.Errorf("database retrieval failed: %w", err)
+		}
+		if media == nil {
+			return fmt.Errorf("media %s not found", id)
+		}
+		if !req.IsAdmin && media.CreatedBy != req.RequestedBy {
+			return fmt.Errorf("unauthorized: media %s belongs to another user", id)
+		}
+
+		snapshot := snapshotEvent(media)
+		if err := send(snapshot); err != nil {
+			return err
+		}
+		if !snapshot.Final() {
+			pending[id] = true
+		}
+	}
+
+	// Step 4: Forward events until no media is left processing
+	for len(pending) > 0 {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if !pending[event.MediaID] {
+				continue
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+			if event.Final() {
+				delete(pending, event.MediaID)
+			}
+		}
+	}
+	return nil
+}
+
+func (uc *WatchProcessingUsecase) validateRequest(req *WatchProcessingRequest) ([]string, error) {
+	if len(req.MediaIDs) == 0 {
+		return nil, fmt.Errorf("validation failed: at least one media ID is required")
+	}
+
+	seen := make(map[string]bool, len(req.MediaIDs))
+	ids := make([]string, 0, len(req.MediaIDs))
+	for _, id := range req.MediaIDs {
+		if id == "" {
+			return nil, fmt.Errorf("validation failed: media ID is required")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) > constants.MaxWatchedMedia {
+		return nil, fmt.Errorf("validation failed: at most %d media can be watched", constants.MaxWatchedMedia)
+	}
+	return ids, nil
+}
+
+// snapshotEvent reports the status saved on the media
+func snapshotEvent(media *entity.Media) *progress.Event {
+	event := &progress.Event{
+		MediaID: media.ID,
+		Status:  media.ProcessingStatus,
+		Reason:  media.LastError,
+		At:      media.UpdatedAt,
+	}
+	if media.ProcessingStatus == entity.ProcessingStatusCompleted {
+		event.Percent = 100
+	}
+	return event
+}
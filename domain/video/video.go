@@ -0,0 +1,96 @@
+package video
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"io"
+)
+
+// sniffLength covers the ftyp box of MP4/QuickTime files and the EBML header of WebM
+const sniffLength = 64
+
+// ebmlMagic starts Matroska and WebM files
+var ebmlMagic = []byte{0x1a, 0x45, 0xdf, 0xa3}
+
+// ErrUnsupported is returned for containers or codecs that cannot be stored
+var ErrUnsupported = errors.New("unsupported format")
+
+// Brands of still image ISOBMFF files, which are not videos
+var imageBrands = map[string]bool{
+	"heic": true,
+	"heix": true,
+	"heim": true,
+	"heis": true,
+	"mif1": true,
+	"avif": true,
+	"avis": true,
+	"crx ": true,
+}
+
+// Info is the metadata of a video file
+type Info struct {
+	Duration float64 // Seconds
+	Width    int     // Display size, rotation applied
+	Height   int
+	Codec    string
+	MimeType string
+	Ext      string
+}
+
+// Prober validates video files and reads their metadata
+type Prober interface {
+	Probe(ctx context.Context, path string) (*Info, error)
+}
+
+// FrameExtractor decodes frames of a video file
+type FrameExtractor interface {
+	// ExtractFrame decodes the frame at the given second
+	ExtractFrame(ctx context.Context, path string, at float64) (image.Image, error)
+	// ExtractFrames decodes one frame every interval seconds, scaled to width x height
+	ExtractFrames(ctx context.Context, path string, interval float64, width, height int) ([]image.Image, error)
+}
+
+// ClipOptions selects the part of a video to cut
+type ClipOptions struct {
+	Start    float64 // Seconds
+	End      float64
+	Width    int // Output size, 0 keeps the source size; a single 0 keeps the aspect ratio
+	Height   int
+	Accurate bool // Re-encode to cut on the exact frames instead of copying from the preceding keyframe
+}
+
+// Clipper cuts a part of a video file into a new file
+type Clipper interface {
+	// Clip writes the clip to dst; accurate clips are encoded as H.264 MP4,
+	// keyframe clips keep the source container and codecs
+	Clip(ctx context.Context, src, dst string, opts ClipOptions) error
+}
+
+// IsVideo sniffs the file for an MP4, QuickTime or Matroska container and rewinds it
+func IsVideo(r io.ReadSeeker) (bool, error) {
+	header := make([]byte, sniffLength)
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	header = header[:n]
+
+	if bytes.HasPrefix(header, ebmlMagic) {
+		return true, nil
+	}
+	if len(header) < 12 {
+		return false, nil
+	}
+	switch string(header[4:8]) {
+	case "ftyp":
+		return !imageBrands[string(header[8:12])], nil
+	case "moov", "mdat", "wide", "free":
+		return true, nil // QuickTime files without ftyp
+	}
+	return false, nil
+}
@@ -0,0 +1,41 @@
+package video
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// ThumbnailCue maps a time range of a video to a tile of a sprite sheet
+type ThumbnailCue struct {
+	Start  float64 // Seconds
+	End    float64
+	URL    string // Sprite sheet, absolute or relative to the track
+	X      int
+	Y      int
+	Width  int
+	Height int
+}
+
+// WriteThumbnailTrack writes a WebVTT thumbnail track, the cue payloads
+// pointing at the tiles with media fragments (#xywh=)
+func WriteThumbnailTrack(w io.Writer, cues []ThumbnailCue) error {
+	if _, err := io.WriteString(w, "WEBVTT\n"); err != nil {
+		return err
+	}
+	for _, cue := range cues {
+		_, err := fmt.Fprintf(w, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			Timestamp(cue.Start), Timestamp(cue.End),
+			cue.URL, cue.X, cue.Y, cue.Width, cue.Height)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Timestamp formats seconds as a WebVTT timestamp, hh:mm:ss.ttt
+func Timestamp(seconds float64) string {
+	ms := int64(math.Round(math.Max(0, seconds) * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
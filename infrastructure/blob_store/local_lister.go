@@ -0,0 +1,57 @@
+package blob_store
+
+import (
+	"context"
+	"io/fs"
+	"media-service/domain/blob"
+	"path/filepath"
+)
+
+type localLister struct {
+	uploadDir string
+}
+
+// NewLocalLister creates a blob lister walking the upload dir of the local storage service
+func NewLocalLister(uploadDir string) blob.Lister {
+	return &localLister{uploadDir: filepath.Clean(uploadDir)}
+}
+
+func (l *localLister) List(ctx context.Context, fn func(*blob.Object) error) error {
+	return filepath.WalkDir(l.uploadDir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(l.uploadDir, path)
+		if err != nil {
+			return err
+		}
+		return fn(&blob.Object{
+			Key:     filepath.ToSlash(rel),
+			URL:     filepath.ToSlash(path),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	})
+}
+
+func (l *localLister) Key(url string) (string, error) {
+	path, err := resolveLocal(l.uploadDir, url)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(l.uploadDir, path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,78 @@
+package blob_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"media-service/domain/blob"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type localReader struct {
+	uploadDir string
+}
+
+// NewLocalReader creates a blob reader for files stored by the local storage service
+func NewLocalReader(uploadDir string) blob.Reader {
+	return &localReader{uploadDir: filepath.Clean(uploadDir)}
+}
+
+func (r *localReader) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	path, err := resolveLocal(r.uploadDir, url)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(path)
+}
+
+// NewLocalFileOpener creates a blob file opener for files stored by the local storage service
+func NewLocalFileOpener(uploadDir string) blob.FileOpener {
+	return &localReader{uploadDir: filepath.Clean(uploadDir)}
+}
+
+func (r *localReader) OpenFile(ctx context.Context, url string) (io.ReadSeekCloser, *blob.Object, error) {
+	path, err := resolveLocal(r.uploadDir, url)
+	if err != nil {
+		return nil, nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("%w: %s", blob.ErrNotFound, url)
+		}
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if !info.Mode().IsRegular() {
+		file.Close()
+		return nil, nil, fmt.Errorf("%w: %s is not a file", blob.ErrNotFound, url)
+	}
+	rel, _ := filepath.Rel(r.uploadDir, path)
+	return file, &blob.Object{
+		Key:     filepath.ToSlash(rel),
+		URL:     url,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}, nil
+}
+
+// resolveLocal maps a stored URL to a path inside the upload dir, rejecting traversal outside of it
+func resolveLocal(uploadDir, url string) (string, error) {
+	path := filepath.Clean(filepath.FromSlash(url))
+	if !filepath.IsAbs(path) && !strings.HasPrefix(path, uploadDir+string(filepath.Separator)) {
+		path = filepath.Join(uploadDir, path)
+	}
+	rel, err := filepath.Rel(uploadDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside the upload dir", url)
+	}
+	return path, nil
+}
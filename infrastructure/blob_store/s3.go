@@ -0,0 +1,129 @@
+package blob_store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"media-service/domain/blob"
+	"media-service/infrastructure/s3"
+)
+
+type s3Store struct {
+	client *s3.Client
+}
+
+// NewS3Reader creates a blob reader for files stored by the S3 storage service
+func NewS3Reader(client *s3.Client) blob.Reader {
+	return &s3Store{client: client}
+}
+
+// NewS3Lister creates a blob lister of the objects under the configured prefix
+func NewS3Lister(client *s3.Client) blob.Lister {
+	return &s3Store{client: client}
+}
+
+// NewS3FileOpener creates a blob file opener reading byte ranges of the objects
+func NewS3FileOpener(client *s3.Client) blob.FileOpener {
+	return &s3Store{client: client}
+}
+
+func (s *s3Store) Open(ctx context.Context, url string) (io.ReadCloser, error) {
+	key, err := s.client.Key(url)
+	if err != nil {
+		return nil, err
+	}
+	return s.client.GetObject(ctx, key)
+}
+
+func (s *s3Store) List(ctx context.Context, fn func(*blob.Object) error) error {
+	return s.client.ListObjects(ctx, func(obj *s3.ObjectInfo) error {
+		return fn(&blob.Object{
+			Key:     s.client.ObjectName(obj.Key),
+			URL:     s.client.URL(obj.Key),
+			Size:    obj.Size,
+			ModTime: obj.LastModified,
+		})
+	})
+}
+
+func (s *s3Store) Key(url string) (string, error) {
+	key, err := s.client.Key(url)
+	if err != nil {
+		return "", err
+	}
+	return s.client.ObjectName(key), nil
+}
+
+func (s *s3Store) OpenFile(ctx context.Context, url string) (io.ReadSeekCloser, *blob.Object, error) {
+	key, err := s.client.Key(url)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := s.client.HeadObject(ctx, key)
+	if err != nil {
+		if errors.Is(err, s3.ErrNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s", blob.ErrNotFound, url)
+		}
+		return nil, nil, err
+	}
+	file := &s3File{ctx: ctx, client: s.client, key: key, size: info.Size}
+	return file, &blob.Object{
+		Key:     s.client.ObjectName(key),
+		URL:     url,
+		Size:    info.Size,
+		ModTime: info.LastModified,
+	}, nil
+}
+
+// s3File reads an object from its current offset, opening a new ranged
+// request only when read after a seek
+type s3File struct {
+	ctx    context.Context
+	client *s3.Client
+	key    string
+	size   int64
+	offset int64
+	body   io.ReadCloser
+}
+
+func (f *s3File) Read(p []byte) (int, error) {
+	if f.offset >= f.size {
+		return 0, io.EOF
+	}
+	if f.body == nil {
+		body, err := f.client.GetObjectFrom(f.ctx, f.key, f.offset)
+		if err != nil {
+			return 0, err
+		}
+		f.body = body
+	}
+	n, err := f.body.Read(p)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *s3File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.size
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position")
+	}
+	if offset != f.offset && f.body != nil {
+		f.body.Close()
+		f.body = nil
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *s3File) Close() error {
+	if f.body == nil {
+		return nil
+	}
+	return f.body.Close()
+}
@@ -0,0 +1,195 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"media-service/constants"
+	"media-service/domain/urlsign"
+	"media-service/domain/usecase"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/anhvanhoa/service-core/domain/log"
+)
+
+// Config configures the HTTP delivery of stored files
+type Config struct {
+	Addr            string
+	PublicOriginals bool              // Serve originals, not only their renditions
+	CacheControl    map[string]string // Cache-Control by media type or track; "default" for the others
+	DefaultCache    string            // Cache-Control when neither the class nor "default" is configured
+	RequireSigned   bool              // Refuse requests without a signed URL
+	TrustProxy      bool              // Take the client address from X-Forwarded-For
+}
+
+// Server streams media, their renditions and text tracks from storage:
+//
+//	GET /media/{id}                   original
+//	GET /media/{id}/{rendition}       variant, such as preview or thumbnail_small
+//	GET /media/{id}/tracks/{track}    text track
+//	GET /health                       liveness probe
+//
+// Range requests, If-None-Match, If-Modified-Since and If-Range are handled by
+// http.ServeContent; ?download=1 asks for an attachment. URLs signed by
+// GetSignedURL carry exp, kid, ip and sig parameters; they grant the original
+// too, and are cached privately until they expire. Media being restored from
+// a colder storage tier get 503 with Retry-After.
+type Server struct {
+	mediaUsecases usecase.MediaUsecaseInterfaces
+	logger        *log.LogGRPCImpl
+	config        Config
+}
+
+// NewServer creates a delivery server
+func NewServer(mediaUsecases usecase.MediaUsecaseInterfaces, logger *log.LogGRPCImpl, config Config) *Server {
+	return &Server{
+		mediaUsecases: mediaUsecases,
+		logger:        logger,
+		config:        config,
+	}
+}
+
+// Handler routes the delivery requests
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("GET /media/{id}", s.serve)
+	mux.HandleFunc("GET /media/{id}/{rendition}", s.serve)
+	mux.HandleFunc("GET /media/{id}/tracks/{track}", s.serve)
+	return mux
+}
+
+// Serve listens on the configured address until ctx is done
+func (s *Server) Serve(ctx context.Context) error {
+	server := &http.Server{Addr: s.config.Addr, Handler: s.Handler()}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	token, err := urlsign.ParseToken(r.URL.Query())
+	if err != nil {
+		http.Error(w, "forbidden", http.StatusForbidden)
+		return
+	}
+	delivery, err := s.mediaUsecases.DeliverMedia(r.Context(), &usecase.DeliverMediaRequest{
+		MediaID:       r.PathValue("id"),
+		Rendition:     r.PathValue("rendition"),
+		TrackID:       r.PathValue("track"),
+		AllowOriginal: s.config.PublicOriginals,
+		Token:         token,
+		ClientIP:      s.clientIP(r),
+		RequireSigned: s.config.RequireSigned,
+	})
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+	defer delivery.File.Close()
+
+	header := w.Header()
+	header.Set("Content-Type", contentType(delivery))
+	header.Set("Content-Disposition", disposition(delivery.Name, r.URL.Query().Get("download") != ""))
+	if token != nil {
+		maxAge := max(token.Expires-time.Now().Unix(), 0)
+		header.Set("Cache-Control", "private, max-age="+strconv.FormatInt(maxAge, 10))
+	} else {
+		header.Set("Cache-Control", s.cacheControl(delivery.Class))
+	}
+	header.Set("ETag", delivery.ETag)
+	header.Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", delivery.ModTime, delivery.File)
+}
+
+func (s *Server) cacheControl(class string) string {
+	if policy, ok := s.config.CacheControl[class]; ok {
+		return policy
+	}
+	if policy, ok := s.config.CacheControl["default"]; ok {
+		return policy
+	}
+	return s.config.DefaultCache
+}
+
+// clientIP is the address signed URLs are bound to: the peer, or the last
+// X-Forwarded-For entry, appended by the proxy in front of the server
+func (s *Server) clientIP(r *http.Request) string {
+	if s.config.TrustProxy {
+		if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+			entries := strings.Split(forwarded[len(forwarded)-1], ",")
+			return strings.TrimSpace(entries[len(entries)-1])
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// contentType is the MIME type recorded on the row, else guessed from the
+// file name; text types are declared UTF-8
+func contentType(delivery *usecase.Delivery) string {
+	contentType := delivery.MimeType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(extension(delivery.Name))
+	}
+	if contentType == "" {
+		return "application/octet-stream"
+	}
+	if strings.HasPrefix(contentType, "text/") && !strings.Contains(contentType, "charset") {
+		contentType += "; charset=utf-8"
+	}
+	return contentType
+}
+
+func extension(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[i:]
+	}
+	return ""
+}
+
+// disposition encodes the file name, non-ASCII ones included (RFC 6266)
+func disposition(name string, download bool) string {
+	kind := "inline"
+	if download {
+		kind = "attachment"
+	}
+	if value := mime.FormatMediaType(kind, map[string]string{"filename": name}); value != "" {
+		return value
+	}
+	return kind
+}
+
+func (s *Server) writeError(w http.ResponseWriter, err error) {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		http.Error(w, "not found", http.StatusNotFound)
+	case strings.Contains(message, "unauthorized"):
+		http.Error(w, "forbidden", http.StatusForbidden)
+	case strings.Contains(message, "validation failed"):
+		http.Error(w, message, http.StatusBadRequest)
+	case strings.Contains(message, "restoring"):
+		w.Header().Set("Retry-After", strconv.Itoa(constants.RestoreRetryAfter))
+		http.Error(w, "media is being restored", http.StatusServiceUnavailable)
+	default:
+		s.logger.Error(fmt.Sprintf("Failed to deliver media: %v", err))
+		http.Error(w, "failed to deliver media", http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,108 @@
+package document
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"media-service/domain/document"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type popplerInspector struct{}
+
+// NewPopplerInspector creates a document inspector backed by the poppler-utils
+// pdfinfo and pdftotext binaries
+func NewPopplerInspector() document.Inspector {
+	return &popplerInspector{}
+}
+
+func (p *popplerInspector) Inspect(ctx context.Context, path string) (*document.Info, error) {
+	out, err := run(ctx, "pdfinfo", path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PDF: %w", err)
+	}
+
+	info := &document.Info{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch key {
+		case "Title":
+			info.Title = value
+		case "Author":
+			info.Author = value
+		case "Pages":
+			info.PageCount, _ = strconv.Atoi(value)
+		}
+	}
+	if info.PageCount <= 0 {
+		return nil, fmt.Errorf("invalid PDF: no pages")
+	}
+	return info, nil
+}
+
+func (p *popplerInspector) ExtractText(ctx context.Context, path string) (string, error) {
+	out, err := run(ctx, "pdftotext", "-enc", "UTF-8", "-q", path, "-")
+	if err != nil {
+		return "", err
+	}
+	return strings.ToValidUTF8(string(out), ""), nil
+}
+
+type popplerRenderer struct {
+	dpi int
+}
+
+// NewPopplerRenderer creates a page renderer backed by the poppler-utils pdftoppm binary
+func NewPopplerRenderer(dpi int) document.Renderer {
+	if dpi <= 0 {
+		dpi = 72
+	}
+	return &popplerRenderer{dpi: dpi}
+}
+
+func (p *popplerRenderer) RenderPage(ctx context.Context, path string, page int) (image.Image, error) {
+	pageArg := strconv.Itoa(page)
+	out, err := run(ctx, "pdftoppm",
+		"-png", "-singlefile",
+		"-f", pageArg, "-l", pageArg,
+		"-r", strconv.Itoa(p.dpi),
+		path,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewReader(out))
+}
+
+type noopRenderer struct{}
+
+// NewNoopRenderer creates a renderer for deployments without a PDF rasterizer;
+// documents are stored without previews
+func NewNoopRenderer() document.Renderer {
+	return &noopRenderer{}
+}
+
+func (n *noopRenderer) RenderPage(ctx context.Context, path string, page int) (image.Image, error) {
+	return nil, fmt.Errorf("document preview rendering is disabled")
+}
+
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,35 @@
+package grpc_service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CallerFunc returns the ID of the user the authorization interceptor
+// authenticated for a request, empty when there is none
+type CallerFunc func(ctx context.Context) string
+
+// caller identifies the user of a request from its authenticated context,
+// never from a request field; admins are the admin_users by their
+// authenticated ID
+func (s *MediaServiceServer) caller(ctx context.Context) (string, bool, error) {
+	userID := s.callerID(ctx)
+	if userID == "" {
+		return "", false, status.Errorf(codes.Unauthenticated, "unauthenticated")
+	}
+	return userID, s.adminUsers[userID], nil
+}
+
+// requireAdmin rejects the callers of an admin-only RPC who are not admins
+func (s *MediaServiceServer) requireAdmin(ctx context.Context) error {
+	_, isAdmin, err := s.caller(ctx)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return status.Errorf(codes.PermissionDenied, "admin only")
+	}
+	return nil
+}
@@ -6,33 +6,50 @@ import (
 	"fmt"
 	"io"
 	"media-service/domain/entity"
+	"media-service/domain/progress"
 	"os"
 
+	"media-service/domain/repository"
 	"media-service/domain/usecase"
 	"strings"
+	"time"
 
 	"github.com/anhvanhoa/sf-proto/gen/media/v1"
 
 	"github.com/anhvanhoa/service-core/domain/goid"
 	"github.com/anhvanhoa/service-core/domain/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const (
+	errorDomain             = "media-service"
+	unsupportedFormatReason = "UNSUPPORTED_FORMAT"
+)
+
 type MediaServiceServer struct {
 	media.UnsafeMediaServiceServer
 	mediaUsecases usecase.MediaUsecaseInterfaces
 	logger        *log.LogGRPCImpl
 	uuid          goid.GoUUID
+	adminUsers    map[string]bool
+	callerID      CallerFunc
 }
 
-func NewMediaServiceServer(mediaUsecases usecase.MediaUsecaseInterfaces, logger *log.LogGRPCImpl) media.MediaServiceServer {
+func NewMediaServiceServer(mediaUsecases usecase.MediaUsecaseInterfaces, logger *log.LogGRPCImpl, adminUsers []string, callerID CallerFunc) media.MediaServiceServer {
 	uuid := goid.NewGoId().UUID()
+	admins := make(map[string]bool, len(adminUsers))
+	for _, id := range adminUsers {
+		admins[id] = true
+	}
 	return &MediaServiceServer{
 		mediaUsecases: mediaUsecases,
 		logger:        logger,
 		uuid:          uuid,
+		adminUsers:    admins,
+		callerID:      callerID,
 	}
 }
 
@@ -83,12 +100,13 @@ func (s *MediaServiceServer) UploadMediaStream(stream media.MediaService_UploadM
 		Metadata:  info.Metadata,
 		FileData:  tmpFile,
 		FileSize:  infoFile.Size(),
+		Priority:  entity.Priority(info.Priority),
 	}
 
 	result, err := s.mediaUsecases.UploadMediaStream(stream.Context(), uploadReq)
 	if err != nil {
 		s.logger.Error(fmt.Sprintf("Failed to upload media via stream: %v", err))
-		return status.Errorf(codes.Internal, "failed to upload media: %v", err)
+		return uploadError(err)
 	}
 
 	response := &media.UploadMediaResponse{
@@ -108,12 +126,13 @@ func (s *MediaServiceServer) UploadMedia(ctx context.Context, req *media.UploadM
 		FileData:  bytes.NewReader(req.FileData),
 		Type:      entity.MediaTypeImage,
 		Size:      int64(len(req.FileData)),
+		Priority:  entity.Priority(req.Priority),
 	}
 
 	result, err := s.mediaUsecases.UploadMedia(ctx, uploadReq)
 	if err != nil {
 		s.logger.Error(fmt.Sprintf("Failed to upload media: %v", err))
-		return nil, status.Errorf(codes.Internal, "failed to upload media: %v", err)
+		return nil, uploadError(err)
 	}
 
 	return &media.UploadMediaResponse{
@@ -136,6 +155,25 @@ func (s *MediaServiceServer) GetMedia(ctx context.Context, req *media.GetMediaRe
 	}, nil
 }
 
+// uploadError maps a processing failure; unsupported formats carry the
+// UNSUPPORTED_FORMAT reason so clients can tell them from server errors
+func uploadError(err error) error {
+	switch {
+	case strings.Contains(err.Error(), "unsupported format"):
+		st := status.New(codes.InvalidArgument, err.Error())
+		if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+			Reason: unsupportedFormatReason,
+			Domain: errorDomain,
+		}); detailErr == nil {
+			st = detailed
+		}
+		return st.Err()
+	case strings.Contains(err.Error(), "validation failed"):
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return status.Errorf(codes.Internal, "failed to upload media: %v", err)
+}
+
 func (s *MediaServiceServer) ListMedia(ctx context.Context, req *media.ListMediaRequest) (*media.ListMediaResponse, error) {
 	listReq := &usecase.ListMediaRequest{
 		CreatedBy: req.CreatedBy,
@@ -151,6 +189,13 @@ func (s *MediaServiceServer) ListMedia(ctx context.Context, req *media.ListMedia
 	if req.MimeType != "" {
 		listReq.MimeType = req.MimeType
 	}
+	if req.Search != "" {
+		listReq.Search = req.Search
+	}
+	if req.Color != "" {
+		listReq.Color = req.Color
+		listReq.ColorDist = req.ColorDistance
+	}
 
 	response, err := s.mediaUsecases.List(ctx, listReq)
 	if err != nil {
@@ -171,7 +216,14 @@ func (s *MediaServiceServer) ListMedia(ctx context.Context, req *media.ListMedia
 
 func (s *MediaServiceServer) UpdateMedia(ctx context.Context, req *media.UpdateMediaRequest) (*media.UpdateMediaResponse, error) {
 	updateReq := &usecase.UpdateMediaRequest{
-		Metadata: req.Metadata,
+		Metadata:        req.Metadata,
+		ClearFocalPoint: req.ClearFocalPoint,
+	}
+	if req.FocalPoint != nil {
+		updateReq.FocalPoint = &entity.FocalPoint{
+			X: req.FocalPoint.X,
+			Y: req.FocalPoint.Y,
+		}
 	}
 
 	if req.Name != "" {
@@ -187,6 +239,9 @@ func (s *MediaServiceServer) UpdateMedia(ctx context.Context, req *media.UpdateM
 		if strings.Contains(err.Error(), "unauthorized") {
 			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
 		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
 		return nil, status.Errorf(codes.Internal, "failed to update media: %v", err)
 	}
 
@@ -213,6 +268,572 @@ func (s *MediaServiceServer) DeleteMedia(ctx context.Context, req *media.DeleteM
 	}, nil
 }
 
+func (s *MediaServiceServer) FindSimilarMedia(ctx context.Context, req *media.FindSimilarMediaRequest) (*media.FindSimilarMediaResponse, error) {
+	userID, isAdmin, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	findReq := &usecase.FindSimilarMediaRequest{
+		MediaID:     req.Id,
+		MaxDistance: int(req.MaxDistance),
+		Limit:       int(req.Limit),
+		RequestedBy: userID,
+		IsAdmin:     isAdmin,
+	}
+	if len(req.ProbeData) > 0 {
+		findReq.ProbeData = bytes.NewReader(req.ProbeData)
+	}
+
+	results, err := s.mediaUsecases.FindSimilar(ctx, findReq)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to find similar media: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "media not found")
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to find similar media: %v", err)
+	}
+
+	protoResults := make([]*media.SimilarMedia, len(results))
+	for i, result := range results {
+		protoResults[i] = &media.SimilarMedia{
+			Media:    s.entityToProto(result.Media),
+			Distance: int32(result.Distance),
+		}
+	}
+
+	return &media.FindSimilarMediaResponse{
+		Results: protoResults,
+	}, nil
+}
+
+func (s *MediaServiceServer) ReprocessMedia(ctx context.Context, req *media.ReprocessMediaRequest) (*media.ReprocessMediaResponse, error) {
+	userID, isAdmin, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reprocessReq := &usecase.ReprocessMediaRequest{
+		MediaID:     req.Id,
+		RequestedBy: userID,
+		IsAdmin:     isAdmin,
+		Priority:    entity.Priority(req.Priority),
+	}
+	if filter := req.Filter; filter != nil {
+		reprocessReq.Filters = &repository.MediaFilters{
+			CreatedBy:   filter.CreatedBy,
+			Type:        entity.MediaType(filter.Type),
+			MimeType:    filter.MimeType,
+			Color:       filter.Color,
+			ColorDist:   filter.ColorDistance,
+			Search:      filter.Search,
+			Misoriented: filter.Misoriented,
+		}
+	}
+
+	job, err := s.mediaUsecases.ReprocessMedia(ctx, reprocessReq)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to reprocess media: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "media not found")
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reprocess media: %v", err)
+	}
+
+	return &media.ReprocessMediaResponse{
+		Job: s.reprocessJobToProto(job),
+	}, nil
+}
+
+func (s *MediaServiceServer) GetReprocessJob(ctx context.Context, req *media.GetReprocessJobRequest) (*media.GetReprocessJobResponse, error) {
+	userID, isAdmin, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.mediaUsecases.GetReprocessJob(ctx, req.Id, userID, isAdmin)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to get reprocess job: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "reprocess job not found")
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get reprocess job: %v", err)
+	}
+
+	return &media.GetReprocessJobResponse{
+		Job: s.reprocessJobToProto(job),
+	}, nil
+}
+
+func (s *MediaServiceServer) CreateClip(ctx context.Context, req *media.CreateClipRequest) (*media.CreateClipResponse, error) {
+	userID, _, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.mediaUsecases.CreateClip(ctx, &usecase.CreateClipRequest{
+		SourceID:    req.SourceId,
+		Start:       req.StartTime,
+		End:         req.EndTime,
+		Width:       int(req.Width),
+		Height:      int(req.Height),
+		Mode:        req.Mode,
+		RequestedBy: userID,
+	})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to create clip: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "media not found")
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create clip: %v", err)
+	}
+
+	return &media.CreateClipResponse{
+		Job: s.clipJobToProto(job),
+	}, nil
+}
+
+func (s *MediaServiceServer) GetClipJob(ctx context.Context, req *media.GetClipJobRequest) (*media.GetClipJobResponse, error) {
+	userID, isAdmin, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job, err := s.mediaUsecases.GetClipJob(ctx, req.Id, userID, isAdmin)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to get clip job: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "clip job not found")
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get clip job: %v", err)
+	}
+
+	return &media.GetClipJobResponse{
+		Job: s.clipJobToProto(job),
+	}, nil
+}
+
+func (s *MediaServiceServer) AddMediaTrack(ctx context.Context, req *media.AddMediaTrackRequest) (*media.AddMediaTrackResponse, error) {
+	track, err := s.mediaUsecases.AddMediaTrack(ctx, &usecase.AddMediaTrackRequest{
+		MediaID:   req.MediaId,
+		Kind:      req.Kind,
+		Language:  req.Language,
+		Label:     req.Label,
+		Data:      req.FileData,
+		CreatedBy: req.CreatedBy,
+	})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to add media track: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "media not found")
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to add media track: %v", err)
+	}
+
+	return &media.AddMediaTrackResponse{
+		Track: s.trackToProto(track),
+	}, nil
+}
+
+func (s *MediaServiceServer) ListMediaTracks(ctx context.Context, req *media.ListMediaTracksRequest) (*media.ListMediaTracksResponse, error) {
+	tracks, err := s.mediaUsecases.ListMediaTracks(ctx, req.MediaId)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to list media tracks: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "media not found")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to list media tracks: %v", err)
+	}
+
+	response := &media.ListMediaTracksResponse{}
+	for _, track := range tracks {
+		response.Tracks = append(response.Tracks, s.trackToProto(track))
+	}
+	return response, nil
+}
+
+func (s *MediaServiceServer) DeleteMediaTrack(ctx context.Context, req *media.DeleteMediaTrackRequest) (*media.DeleteMediaTrackResponse, error) {
+	err := s.mediaUsecases.DeleteMediaTrack(ctx, req.Id, req.CreatedBy)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to delete media track: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "track not found")
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to delete media track: %v", err)
+	}
+
+	return &media.DeleteMediaTrackResponse{
+		Success: true,
+	}, nil
+}
+
+func (s *MediaServiceServer) trackToProto(track *entity.MediaTrack) *media.MediaTrack {
+	return &media.MediaTrack{
+		Id:        track.ID,
+		MediaId:   track.MediaID,
+		Kind:      track.Kind,
+		Language:  track.Language,
+		Label:     track.Label,
+		Url:       track.URL,
+		MimeType:  track.MimeType,
+		CreatedAt: timestamppb.New(track.CreatedAt),
+	}
+}
+
+func (s *MediaServiceServer) reprocessJobToProto(job *entity.ReprocessJob) *media.ReprocessJob {
+	proto := &media.ReprocessJob{
+		Id:        job.ID,
+		Status:    string(job.Status),
+		Total:     int32(job.Total),
+		Processed: int32(job.Processed),
+		Failed:    int32(job.Failed),
+		Progress:  job.Progress(),
+		LastError: job.LastError,
+		CreatedAt: timestamppb.New(job.CreatedAt),
+	}
+	if job.CompletedAt != nil {
+		proto.CompletedAt = timestamppb.New(*job.CompletedAt)
+	}
+	return proto
+}
+
+func (s *MediaServiceServer) ListDeadTasks(ctx context.Context, req *media.ListDeadTasksRequest) (*media.ListDeadTasksResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	result, err := s.mediaUsecases.ListDeadTasks(ctx, &usecase.ListDeadTasksRequest{
+		Filter:  deadTaskFilter(req.Filter),
+		Limit:   int(req.Limit),
+		Offset:  int(req.Offset),
+		IsAdmin: true,
+	})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to list dead tasks: %v", err))
+		return nil, deadTaskError(err, "failed to list dead tasks")
+	}
+
+	tasks := make([]*media.DeadTask, len(result.Tasks))
+	for i, t := range result.Tasks {
+		tasks[i] = &media.DeadTask{
+			Id:       t.ID,
+			Queue:    t.Queue,
+			Type:     t.Type,
+			Payload:  string(t.Payload),
+			Error:    t.Error,
+			Retried:  int32(t.Retried),
+			FailedAt: timestamppb.New(t.FailedAt),
+		}
+	}
+	return &media.ListDeadTasksResponse{
+		Tasks: tasks,
+		Total: int32(result.Total),
+	}, nil
+}
+
+func (s *MediaServiceServer) RequeueDeadTasks(ctx context.Context, req *media.RequeueDeadTasksRequest) (*media.RequeueDeadTasksResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	filter := deadTaskFilter(req.Filter)
+	count, err := s.mediaUsecases.RequeueDeadTasks(ctx, &filter, true)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to requeue dead tasks: %v", err))
+		return nil, deadTaskError(err, "failed to requeue dead tasks")
+	}
+	return &media.RequeueDeadTasksResponse{
+		Count: int32(count),
+	}, nil
+}
+
+func (s *MediaServiceServer) DeleteDeadTasks(ctx context.Context, req *media.DeleteDeadTasksRequest) (*media.DeleteDeadTasksResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	filter := deadTaskFilter(req.Filter)
+	count, err := s.mediaUsecases.DeleteDeadTasks(ctx, &filter, true)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to delete dead tasks: %v", err))
+		return nil, deadTaskError(err, "failed to delete dead tasks")
+	}
+	return &media.DeleteDeadTasksResponse{
+		Count: int32(count),
+	}, nil
+}
+
+func (s *MediaServiceServer) GetQueueStats(ctx context.Context, req *media.GetQueueStatsRequest) (*media.GetQueueStatsResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	stats, err := s.mediaUsecases.GetQueueStats(ctx, true)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to get queue stats: %v", err))
+		return nil, deadTaskError(err, "failed to get queue stats")
+	}
+
+	queues := make([]*media.QueueStats, len(stats))
+	for i, q := range stats {
+		queues[i] = &media.QueueStats{
+			Queue:     q.Queue,
+			Size:      int32(q.Size),
+			Pending:   int32(q.Pending),
+			Active:    int32(q.Active),
+			Scheduled: int32(q.Scheduled),
+			Retry:     int32(q.Retry),
+			Archived:  int32(q.Archived),
+		}
+	}
+	return &media.GetQueueStatsResponse{
+		Queues: queues,
+	}, nil
+}
+
+func (s *MediaServiceServer) WatchProcessing(req *media.WatchProcessingRequest, stream media.MediaService_WatchProcessingServer) error {
+	userID, isAdmin, err := s.caller(stream.Context())
+	if err != nil {
+		return err
+	}
+	err = s.mediaUsecases.WatchProcessing(stream.Context(), &usecase.WatchProcessingRequest{
+		MediaIDs:    req.Ids,
+		RequestedBy: userID,
+		IsAdmin:     isAdmin,
+	}, func(event *progress.Event) error {
+		return stream.Send(&media.ProcessingEvent{
+			MediaId: event.MediaID,
+			Status:  string(event.Status),
+			Stage:   event.Stage,
+			Percent: int32(event.Percent),
+			Reason:  event.Reason,
+			At:      timestamppb.New(event.At),
+		})
+	})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to watch processing: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return status.Errorf(codes.NotFound, "%v", err)
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return status.Errorf(codes.Internal, "failed to watch processing: %v", err)
+	}
+	return nil
+}
+
+func deadTaskFilter(filter *media.DeadTaskFilter) usecase.DeadTaskFilter {
+	if filter == nil {
+		return usecase.DeadTaskFilter{}
+	}
+	return usecase.DeadTaskFilter{
+		Queue:         filter.Queue,
+		ID:            filter.Id,
+		Type:          filter.Type,
+		MediaID:       filter.MediaId,
+		ErrorContains: filter.ErrorContains,
+	}
+}
+
+// deadTaskError maps the errors of the task administration usecases to gRPC statuses
+func deadTaskError(err error, message string) error {
+	if strings.Contains(err.Error(), "not found") {
+		return status.Errorf(codes.NotFound, "dead task not found")
+	}
+	if strings.Contains(err.Error(), "unauthorized") {
+		return status.Errorf(codes.PermissionDenied, "unauthorized")
+	}
+	if strings.Contains(err.Error(), "validation failed") {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return status.Errorf(codes.Internal, "%s: %v", message, err)
+}
+
+func (s *MediaServiceServer) StartStorageMigration(ctx context.Context, req *media.StartStorageMigrationRequest) (*media.StartStorageMigrationResponse, error) {
+	userID, isAdmin, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, status.Errorf(codes.PermissionDenied, "admin only")
+	}
+	migration, err := s.mediaUsecases.StartStorageMigration(ctx, &usecase.StartStorageMigrationRequest{
+		Source:       req.Source,
+		Target:       req.Target,
+		DeleteSource: req.DeleteSource,
+		RequestedBy:  userID,
+		IsAdmin:      true,
+	})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to start storage migration: %v", err))
+		return nil, storageMigrationError(err, "failed to start storage migration")
+	}
+	return &media.StartStorageMigrationResponse{
+		Migration: s.storageMigrationToProto(migration),
+	}, nil
+}
+
+func (s *MediaServiceServer) GetStorageMigration(ctx context.Context, req *media.GetStorageMigrationRequest) (*media.GetStorageMigrationResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	migration, err := s.mediaUsecases.GetStorageMigration(ctx, req.Id, true)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to get storage migration: %v", err))
+		return nil, storageMigrationError(err, "failed to get storage migration")
+	}
+	return &media.GetStorageMigrationResponse{
+		Migration: s.storageMigrationToProto(migration),
+	}, nil
+}
+
+func (s *MediaServiceServer) ResumeStorageMigration(ctx context.Context, req *media.ResumeStorageMigrationRequest) (*media.ResumeStorageMigrationResponse, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	migration, err := s.mediaUsecases.ResumeStorageMigration(ctx, req.Id, true)
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to resume storage migration: %v", err))
+		return nil, storageMigrationError(err, "failed to resume storage migration")
+	}
+	return &media.ResumeStorageMigrationResponse{
+		Migration: s.storageMigrationToProto(migration),
+	}, nil
+}
+
+func (s *MediaServiceServer) GetSignedURL(ctx context.Context, req *media.GetSignedURLRequest) (*media.GetSignedURLResponse, error) {
+	userID, isAdmin, err := s.caller(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := s.mediaUsecases.GetSignedURL(ctx, &usecase.GetSignedURLRequest{
+		MediaID:     req.Id,
+		Rendition:   req.Rendition,
+		TrackID:     req.TrackId,
+		TTL:         time.Duration(req.TtlSeconds) * time.Second,
+		ClientIP:    req.ClientIp,
+		RequestedBy: userID,
+		IsAdmin:     isAdmin,
+	})
+	if err != nil {
+		s.logger.Error(fmt.Sprintf("Failed to sign URL: %v", err))
+		if strings.Contains(err.Error(), "not found") {
+			return nil, status.Errorf(codes.NotFound, "%v", err)
+		}
+		if strings.Contains(err.Error(), "unauthorized") {
+			return nil, status.Errorf(codes.PermissionDenied, "unauthorized")
+		}
+		if strings.Contains(err.Error(), "validation failed") {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		if strings.Contains(err.Error(), "not configured") {
+			return nil, status.Errorf(codes.FailedPrecondition, "%v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to sign URL: %v", err)
+	}
+
+	return &media.GetSignedURLResponse{
+		Url:       signed.URL,
+		ExpiresAt: timestamppb.New(signed.ExpiresAt),
+	}, nil
+}
+
+func storageMigrationError(err error, message string) error {
+	if strings.Contains(err.Error(), "not found") {
+		return status.Errorf(codes.NotFound, "storage migration not found")
+	}
+	if strings.Contains(err.Error(), "unauthorized") {
+		return status.Errorf(codes.PermissionDenied, "unauthorized")
+	}
+	if strings.Contains(err.Error(), "validation failed") {
+		return status.Errorf(codes.InvalidArgument, "%v", err)
+	}
+	return status.Errorf(codes.Internal, "%s: %v", message, err)
+}
+
+func (s *MediaServiceServer) storageMigrationToProto(migration *entity.StorageMigration) *media.StorageMigration {
+	proto := &media.StorageMigration{
+		Id:           migration.ID,
+		Source:       migration.Source,
+		Target:       migration.Target,
+		DeleteSource: migration.DeleteSource,
+		Status:       string(migration.Status),
+		LastMediaId:  migration.LastMediaID,
+		Total:        int32(migration.Total),
+		Migrated:     int32(migration.Migrated),
+		Failed:       int32(migration.Failed),
+		Progress:     migration.Progress(),
+		LastError:    migration.LastError,
+		CreatedAt:    timestamppb.New(migration.CreatedAt),
+	}
+	if migration.CompletedAt != nil {
+		proto.CompletedAt = timestamppb.New(*migration.CompletedAt)
+	}
+	return proto
+}
+
+func (s *MediaServiceServer) clipJobToProto(job *entity.ClipJob) *media.ClipJob {
+	proto := &media.ClipJob{
+		Id:        job.ID,
+		SourceId:  job.SourceMediaID,
+		Status:    string(job.Status),
+		Mode:      job.Mode,
+		StartTime: job.Start,
+		EndTime:   job.End,
+		Width:     int32(job.Width),
+		Height:    int32(job.Height),
+		Error:     job.Error,
+		CreatedAt: timestamppb.New(job.CreatedAt),
+	}
+	if job.ClipMediaID != nil {
+		proto.ClipId = *job.ClipMediaID
+	}
+	if job.CompletedAt != nil {
+		proto.CompletedAt = timestamppb.New(*job.CompletedAt)
+	}
+	return proto
+}
+
 func (s *MediaServiceServer) entityToProto(entity *entity.Media) *media.Media {
 	proto := &media.Media{
 		Id:               entity.ID,
@@ -223,7 +844,14 @@ func (s *MediaServiceServer) entityToProto(entity *entity.Media) *media.Media {
 		MimeType:         entity.MimeType,
 		Type:             string(entity.Type),
 		ProcessingStatus: string(entity.ProcessingStatus),
+		Attempts:         int32(entity.Attempts),
+		LastError:        entity.LastError,
+		Priority:         string(entity.Priority),
+		StorageBackend:   entity.StorageBackend,
+		StorageTier:      entity.StorageTier,
+		TierStatus:       entity.TierStatus,
 		Metadata:         entity.Metadata,
+		Misoriented:      entity.Misoriented,
 		CreatedAt:        timestamppb.New(entity.CreatedAt),
 		UpdatedAt:        timestamppb.New(entity.UpdatedAt),
 	}
@@ -237,6 +865,36 @@ func (s *MediaServiceServer) entityToProto(entity *entity.Media) *media.Media {
 	if entity.Duration != nil {
 		proto.Duration = int32(*entity.Duration)
 	}
+	if entity.PageCount != nil {
+		proto.PageCount = int32(*entity.PageCount)
+	}
+	if entity.SourceMediaID != nil {
+		proto.SourceId = *entity.SourceMediaID
+	}
+	if entity.LastAccessedAt != nil {
+		proto.LastAccessedAt = timestamppb.New(*entity.LastAccessedAt)
+	}
+	if focal := entity.FocalPoint(); focal != nil {
+		proto.FocalPoint = &media.FocalPoint{
+			X: focal.X,
+			Y: focal.Y,
+		}
+	}
+	for _, variant := range entity.Variants {
+		proto.Variants = append(proto.Variants, &media.MediaVariant{
+			Name:     variant.Name,
+			Url:      variant.URL,
+			MimeType: variant.MimeType,
+			Width:    int32(variant.Width),
+			Height:   int32(variant.Height),
+		})
+	}
+	for _, color := range entity.Palette {
+		proto.Palette = append(proto.Palette, &media.PaletteColor{
+			Hex:   color.Hex,
+			Share: color.Share,
+		})
+	}
 
 	return proto
 }
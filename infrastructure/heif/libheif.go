@@ -0,0 +1,63 @@
+package heif
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"media-service/domain/heif"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type libheifDecoder struct{}
+
+// NewLibheifDecoder creates a HEIF decoder backed by the libheif heif-dec binary,
+// which applies the image transformations
+func NewLibheifDecoder() heif.Decoder {
+	return &libheifDecoder{}
+}
+
+func (d *libheifDecoder) Decode(ctx context.Context, path string, info *heif.Info) (image.Image, error) {
+	dir, err := os.MkdirTemp("", "heif-*")
+	if err != nil {
+		return nil, fmt.Errorf("cannot create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "heif-dec", path, filepath.Join(dir, "image.png"))
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: heif-dec: %v: %s", heif.ErrUnsupported, err, strings.TrimSpace(stderr.String()))
+	}
+
+	// Containers with several top-level images are written one file per image
+	output := "image.png"
+	if info.Images > 1 {
+		output = fmt.Sprintf("image-%d.png", info.PrimaryIndex+1)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, output))
+	if err != nil {
+		return nil, fmt.Errorf("%w: heif-dec wrote no primary image", heif.ErrUnsupported)
+	}
+	return png.Decode(bytes.NewReader(data))
+}
+
+type noopDecoder struct{}
+
+// NewNoopDecoder creates a decoder for deployments without libheif; HEIF
+// uploads are rejected as unsupported
+func NewNoopDecoder() heif.Decoder {
+	return &noopDecoder{}
+}
+
+func (n *noopDecoder) Decode(ctx context.Context, path string, info *heif.Info) (image.Image, error) {
+	return nil, fmt.Errorf("%w: HEIF decoding is disabled", heif.ErrUnsupported)
+}
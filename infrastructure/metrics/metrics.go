@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"net/http"
+	"time"
+)
+
+// reaper counts the stuck media sweeps, published as media_reaper in /debug/vars
+var reaper = expvar.NewMap("media_reaper")
+
+// RecordReap adds the outcome of a stuck media sweep; a failed sweep only counts as an error
+func RecordReap(found, requeued, failed, errs int, sweepErr error) {
+	reaper.Add("runs", 1)
+	if sweepErr != nil {
+		reaper.Add("run_errors", 1)
+		return
+	}
+	reaper.Add("found", int64(found))
+	reaper.Add("requeued", int64(requeued))
+	reaper.Add("failed", int64(failed))
+	reaper.Add("errors", int64(errs))
+
+	last := new(expvar.Int)
+	last.Set(time.Now().Unix())
+	reaper.Set("last_run_unix", last)
+}
+
+// Serve exposes the expvar metrics on /debug/vars until ctx is done
+func Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package progress
+
+import (
+	"context"
+	"media-service/domain/progress"
+	"sync"
+)
+
+type localBroker struct {
+	mu   sync.Mutex
+	subs map[string]map[chan *progress.Event]struct{}
+}
+
+// NewLocalBroker creates an in-process broker, for deployments running the
+// API and the worker as one process
+func NewLocalBroker() progress.Broker {
+	return &localBroker{subs: make(map[string]map[chan *progress.Event]struct{})}
+}
+
+// Publish drops the event for subscribers whose buffer is full rather than block the publisher
+func (b *localBroker) Publish(ctx context.Context, event *progress.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for events := range b.subs[event.MediaID] {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *localBroker) Subscribe(ctx context.Context, mediaIDs []string) (<-chan *progress.Event, error) {
+	events := make(chan *progress.Event, bufferSize)
+	b.mu.Lock()
+	for _, id := range mediaIDs {
+		if b.subs[id] == nil {
+			b.subs[id] = make(map[chan *progress.Event]struct{})
+		}
+		b.subs[id][events] = struct{}{}
+	}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for _, id := range mediaIDs {
+			delete(b.subs[id], events)
+			if len(b.subs[id]) == 0 {
+				delete(b.subs, id)
+			}
+		}
+		close(events)
+	}()
+	return events, nil
+}
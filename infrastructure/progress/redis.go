@@ -0,0 +1,79 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"media-service/domain/progress"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// channelPrefix namespaces the pub/sub channel of each media
+const channelPrefix = "media:progress:"
+
+// bufferSize is the number of events a slow subscriber may lag behind
+const bufferSize = 64
+
+type redisBroker struct {
+	client *redis.Client
+}
+
+// NewRedisBroker creates a broker fanning out events through Redis pub/sub, so
+// events published by the workers reach the streams of every API replica
+func NewRedisBroker(addr, network, password string, db int) progress.Broker {
+	return &redisBroker{client: redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Network:  network,
+		Password: password,
+		DB:       db,
+	})}
+}
+
+func (b *redisBroker) Publish(ctx context.Context, event *progress.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode progress event: %w", err)
+	}
+	return b.client.Publish(ctx, channelPrefix+event.MediaID, data).Err()
+}
+
+func (b *redisBroker) Subscribe(ctx context.Context, mediaIDs []string) (<-chan *progress.Event, error) {
+	channels := make([]string, len(mediaIDs))
+	for i, id := range mediaIDs {
+		channels[i] = channelPrefix + id
+	}
+	sub := b.client.Subscribe(ctx, channels...)
+	// Wait for the confirmation so no event published afterwards is missed
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to subscribe to progress events: %w", err)
+	}
+
+	events := make(chan *progress.Event, bufferSize)
+	go func() {
+		defer close(events)
+		defer sub.Close()
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event progress.Event
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					continue
+				}
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type clipJobRepository struct {
+	db *pg.DB
+}
+
+// NewClipJobRepository creates a new clip job repository
+func NewClipJobRepository(db *pg.DB) repository.ClipJobRepository {
+	return &clipJobRepository{db: db}
+}
+
+func (r *clipJobRepository) Create(ctx context.Context, job *entity.ClipJob) error {
+	_, err := r.db.ModelContext(ctx, job).Insert()
+	return err
+}
+
+func (r *clipJobRepository) GetByID(ctx context.Context, id string) (*entity.ClipJob, error) {
+	job := &entity.ClipJob{}
+	err := r.db.ModelContext(ctx, job).Where("id = ?", id).Select()
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *clipJobRepository) UpdateStatus(ctx context.Context, job *entity.ClipJob) error {
+	_, err := r.db.ModelContext(ctx, job).
+		Column("status", "clip_media_id", "error", "completed_at").
+		WherePK().
+		Update()
+	return err
+}
@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"media-service/domain/entity"
+	"media-service/domain/imaging"
 	"media-service/domain/repository"
+	"time"
 
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 )
 
 type mediaRepository struct {
@@ -25,7 +28,10 @@ func (r *mediaRepository) Create(ctx context.Context, media *entity.Media) error
 
 func (r *mediaRepository) GetByID(ctx context.Context, id string) (*entity.Media, error) {
 	media := &entity.Media{}
-	err := r.db.ModelContext(ctx, media).Where("id = ?", id).Select()
+	err := r.db.ModelContext(ctx, media).
+		Relation("Variants").
+		Where("media.id = ?", id).
+		Select()
 	if err != nil {
 		if err == pg.ErrNoRows {
 			return nil, nil
@@ -58,7 +64,7 @@ func (r *mediaRepository) Delete(ctx context.Context, id string) error {
 
 func (r *mediaRepository) List(ctx context.Context, filters repository.MediaFilters) ([]*entity.Media, int, error) {
 	var media []*entity.Media
-	query := r.db.ModelContext(ctx, &media)
+	query := r.db.ModelContext(ctx, &media).ExcludeColumn("content")
 
 	// Apply filters
 	if filters.CreatedBy != "" {
@@ -70,6 +76,24 @@ func (r *mediaRepository) List(ctx context.Context, filters repository.MediaFilt
 	if filters.MimeType != "" {
 		query = query.Where("mime_type = ?", filters.MimeType)
 	}
+	if filters.Color != "" {
+		lab, err := imaging.HexToLab(filters.Color)
+		if err != nil {
+			return nil, 0, err
+		}
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM jsonb_to_recordset(palette) AS c(l float8, a float8, b float8) "+
+				"WHERE ciede2000(c.l, c.a, c.b, ?, ?, ?) <= ?)",
+			lab.L, lab.A, lab.B, filters.ColorDist,
+		)
+	}
+
+	if filters.Search != "" {
+		query = query.Where("content_tsv @@ websearch_to_tsquery('simple', ?)", filters.Search)
+	}
+	if filters.Misoriented {
+		query = query.Where("misoriented")
+	}
 
 	// Apply sorting
 	sortBy := "created_at"
@@ -100,21 +124,216 @@ func (r *mediaRepository) List(ctx context.Context, filters repository.MediaFilt
 	return media, count, err
 }
 
-func (r *mediaRepository) UpdateProcessingStatus(ctx context.Context, id string, status entity.ProcessingStatus) error {
+func (r *mediaRepository) UpdateProcessingStatus(ctx context.Context, media *entity.Media, from entity.ProcessingStatus) error {
+	result, err := r.db.ModelContext(ctx, (*entity.Media)(nil)).
+		Set("processing_status = ?", media.ProcessingStatus).
+		Set("attempts = ?", media.Attempts).
+		Set("last_error = NULLIF(?, '')", media.LastError).
+		Set("updated_at = NOW()").
+		Where("id = ?", media.ID).
+		Where("processing_status = ?", from).
+		Update()
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%w: media %s is no longer %s", entity.ErrInvalidTransition, media.ID, from)
+	}
+	return nil
+}
+
+func (r *mediaRepository) SetMisoriented(ctx context.Context, id string, flagged bool) error {
 	_, err := r.db.ModelContext(ctx, (*entity.Media)(nil)).
-		Set("processing_status = ?", status).
+		Set("misoriented = ?", flagged).
 		Set("updated_at = NOW()").
 		Where("id = ?", id).
 		Update()
 	return err
 }
 
-func (r *mediaRepository) GetPendingProcessing(ctx context.Context, limit int) ([]*entity.Media, error) {
+func (r *mediaRepository) GetPendingProcessing(ctx context.Context, updatedBefore time.Time, limit int) ([]*entity.Media, error) {
 	var media []*entity.Media
 	err := r.db.ModelContext(ctx, &media).
-		Where("processing_status = ?", entity.ProcessingStatusPending).
-		Order("created_at ASC").
+		ExcludeColumn("content").
+		Where("processing_status IN (?, ?)", entity.ProcessingStatusPending, entity.ProcessingStatusProcessing).
+		Where("updated_at < ?", updatedBefore).
+		Order("updated_at ASC").
 		Limit(limit).
 		Select()
 	return media, err
 }
+
+// fileReferences is the stored file of every media, variant and track; its
+// placeholders take the three entity.FileSource names
+const fileReferences = `(
+	SELECT ? AS source, id::text AS row_id, id::text AS media_id, url, storage_backend AS backend, created_at FROM media
+	UNION ALL
+	SELECT ?, id::text, media_id::text, url, storage_backend, created_at FROM media_variants
+	UNION ALL
+	SELECT ?, id::text, media_id::text, url, storage_backend, created_at FROM media_tracks
+) refs`
+
+// fileSourceTables maps the sources of file references to their tables
+var fileSourceTables = map[string]string{
+	entity.FileSourceMedia:    "media",
+	entity.FileSourceVariants: "media_variants",
+	entity.FileSourceTracks:   "media_tracks",
+}
+
+func (r *mediaRepository) GetFileReferences(ctx context.Context, after *entity.FileReference, limit int) ([]*entity.FileReference, error) {
+	var afterSource, afterID string
+	if after != nil {
+		afterSource, afterID = after.Source, after.RowID
+	}
+	var refs []*entity.FileReference
+	_, err := r.db.QueryContext(ctx, &refs, `
+SELECT source, row_id, media_id, url, backend, created_at FROM `+fileReferences+`
+WHERE url <> '' AND (source, row_id) > (?, ?)
+ORDER BY source, row_id
+LIMIT ?`,
+		entity.FileSourceMedia, entity.FileSourceVariants, entity.FileSourceTracks,
+		afterSource, afterID, limit)
+	return refs, err
+}
+
+func (r *mediaRepository) CountMediaOnBackend(ctx context.Context, backend string) (int, error) {
+	var count int
+	_, err := r.db.QueryOneContext(ctx, pg.Scan(&count), `
+SELECT count(DISTINCT media_id) FROM `+fileReferences+`
+WHERE url <> '' AND backend = ?`,
+		entity.FileSourceMedia, entity.FileSourceVariants, entity.FileSourceTracks,
+		backend)
+	return count, err
+}
+
+func (r *mediaRepository) GetMediaIDsOnBackend(ctx context.Context, backend, after string, limit int) ([]string, error) {
+	var ids []string
+	_, err := r.db.QueryContext(ctx, &ids, `
+SELECT DISTINCT media_id FROM `+fileReferences+`
+WHERE url <> '' AND backend = ? AND media_id > ?
+ORDER BY media_id
+LIMIT ?`,
+		entity.FileSourceMedia, entity.FileSourceVariants, entity.FileSourceTracks,
+		backend, after, limit)
+	return ids, err
+}
+
+func (r *mediaRepository) GetMediaFileReferences(ctx context.Context, mediaID string) ([]*entity.FileReference, error) {
+	var refs []*entity.FileReference
+	_, err := r.db.QueryContext(ctx, &refs, `
+SELECT source, row_id, media_id, url, backend, created_at FROM `+fileReferences+`
+WHERE url <> '' AND media_id = ?
+ORDER BY source, row_id`,
+		entity.FileSourceMedia, entity.FileSourceVariants, entity.FileSourceTracks,
+		mediaID)
+	return refs, err
+}
+
+func (r *mediaRepository) MoveFiles(ctx context.Context, moves []*repository.FileMove) error {
+	return r.db.RunInTransaction(ctx, func(tx *pg.Tx) error {
+		for _, move := range moves {
+			table, ok := fileSourceTables[move.From.Source]
+			if !ok {
+				return fmt.Errorf("unknown file source: %s", move.From.Source)
+			}
+			result, err := tx.ExecContext(ctx, `
+UPDATE ? SET url = ?, storage_backend = ?
+WHERE id = ? AND url = ? AND storage_backend = ?`,
+				pg.Ident(table), move.URL, move.Backend,
+				move.From.RowID, move.From.URL, move.From.Backend)
+			if err != nil {
+				return err
+			}
+			if result.RowsAffected() == 0 {
+				return fmt.Errorf("%w: %s %s", repository.ErrFileChanged, move.From.Source, move.From.RowID)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *mediaRepository) GetLifecycleCandidates(ctx context.Context, filters repository.LifecycleFilters) ([]string, error) {
+	var ids []string
+	query := r.db.ModelContext(ctx, (*entity.Media)(nil)).
+		Column("id").
+		Where("storage_backend IN (?)", pg.In(filters.Backends)).
+		Where("tier_status = ''").
+		Where("processing_status IN (?, ?)", entity.ProcessingStatusCompleted, entity.ProcessingStatusFailed)
+
+	if len(filters.Types) > 0 {
+		query = query.Where("type IN (?)", pg.In(filters.Types))
+	}
+	if filters.CreatedBefore != nil {
+		query = query.Where("created_at < ?", *filters.CreatedBefore)
+	}
+	if filters.AccessedBefore != nil {
+		query = query.Where("COALESCE(last_accessed_at, created_at) < ?", *filters.AccessedBefore)
+	}
+
+	err := query.
+		Order("created_at ASC").
+		Limit(filters.Limit).
+		Select(&ids)
+	return ids, err
+}
+
+func (r *mediaRepository) UpdateStorageTier(ctx context.Context, id, tier string) error {
+	_, err := r.db.ModelContext(ctx, (*entity.Media)(nil)).
+		Set("storage_tier = ?", tier).
+		Set("tier_status = ''").
+		Set("tier_updated_at = NOW()").
+		Where("id = ?", id).
+		Update()
+	return err
+}
+
+func (r *mediaRepository) MarkRestoring(ctx context.Context, id string, staleBefore time.Time) (bool, error) {
+	result, err := r.db.ModelContext(ctx, (*entity.Media)(nil)).
+		Set("tier_status = ?", entity.TierStatusRestoring).
+		Set("tier_updated_at = NOW()").
+		Where("id = ?", id).
+		Where("(tier_status = '' OR tier_updated_at < ?)", staleBefore).
+		Update()
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *mediaRepository) TouchAccess(ctx context.Context, id string, at, recordedAfter time.Time) error {
+	_, err := r.db.ModelContext(ctx, (*entity.Media)(nil)).
+		Set("last_accessed_at = ?", at).
+		Where("id = ?", id).
+		Where("(last_accessed_at IS NULL OR last_accessed_at < ?)", recordedAfter).
+		Update()
+	return err
+}
+
+// FindSimilar narrows the media down with the indexed bands of their hash
+// (multi-index hashing) before comparing the whole hashes
+func (r *mediaRepository) FindSimilar(ctx context.Context, filters repository.SimilarMediaFilters) ([]*entity.Media, error) {
+	var media []*entity.Media
+	distance := "length(replace(((phash # ?)::bit(64))::text, '0', ''))"
+	query := r.db.ModelContext(ctx, &media).
+		ExcludeColumn("content").
+		WhereGroup(func(q *orm.Query) (*orm.Query, error) {
+			for band, values := range imaging.HashBandCandidates(uint64(filters.PHash), filters.MaxDistance) {
+				q = q.WhereOr(fmt.Sprintf("phash_band%d IN (?)", band), pg.In(values))
+			}
+			return q, nil
+		}).
+		Where(distance+" <= ?", filters.PHash, filters.MaxDistance)
+
+	if filters.CreatedBy != "" {
+		query = query.Where("created_by = ?", filters.CreatedBy)
+	}
+	if filters.ExcludeID != "" {
+		query = query.Where("id <> ?", filters.ExcludeID)
+	}
+
+	err := query.
+		OrderExpr(distance+" ASC, created_at DESC", filters.PHash).
+		Limit(filters.Limit).
+		Select()
+	return media, err
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type mediaTrackRepository struct {
+	db *pg.DB
+}
+
+// NewMediaTrackRepository creates a new media track repository
+func NewMediaTrackRepository(db *pg.DB) repository.MediaTrackRepository {
+	return &mediaTrackRepository{db: db}
+}
+
+func (r *mediaTrackRepository) Create(ctx context.Context, track *entity.MediaTrack) error {
+	_, err := r.db.ModelContext(ctx, track).Insert()
+	return err
+}
+
+func (r *mediaTrackRepository) GetByID(ctx context.Context, id string) (*entity.MediaTrack, error) {
+	track := &entity.MediaTrack{}
+	err := r.db.ModelContext(ctx, track).Where("id = ?", id).Select()
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return track, nil
+}
+
+func (r *mediaTrackRepository) GetByMediaID(ctx context.Context, mediaID string) ([]*entity.MediaTrack, error) {
+	var tracks []*entity.MediaTrack
+	err := r.db.ModelContext(ctx, &tracks).
+		Where("media_id = ?", mediaID).
+		Order("kind ASC", "language ASC", "label ASC").
+		Select()
+	return tracks, err
+}
+
+func (r *mediaTrackRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.ModelContext(ctx, (*entity.MediaTrack)(nil)).Where("id = ?", id).Delete()
+	return err
+}
@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"context"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type mediaVariantRepository struct {
+	db *pg.DB
+}
+
+// NewMediaVariantRepository creates a new media variant repository
+func NewMediaVariantRepository(db *pg.DB) repository.MediaVariantRepository {
+	return &mediaVariantRepository{db: db}
+}
+
+func (r *mediaVariantRepository) Upsert(ctx context.Context, variants []*entity.MediaVariant) error {
+	if len(variants) == 0 {
+		return nil
+	}
+	_, err := r.db.ModelContext(ctx, &variants).
+		OnConflict("(media_id, name) DO UPDATE").
+		Set("url = EXCLUDED.url").
+		Set("mime_type = EXCLUDED.mime_type").
+		Set("width = EXCLUDED.width").
+		Set("height = EXCLUDED.height").
+		Set("watermark = EXCLUDED.watermark").
+		Set("updated_at = NOW()").
+		Insert()
+	return err
+}
+
+func (r *mediaVariantRepository) GetByMediaID(ctx context.Context, mediaID string) ([]*entity.MediaVariant, error) {
+	var variants []*entity.MediaVariant
+	err := r.db.ModelContext(ctx, &variants).
+		Where("media_id = ?", mediaID).
+		Order("name ASC").
+		Select()
+	return variants, err
+}
+
+func (r *mediaVariantRepository) DeleteByMediaID(ctx context.Context, mediaID string) error {
+	_, err := r.db.ModelContext(ctx, (*entity.MediaVariant)(nil)).Where("media_id = ?", mediaID).Delete()
+	return err
+}
+
+func (r *mediaVariantRepository) DeleteByNames(ctx context.Context, mediaID string, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	_, err := r.db.ModelContext(ctx, (*entity.MediaVariant)(nil)).
+		Where("media_id = ?", mediaID).
+		Where("name IN (?)", pg.In(names)).
+		Delete()
+	return err
+}
+
+func (r *mediaVariantRepository) GetMediaIDsByStaleWatermark(ctx context.Context, name, watermark string) ([]string, error) {
+	var mediaIDs []string
+	err := r.db.ModelContext(ctx, (*entity.MediaVariant)(nil)).
+		ColumnExpr("DISTINCT media_id").
+		Where("name = ?", name).
+		Where("watermark <> ?", watermark).
+		Select(&mediaIDs)
+	return mediaIDs, err
+}
@@ -0,0 +1,23 @@
+package repo
+
+import (
+	"context"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type pipelineRunRepository struct {
+	db *pg.DB
+}
+
+// NewPipelineRunRepository creates a new pipeline run repository
+func NewPipelineRunRepository(db *pg.DB) repository.PipelineRunRepository {
+	return &pipelineRunRepository{db: db}
+}
+
+func (r *pipelineRunRepository) Create(ctx context.Context, run *entity.PipelineRun) error {
+	_, err := r.db.ModelContext(ctx, run).Insert()
+	return err
+}
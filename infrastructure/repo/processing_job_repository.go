@@ -0,0 +1,41 @@
+package repo
+
+import (
+	"context"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type processingJobRepository struct {
+	db *pg.DB
+}
+
+// NewProcessingJobRepository creates a new processing job repository
+func NewProcessingJobRepository(db *pg.DB) repository.ProcessingJobRepository {
+	return &processingJobRepository{db: db}
+}
+
+func (r *processingJobRepository) Create(ctx context.Context, job *entity.ProcessingJob) error {
+	_, err := r.db.ModelContext(ctx, job).Insert()
+	return err
+}
+
+func (r *processingJobRepository) Finish(ctx context.Context, job *entity.ProcessingJob) error {
+	_, err := r.db.ModelContext(ctx, job).
+		Column("status", "error", "finished_at", "duration_ms").
+		WherePK().
+		Update()
+	return err
+}
+
+func (r *processingJobRepository) GetByMediaID(ctx context.Context, mediaID string, limit int) ([]*entity.ProcessingJob, error) {
+	var jobs []*entity.ProcessingJob
+	err := r.db.ModelContext(ctx, &jobs).
+		Where("media_id = ?", mediaID).
+		Order("started_at DESC").
+		Limit(limit).
+		Select()
+	return jobs, err
+}
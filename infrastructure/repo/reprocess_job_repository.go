@@ -0,0 +1,52 @@
+package repo
+
+import (
+	"context"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type reprocessJobRepository struct {
+	db *pg.DB
+}
+
+// NewReprocessJobRepository creates a new reprocess job repository
+func NewReprocessJobRepository(db *pg.DB) repository.ReprocessJobRepository {
+	return &reprocessJobRepository{db: db}
+}
+
+func (r *reprocessJobRepository) Create(ctx context.Context, job *entity.ReprocessJob) error {
+	_, err := r.db.ModelContext(ctx, job).Insert()
+	return err
+}
+
+func (r *reprocessJobRepository) GetByID(ctx context.Context, id string) (*entity.ReprocessJob, error) {
+	job := &entity.ReprocessJob{}
+	err := r.db.ModelContext(ctx, job).Where("id = ?", id).Select()
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (r *reprocessJobRepository) RecordResult(ctx context.Context, id string, failure error) error {
+	failed, lastError := 0, ""
+	if failure != nil {
+		failed, lastError = 1, failure.Error()
+	}
+	_, err := r.db.ModelContext(ctx, (*entity.ReprocessJob)(nil)).
+		Set("processed = processed + 1").
+		Set("failed = failed + ?", failed).
+		Set("last_error = COALESCE(NULLIF(?, ''), last_error)", lastError).
+		Set("status = CASE WHEN processed + 1 >= total THEN ? ELSE ? END",
+			entity.ReprocessJobStatusCompleted, entity.ReprocessJobStatusRunning).
+		Set("completed_at = CASE WHEN processed + 1 >= total THEN NOW() END").
+		Where("id = ?", id).
+		Update()
+	return err
+}
@@ -0,0 +1,43 @@
+package repo
+
+import (
+	"context"
+	"media-service/domain/entity"
+	"media-service/domain/repository"
+
+	"github.com/go-pg/pg/v10"
+)
+
+type storageMigrationRepository struct {
+	db *pg.DB
+}
+
+// NewStorageMigrationRepository creates a new storage migration repository
+func NewStorageMigrationRepository(db *pg.DB) repository.StorageMigrationRepository {
+	return &storageMigrationRepository{db: db}
+}
+
+func (r *storageMigrationRepository) Create(ctx context.Context, migration *entity.StorageMigration) error {
+	_, err := r.db.ModelContext(ctx, migration).Insert()
+	return err
+}
+
+func (r *storageMigrationRepository) GetByID(ctx context.Context, id string) (*entity.StorageMigration, error) {
+	migration := &entity.StorageMigration{}
+	err := r.db.ModelContext(ctx, migration).Where("id = ?", id).Select()
+	if err != nil {
+		if err == pg.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return migration, nil
+}
+
+func (r *storageMigrationRepository) Update(ctx context.Context, migration *entity.StorageMigration) error {
+	_, err := r.db.ModelContext(ctx, migration).
+		Column("status", "last_media_id", "total", "migrated", "failed", "last_error", "completed_at").
+		WherePK().
+		Update()
+	return err
+}
@@ -0,0 +1,351 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// MinPartSize is the smallest part S3 accepts, but for the last one
+	MinPartSize = 5 * 1024 * 1024
+	// DefaultPartSize is the part size of multipart uploads when unset
+	DefaultPartSize = 8 * 1024 * 1024
+	// DefaultMaxRetries is the number of retries of failed requests when unset
+	DefaultMaxRetries = 3
+
+	retryBaseDelay = 200 * time.Millisecond
+	timeFormat     = "20060102T150405Z"
+	dateFormat     = "20060102"
+)
+
+// Config addresses a bucket of an S3-compatible object store
+type Config struct {
+	Endpoint    string // Scheme and host, such as http://localhost:9000; empty is AWS in Region
+	Region      string
+	Bucket      string
+	AccessKey   string
+	SecretKey   string
+	PathStyle   bool   // Address the bucket in the path rather than the host, as MinIO requires
+	PublicURL   string // Base of the returned URLs; empty is the bucket URL
+	Prefix      string // Prepended to every object key
+	PartSize    int64  // Uploads larger than this are sent in parts
+	SSE         string // Server-side encryption: AES256 or aws:kms
+	SSEKMSKeyID string // KMS key of aws:kms encryption; empty is the bucket default
+	MaxRetries  int
+	HTTPClient  *http.Client // Lets tests point the client at an in-process fake
+}
+
+// Client is a minimal S3 client signing its requests with AWS Signature
+// Version 4. Requests failing with a network error, a 5xx or a 429 are
+// retried with an exponential backoff.
+type Client struct {
+	config  Config
+	base    *url.URL // Endpoint of the bucket
+	public  string
+	service string
+	http    *http.Client
+}
+
+// NewClient creates a client of the configured bucket
+func NewClient(config Config) (*Client, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = "https://s3." + config.Region + ".amazonaws.com"
+	}
+	if config.PartSize <= 0 {
+		config.PartSize = DefaultPartSize
+	}
+	if config.PartSize < MinPartSize {
+		return nil, fmt.Errorf("s3 part size must be at least %d bytes", MinPartSize)
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = DefaultMaxRetries
+	}
+	if config.SSE != "" && config.SSE != "AES256" && config.SSE != "aws:kms" {
+		return nil, fmt.Errorf("unsupported s3 server-side encryption: %s", config.SSE)
+	}
+	config.Prefix = strings.Trim(config.Prefix, "/")
+
+	base, err := url.Parse(strings.TrimRight(config.Endpoint, "/"))
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint: %s", config.Endpoint)
+	}
+	if config.PathStyle {
+		base.Path += "/" + config.Bucket
+	} else {
+		base.Host = config.Bucket + "." + base.Host
+	}
+
+	public := strings.TrimRight(config.PublicURL, "/")
+	if public == "" {
+		public = base.String()
+	}
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		config:  config,
+		base:    base,
+		public:  public,
+		service: "s3",
+		http:    httpClient,
+	}, nil
+}
+
+// ObjectKey prefixes the name of an object with the configured prefix
+func (c *Client) ObjectKey(name string) string {
+	name = strings.TrimLeft(name, "/")
+	if c.config.Prefix == "" {
+		return name
+	}
+	return c.config.Prefix + "/" + name
+}
+
+// ObjectName strips the configured prefix off an object key, reversing ObjectKey
+func (c *Client) ObjectName(key string) string {
+	if c.config.Prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, c.config.Prefix+"/")
+}
+
+// URL is the URL returned for an object
+func (c *Client) URL(key string) string {
+	return c.public + "/" + canonicalURI(key)
+}
+
+// Key maps a URL returned by URL, an s3:// URL of the bucket or a bare key
+// back to the object key
+func (c *Client) Key(rawURL string) (string, error) {
+	if escaped, ok := strings.CutPrefix(rawURL, c.public+"/"); ok && escaped != "" {
+		return url.PathUnescape(escaped)
+	}
+	if key, ok := strings.CutPrefix(rawURL, "s3://"+c.config.Bucket+"/"); ok && key != "" {
+		return key, nil
+	}
+	if !strings.Contains(rawURL, "://") && strings.Trim(rawURL, "/") != "" {
+		return strings.TrimLeft(rawURL, "/"), nil
+	}
+	return "", fmt.Errorf("url %s is not an object of bucket %s", rawURL, c.config.Bucket)
+}
+
+// request is an S3 call; body is kept in memory so it can be resent on retries
+type request struct {
+	method  string
+	key     string
+	query   url.Values
+	header  http.Header
+	body    []byte
+	success int // Expected status, 200 when zero
+}
+
+// Error is an error response of S3
+type Error struct {
+	Status  int
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("s3: %d %s: %s", e.Status, e.Code, e.Message)
+}
+
+// do sends the request, retrying transient failures. The caller closes the
+// body of the returned response.
+func (c *Client) do(ctx context.Context, req *request) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			delay := retryBaseDelay << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		resp, err := c.send(ctx, req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode == req.expected() {
+			return resp, nil
+		}
+
+		s3Err := readError(resp)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, s3Err
+		}
+		lastErr = s3Err
+	}
+	return nil, fmt.Errorf("s3 %s %s failed after %d attempts: %w", req.method, req.key, c.config.MaxRetries+1, lastErr)
+}
+
+func (r *request) expected() int {
+	if r.success == 0 {
+		return http.StatusOK
+	}
+	return r.success
+}
+
+func (c *Client) send(ctx context.Context, req *request) (*http.Response, error) {
+	target := *c.base
+	target.Path += "/" + req.key
+	target.RawPath = canonicalURI(target.Path)
+	target.RawQuery = canonicalQuery(req.query)
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target.String(), bytes.NewReader(req.body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.ContentLength = int64(len(req.body))
+	c.sign(httpReq, req.body, time.Now().UTC())
+	return c.http.Do(httpReq)
+}
+
+// sign adds the AWS Signature Version 4 authorization of the request
+func (c *Client) sign(req *http.Request, body []byte, now time.Time) {
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", now.Format(timeFormat))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "content-md5" || lower == "range" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL.Path),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := now.Format(dateFormat) + "/" + c.config.Region + "/" + c.service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		now.Format(timeFormat),
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.config.SecretKey), now.Format(dateFormat))
+	key = hmacSHA256(key, c.config.Region)
+	key = hmacSHA256(key, c.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// encryptionHeaders are the server-side encryption headers of new objects
+func (c *Client) encryptionHeaders(header http.Header) {
+	if c.config.SSE == "" {
+		return
+	}
+	header.Set("X-Amz-Server-Side-Encryption", c.config.SSE)
+	if c.config.SSE == "aws:kms" && c.config.SSEKMSKeyID != "" {
+		header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", c.config.SSEKMSKeyID)
+	}
+}
+
+func readError(resp *http.Response) error {
+	defer resp.Body.Close()
+	s3Err := &Error{Status: resp.StatusCode}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if xml.Unmarshal(data, s3Err) != nil || s3Err.Code == "" {
+		s3Err.Code = http.StatusText(resp.StatusCode)
+	}
+	return s3Err
+}
+
+// canonicalURI escapes every byte of the path but unreserved characters and slashes
+func canonicalURI(path string) string {
+	if path == "" {
+		return "/"
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		ch := path[i]
+		if ch == '/' || ch == '-' || ch == '_' || ch == '.' || ch == '~' ||
+			('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
+
+// canonicalQuery sorts and escapes the query as Signature Version 4 requires
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, escape(key)+"="+escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,283 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	fakeBucket    = "media"
+	fakeRegion    = "eu-west-1"
+	fakeAccessKey = "AKIDEXAMPLE"
+	fakeSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+// fakeS3 is an in-process S3 bucket checking the signature of every request
+// it receives
+type fakeS3 struct {
+	t         *testing.T
+	server    *httptest.Server
+	pathStyle bool
+
+	mu       sync.Mutex
+	objects  map[string][]byte
+	uploads  map[string]map[int][]byte // Parts of the multipart uploads in progress
+	nextID   int
+	requests []string                // Method and query of every request, as "PUT partNumber=1&uploadId=1"
+	hosts    []string                // Host header of every request
+	failures []int                   // Statuses returned, in order, before the next requests are served
+	fail     func(*http.Request) int // Status returned instead of serving a request when not zero
+}
+
+func newFakeS3(t *testing.T, pathStyle bool) *fakeS3 {
+	f := &fakeS3{
+		t:         t,
+		pathStyle: pathStyle,
+		objects:   make(map[string][]byte),
+		uploads:   make(map[string]map[int][]byte),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// client creates a client of the fake; in virtual-hosted style every bucket
+// host is dialed to the fake
+func (f *fakeS3) client(config Config) *Client {
+	config.Endpoint = f.server.URL
+	config.Bucket = fakeBucket
+	config.Region = fakeRegion
+	config.AccessKey = fakeAccessKey
+	config.SecretKey = fakeSecretKey
+	config.PathStyle = f.pathStyle
+	if !f.pathStyle {
+		transport := f.server.Client().Transport.(*http.Transport).Clone()
+		dial := transport.DialContext
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dial(ctx, network, f.server.Listener.Addr().String())
+		}
+		config.HTTPClient = &http.Client{Transport: transport}
+	}
+	client, err := NewClient(config)
+	if err != nil {
+		f.t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func (f *fakeS3) serve(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.t.Errorf("failed to read request body: %v", err)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.RawQuery)
+	f.hosts = append(f.hosts, r.Host)
+
+	if err := f.verify(r, body); err != nil {
+		f.t.Errorf("%s %s: %v", r.Method, r.URL, err)
+		writeError(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+	if len(f.failures) > 0 {
+		status := f.failures[0]
+		f.failures = f.failures[1:]
+		writeError(w, status, "InjectedFailure")
+		return
+	}
+	if f.fail != nil {
+		if status := f.fail(r); status != 0 {
+			writeError(w, status, "InjectedFailure")
+			return
+		}
+	}
+
+	key, ok := f.key(r)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "InvalidBucketName")
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		f.nextID++
+		id := strconv.Itoa(f.nextID)
+		f.uploads[id] = make(map[int][]byte)
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodPut && query.Has("uploadId"):
+		parts, ok := f.uploads[query.Get("uploadId")]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchUpload")
+			return
+		}
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		parts[number] = body
+		w.Header().Set("ETag", fmt.Sprintf("%q", "etag-"+strconv.Itoa(number)))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		f.complete(w, key, query.Get("uploadId"), body)
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		delete(f.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut:
+		f.objects[key] = body
+	case r.Method == http.MethodGet && key == "":
+		f.list(w)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		if start, ok := strings.CutPrefix(r.Header.Get("Range"), "bytes="); ok {
+			offset, _ := strconv.Atoi(strings.TrimSuffix(start, "-"))
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)-offset))
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(data[offset:])
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// key is the object key of a request, checking the bucket is addressed in
+// the configured style
+func (f *fakeS3) key(r *http.Request) (string, bool) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if f.pathStyle {
+		key, ok := strings.CutPrefix(path, fakeBucket)
+		return strings.TrimPrefix(key, "/"), ok
+	}
+	return path, strings.HasPrefix(r.Host, fakeBucket+".")
+}
+
+func (f *fakeS3) complete(w http.ResponseWriter, key, uploadID string, body []byte) {
+	parts, ok := f.uploads[uploadID]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchUpload")
+		return
+	}
+	var request struct {
+		Parts []completedPart `xml:"Part"`
+	}
+	if err := xml.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusBadRequest, "MalformedXML")
+		return
+	}
+	var data []byte
+	for n, part := range request.Parts {
+		if part.PartNumber != n+1 || part.ETag != fmt.Sprintf("%q", "etag-"+strconv.Itoa(part.PartNumber)) {
+			writeError(w, http.StatusBadRequest, "InvalidPart")
+			return
+		}
+		data = append(data, parts[part.PartNumber]...)
+	}
+	f.objects[key] = data
+	delete(f.uploads, uploadID)
+	fmt.Fprintf(w, "<CompleteMultipartUploadResult><Key>%s</Key></CompleteMultipartUploadResult>", key)
+}
+
+func (f *fakeS3) list(w http.ResponseWriter) {
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	fmt.Fprint(w, "<ListBucketResult>")
+	for _, key := range keys {
+		fmt.Fprintf(w, "<Contents><Key>%s</Key><Size>%d</Size></Contents>", key, len(f.objects[key]))
+	}
+	fmt.Fprint(w, "<IsTruncated>false</IsTruncated></ListBucketResult>")
+}
+
+// verify recomputes the Signature Version 4 of a request from what was
+// received on the wire
+func (f *fakeS3) verify(r *http.Request, body []byte) error {
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+	if !ok {
+		return fmt.Errorf("unsigned request")
+	}
+	fields := make(map[string]string)
+	for _, field := range strings.Split(auth, ", ") {
+		name, value, _ := strings.Cut(field, "=")
+		fields[name] = value
+	}
+	date := r.Header.Get("X-Amz-Date")
+	if len(date) != len(timeFormat) {
+		return fmt.Errorf("invalid X-Amz-Date %q", date)
+	}
+	scope := date[:8] + "/" + fakeRegion + "/s3/aws4_request"
+	if fields["Credential"] != fakeAccessKey+"/"+scope {
+		return fmt.Errorf("credential %s, want scope %s", fields["Credential"], scope)
+	}
+	if r.Header.Get("X-Amz-Content-Sha256") != sha256Hex(body) {
+		return fmt.Errorf("payload hash does not match the body")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, name := range strings.Split(fields["SignedHeaders"], ";") {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonicalRequest := strings.Join([]string{
+		r.Method,
+		r.URL.EscapedPath(),
+		r.URL.RawQuery,
+		canonicalHeaders.String(),
+		fields["SignedHeaders"],
+		sha256Hex(body),
+	}, "\n")
+	stringToSign := "AWS4-HMAC-SHA256\n" + date + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+fakeSecretKey), date[:8])
+	for _, part := range []string{fakeRegion, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	if want := hex.EncodeToString(hmacSHA256(key, stringToSign)); fields["Signature"] != want {
+		return fmt.Errorf("signature %s, want %s", fields["Signature"], want)
+	}
+	return nil
+}
+
+func (f *fakeS3) object(key string) ([]byte, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.objects[key]
+	return data, ok
+}
+
+func (f *fakeS3) requestLog() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.requests...)
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "<Error><Code>%s</Code><Message>injected by the fake</Message></Error>", code)
+	w.Write(b.Bytes())
+}
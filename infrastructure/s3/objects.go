@@ -0,0 +1,227 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// ErrNotFound is returned for missing objects
+var ErrNotFound = errors.New("s3: object not found")
+
+// ObjectInfo describes a listed object
+type ObjectInfo struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// PutObject uploads an object, in parts when it is larger than the part size
+func (c *Client) PutObject(ctx context.Context, key string, r io.Reader, contentType string) error {
+	first, err := readPart(r, c.config.PartSize)
+	if err != nil {
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+	if int64(len(first)) < c.config.PartSize {
+		header := http.Header{}
+		c.contentHeaders(header, contentType)
+		resp, err := c.do(ctx, &request{method: http.MethodPut, key: key, header: header, body: first})
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+	return c.putMultipart(ctx, key, first, r, contentType)
+}
+
+// GetObject opens an object; the caller closes it
+func (c *Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, &request{method: http.MethodGet, key: key})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return resp.Body, nil
+}
+
+// HeadObject describes an object
+func (c *Client) HeadObject(ctx context.Context, key string) (*ObjectInfo, error) {
+	resp, err := c.do(ctx, &request{method: http.MethodHead, key: key})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	resp.Body.Close()
+	info := &ObjectInfo{Key: key, Size: resp.ContentLength}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = modified
+	}
+	return info, nil
+}
+
+// GetObjectFrom opens an object from an offset to its end; the caller closes it
+func (c *Client) GetObjectFrom(ctx context.Context, key string, offset int64) (io.ReadCloser, error) {
+	if offset == 0 {
+		return c.GetObject(ctx, key)
+	}
+	header := http.Header{}
+	header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	resp, err := c.do(ctx, &request{method: http.MethodGet, key: key, header: header, success: http.StatusPartialContent})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return resp.Body, nil
+}
+
+// DeleteObject deletes an object; deleting a missing object succeeds
+func (c *Client) DeleteObject(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, &request{method: http.MethodDelete, key: key, success: http.StatusNoContent})
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// ListObjects calls fn for every object under the configured prefix
+func (c *Client) ListObjects(ctx context.Context, fn func(*ObjectInfo) error) error {
+	var result struct {
+		Contents              []*ObjectInfo `xml:"Contents"`
+		IsTruncated           bool          `xml:"IsTruncated"`
+		NextContinuationToken string        `xml:"NextContinuationToken"`
+	}
+	query := url.Values{"list-type": {"2"}}
+	if c.config.Prefix != "" {
+		query.Set("prefix", c.config.Prefix+"/")
+	}
+	for {
+		result.Contents, result.IsTruncated, result.NextContinuationToken = nil, false, ""
+		if err := c.doXML(ctx, &request{method: http.MethodGet, query: query}, &result); err != nil {
+			return err
+		}
+		for _, obj := range result.Contents {
+			if err := fn(obj); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// putMultipart uploads first and the rest of r in parts, aborting the upload
+// on failure so no part is left billed
+func (c *Client) putMultipart(ctx context.Context, key string, first []byte, r io.Reader, contentType string) error {
+	header := http.Header{}
+	c.contentHeaders(header, contentType)
+	var initiated struct {
+		UploadID string `xml:"UploadId"`
+	}
+	err := c.doXML(ctx, &request{method: http.MethodPost, key: key, query: url.Values{"uploads": {""}}, header: header}, &initiated)
+	if err != nil {
+		return fmt.Errorf("failed to start multipart upload: %w", err)
+	}
+
+	parts, err := c.uploadParts(ctx, key, initiated.UploadID, first, r)
+	if err == nil {
+		err = c.completeMultipart(ctx, key, initiated.UploadID, parts)
+	}
+	if err != nil {
+		abort := &request{method: http.MethodDelete, key: key, query: url.Values{"uploadId": {initiated.UploadID}}, success: http.StatusNoContent}
+		if resp, abortErr := c.do(context.WithoutCancel(ctx), abort); abortErr == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+	return nil
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+func (c *Client) uploadParts(ctx context.Context, key, uploadID string, part []byte, r io.Reader) ([]completedPart, error) {
+	var parts []completedPart
+	for number := 1; len(part) > 0; number++ {
+		query := url.Values{
+			"partNumber": {strconv.Itoa(number)},
+			"uploadId":   {uploadID},
+		}
+		resp, err := c.do(ctx, &request{method: http.MethodPut, key: key, query: query, body: part})
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload part %d: %w", number, err)
+		}
+		resp.Body.Close()
+		parts = append(parts, completedPart{PartNumber: number, ETag: resp.Header.Get("ETag")})
+
+		if part, err = readPart(r, c.config.PartSize); err != nil {
+			return nil, fmt.Errorf("failed to read upload: %w", err)
+		}
+	}
+	return parts, nil
+}
+
+func (c *Client) completeMultipart(ctx context.Context, key, uploadID string, parts []completedPart) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	// S3 may report a failed completion in the body of a 200 response
+	var result struct {
+		XMLName xml.Name
+		Error
+	}
+	req := &request{method: http.MethodPost, key: key, query: url.Values{"uploadId": {uploadID}}, body: body}
+	if err := c.doXML(ctx, req, &result); err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	if result.XMLName.Local == "Error" {
+		result.Status = http.StatusOK
+		return fmt.Errorf("failed to complete multipart upload: %w", &result.Error)
+	}
+	return nil
+}
+
+func (c *Client) doXML(ctx context.Context, req *request, out any) error {
+	resp, err := c.do(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return xml.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) contentHeaders(header http.Header, contentType string) {
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	c.encryptionHeaders(header)
+}
+
+// readPart reads up to size bytes, fewer only at the end of r
+func readPart(r io.Reader, size int64) ([]byte, error) {
+	var buf bytes.Buffer
+	_, err := io.CopyN(&buf, r, size)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func notFound(err error) error {
+	var s3Err *Error
+	if errors.As(err, &s3Err) && s3Err.Status == http.StatusNotFound {
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	return err
+}
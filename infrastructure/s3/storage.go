@@ -0,0 +1,39 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"path"
+
+	"github.com/anhvanhoa/service-core/domain/storage"
+)
+
+type storageService struct {
+	client *Client
+}
+
+// NewStorageService creates a storage service keeping files in an S3 bucket;
+// the returned URLs are the object URLs under the configured public URL
+func NewStorageService(client *Client) storage.StorageI {
+	return &storageService{client: client}
+}
+
+func (s *storageService) Upload(ctx context.Context, req *storage.UploadRequest) (string, error) {
+	if req.OutputPath == "" {
+		return "", fmt.Errorf("output path is required")
+	}
+	key := s.client.ObjectKey(req.OutputPath)
+	if err := s.client.PutObject(ctx, key, req.FileData, mime.TypeByExtension(path.Ext(key))); err != nil {
+		return "", fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return s.client.URL(key), nil
+}
+
+func (s *storageService) Delete(ctx context.Context, url string) error {
+	key, err := s.client.Key(url)
+	if err != nil {
+		return err
+	}
+	return s.client.DeleteObject(ctx, key)
+}
@@ -0,0 +1,48 @@
+package svg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+	"media-service/domain/svg"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+type rsvgRasterizer struct{}
+
+// NewRsvgRasterizer creates a rasterizer backed by the librsvg rsvg-convert binary
+func NewRsvgRasterizer() svg.Rasterizer {
+	return &rsvgRasterizer{}
+}
+
+func (r *rsvgRasterizer) Rasterize(ctx context.Context, path string, width, height int) (image.Image, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "rsvg-convert",
+		"--format", "png",
+		"--width", strconv.Itoa(width),
+		"--height", strconv.Itoa(height),
+		path,
+	)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("rsvg-convert: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return png.Decode(bytes.NewReader(out))
+}
+
+type noopRasterizer struct{}
+
+// NewNoopRasterizer creates a rasterizer for deployments without librsvg;
+// SVGs are stored without previews
+func NewNoopRasterizer() svg.Rasterizer {
+	return &noopRasterizer{}
+}
+
+func (n *noopRasterizer) Rasterize(ctx context.Context, path string, width, height int) (image.Image, error) {
+	return nil, fmt.Errorf("svg rasterization is disabled")
+}
@@ -0,0 +1,43 @@
+package task_queue
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"media-service/domain/task"
+
+	"github.com/hibiken/asynq"
+)
+
+type enqueuer struct {
+	client *asynq.Client
+}
+
+// NewEnqueuer creates a task enqueuer backed by asynq
+func NewEnqueuer(config RedisConfig) task.Enqueuer {
+	return &enqueuer{client: asynq.NewClient(config.connOpt())}
+}
+
+func (e *enqueuer) Enqueue(ctx context.Context, taskType string, payload any, opts task.Options) (string, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode %s payload: %w", taskType, err)
+	}
+
+	var options []asynq.Option
+	if opts.Queue != "" {
+		options = append(options, asynq.Queue(opts.Queue))
+	}
+	if opts.MaxRetry > 0 {
+		options = append(options, asynq.MaxRetry(opts.MaxRetry))
+	}
+	if opts.Delay > 0 {
+		options = append(options, asynq.ProcessIn(opts.Delay))
+	}
+
+	info, err := e.client.EnqueueContext(ctx, asynq.NewTask(taskType, data), options...)
+	if err != nil {
+		return "", err
+	}
+	return info.ID, nil
+}
@@ -0,0 +1,68 @@
+package task_queue
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+// throttleDelay postpones the tasks of an owner holding its whole share
+const throttleDelay = 5 * time.Second
+
+// errThrottled postpones a task without counting it as a failed attempt
+var errThrottled = errors.New("owner share of the worker capacity in use")
+
+// ownerLimiter caps the tasks of one owner processed at once by a server
+type ownerLimiter struct {
+	limit  int
+	mu     sync.Mutex
+	active map[string]int
+}
+
+func newOwnerLimiter(limit int) *ownerLimiter {
+	return &ownerLimiter{limit: limit, active: make(map[string]int)}
+}
+
+func (l *ownerLimiter) acquire(owner string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[owner] >= l.limit {
+		return false
+	}
+	l.active[owner]++
+	return true
+}
+
+func (l *ownerLimiter) release(owner string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[owner]--; l.active[owner] <= 0 {
+		delete(l.active, owner)
+	}
+}
+
+// taskOwner reads the owner of a payload; tasks without one are not limited
+func taskOwner(payload []byte) string {
+	var p struct {
+		Owner string `json:"owner"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return ""
+	}
+	return p.Owner
+}
+
+// retryDelay retries throttled tasks shortly, other failures with asynq's backoff
+func retryDelay(n int, err error, t *asynq.Task) time.Duration {
+	if errors.Is(err, errThrottled) {
+		return throttleDelay
+	}
+	return asynq.DefaultRetryDelayFunc(n, err, t)
+}
+
+func isFailure(err error) bool {
+	return !errors.Is(err, errThrottled)
+}
@@ -0,0 +1,84 @@
+package task_queue
+
+import (
+	"context"
+	"errors"
+	"media-service/domain/task"
+	"slices"
+
+	"github.com/hibiken/asynq"
+)
+
+type inspector struct {
+	inspector *asynq.Inspector
+}
+
+// NewInspector creates a task inspector backed by asynq
+func NewInspector(config RedisConfig) task.Inspector {
+	return &inspector{inspector: asynq.NewInspector(config.connOpt())}
+}
+
+func (i *inspector) ListDead(ctx context.Context, queue string, page, size int) ([]*task.DeadTask, error) {
+	infos, err := i.inspector.ListArchivedTasks(queue, asynq.Page(page), asynq.PageSize(size))
+	if errors.Is(err, asynq.ErrQueueNotFound) {
+		return nil, nil // No task was ever enqueued
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*task.DeadTask, len(infos))
+	for n, info := range infos {
+		tasks[n] = &task.DeadTask{
+			ID:       info.ID,
+			Queue:    info.Queue,
+			Type:     info.Type,
+			Payload:  info.Payload,
+			Error:    info.LastErr,
+			Retried:  info.Retried,
+			FailedAt: info.LastFailedAt,
+		}
+	}
+	return tasks, nil
+}
+
+func (i *inspector) Requeue(ctx context.Context, queue, id string) error {
+	return taskError(i.inspector.RunTask(queue, id))
+}
+
+func (i *inspector) Delete(ctx context.Context, queue, id string) error {
+	return taskError(i.inspector.DeleteTask(queue, id))
+}
+
+func (i *inspector) Stats(ctx context.Context, queue string) (*task.QueueStats, error) {
+	// Queues are created by their first task
+	queues, err := i.inspector.Queues()
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(queues, queue) {
+		return &task.QueueStats{Queue: queue}, nil
+	}
+
+	info, err := i.inspector.GetQueueInfo(queue)
+	if err != nil {
+		return nil, err
+	}
+	return &task.QueueStats{
+		Queue:     queue,
+		Size:      info.Size,
+		Pending:   info.Pending,
+		Active:    info.Active,
+		Scheduled: info.Scheduled,
+		Retry:     info.Retry,
+		Archived:  info.Archived,
+	}, nil
+}
+
+// taskError maps the missing queue and task errors of asynq to task.ErrTaskNotFound
+func taskError(err error) error {
+	if errors.Is(err, asynq.ErrQueueNotFound) || errors.Is(err, asynq.ErrTaskNotFound) {
+		return task.ErrTaskNotFound
+	}
+	return err
+}
@@ -0,0 +1,22 @@
+package task_queue
+
+import (
+	"github.com/hibiken/asynq"
+)
+
+// RedisConfig locates the Redis instance backing the task queues
+type RedisConfig struct {
+	Addr     string
+	Network  string
+	Password string
+	DB       int
+}
+
+func (c RedisConfig) connOpt() asynq.RedisClientOpt {
+	return asynq.RedisClientOpt{
+		Network:  c.Network,
+		Addr:     c.Addr,
+		Password: c.Password,
+		DB:       c.DB,
+	}
+}
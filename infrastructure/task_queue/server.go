@@ -0,0 +1,70 @@
+package task_queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"media-service/domain/task"
+
+	"github.com/hibiken/asynq"
+)
+
+// Server consumes tasks from the queues and dispatches them to their handlers
+type Server struct {
+	server *asynq.Server
+	mux    *asynq.ServeMux
+	owners *ownerLimiter
+}
+
+// NewServer creates a task server processing up to concurrency tasks at once,
+// taking from the queues in proportion to their weight. When ownerLimit is
+// positive, at most ownerLimit tasks of one owner run at once; the others are
+// postponed without using up their retries.
+func NewServer(config RedisConfig, concurrency int, queues map[string]int, ownerLimit int) *Server {
+	s := &Server{
+		server: asynq.NewServer(config.connOpt(), asynq.Config{
+			Concurrency:    concurrency,
+			Queues:         queues,
+			RetryDelayFunc: retryDelay,
+			IsFailure:      isFailure,
+		}),
+		mux: asynq.NewServeMux(),
+	}
+	if ownerLimit > 0 {
+		s.owners = newOwnerLimiter(ownerLimit)
+	}
+	return s
+}
+
+// Handle registers the handler of a task type
+func (s *Server) Handle(taskType string, handler task.Handler) {
+	s.mux.HandleFunc(taskType, func(ctx context.Context, t *asynq.Task) error {
+		owner := taskOwner(t.Payload())
+		if s.owners == nil || owner == "" {
+			return skipRetry(handler(ctx, t.Payload()))
+		}
+		if !s.owners.acquire(owner) {
+			return errThrottled
+		}
+		defer s.owners.release(owner)
+		return skipRetry(handler(ctx, t.Payload()))
+	})
+}
+
+// skipRetry archives the tasks failing with task.ErrSkipRetry without retrying them
+func skipRetry(err error) error {
+	if errors.Is(err, task.ErrSkipRetry) {
+		return fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+	return err
+}
+
+// Start begins consuming tasks in the background
+func (s *Server) Start() error {
+	return s.server.Start(s.mux)
+}
+
+// Shutdown waits for the active tasks and stops the server
+func (s *Server) Shutdown() {
+	s.server.Shutdown()
+}
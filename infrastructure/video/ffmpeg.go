@@ -0,0 +1,245 @@
+package video
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"media-service/domain/entity"
+	"media-service/domain/video"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Codecs a WebM file may carry; other Matroska files are rejected
+var webmCodecs = map[string]bool{"vp8": true, "vp9": true, "av1": true}
+
+type sideData struct {
+	Rotation float64 `json:"rotation"`
+}
+
+type probeOutput struct {
+	Streams []struct {
+		CodecName string            `json:"codec_name"`
+		Width     int               `json:"width"`
+		Height    int               `json:"height"`
+		Tags      map[string]string `json:"tags"`
+		SideData  []sideData        `json:"side_data_list"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string            `json:"format_name"`
+		Duration   string            `json:"duration"`
+		Tags       map[string]string `json:"tags"`
+	} `json:"format"`
+}
+
+type ffmpegProber struct{}
+
+// NewFFmpegProber creates a video prober backed by the ffprobe binary
+func NewFFmpegProber() video.Prober {
+	return &ffmpegProber{}
+}
+
+func (p *ffmpegProber) Probe(ctx context.Context, path string) (*video.Info, error) {
+	out, err := run(ctx, "ffprobe",
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format", "-show_streams",
+		"-select_streams", "v:0",
+		path,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid video: %w", err)
+	}
+	var probe probeOutput
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("invalid ffprobe output: %w", err)
+	}
+	if len(probe.Streams) == 0 {
+		return nil, fmt.Errorf("invalid video: no video stream")
+	}
+
+	stream := probe.Streams[0]
+	info := &video.Info{Width: stream.Width, Height: stream.Height, Codec: stream.CodecName}
+	info.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	if rotated(stream.Tags["rotate"], stream.SideData) {
+		info.Width, info.Height = info.Height, info.Width
+	}
+
+	switch {
+	case strings.Contains(probe.Format.FormatName, "mp4"):
+		info.MimeType, info.Ext = string(entity.MimeTypeVideo), entity.ExtMP4
+		if strings.TrimSpace(probe.Format.Tags["major_brand"]) == "qt" {
+			info.MimeType, info.Ext = string(entity.MimeTypeMOV), entity.ExtMOV
+		}
+	case strings.Contains(probe.Format.FormatName, "webm") && webmCodecs[stream.CodecName]:
+		info.MimeType, info.Ext = string(entity.MimeTypeWebM), entity.ExtWebM
+	default:
+		return nil, fmt.Errorf("%w: %s video in %s container", video.ErrUnsupported, stream.CodecName, probe.Format.FormatName)
+	}
+	return info, nil
+}
+
+// rotated reports whether the display matrix turns the video by a quarter turn
+func rotated(tag string, sideData []sideData) bool {
+	rotation, _ := strconv.ParseFloat(tag, 64)
+	for _, data := range sideData {
+		if data.Rotation != 0 {
+			rotation = data.Rotation
+		}
+	}
+	return int(rotation)%180 != 0
+}
+
+type ffmpegExtractor struct{}
+
+// NewFFmpegExtractor creates a frame extractor backed by the ffmpeg binary;
+// frames are rotated per the display matrix
+func NewFFmpegExtractor() video.FrameExtractor {
+	return &ffmpegExtractor{}
+}
+
+func (e *ffmpegExtractor) ExtractFrame(ctx context.Context, path string, at float64) (image.Image, error) {
+	out, err := run(ctx, "ffmpeg",
+		"-v", "error",
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", path,
+		"-frames:v", "1",
+		"-f", "image2pipe", "-vcodec", "png",
+		"-",
+	)
+	if err != nil {
+		return nil, err
+	}
+	return png.Decode(bytes.NewReader(out))
+}
+
+func (e *ffmpegExtractor) ExtractFrames(ctx context.Context, path string, interval float64, width, height int) ([]image.Image, error) {
+	out, err := run(ctx, "ffmpeg",
+		"-v", "error",
+		"-i", path,
+		"-vf", fmt.Sprintf("fps=1/%s,scale=%d:%d", strconv.FormatFloat(interval, 'f', -1, 64), width, height),
+		"-f", "image2pipe", "-vcodec", "png",
+		"-",
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The frames are concatenated PNG files
+	var frames []image.Image
+	reader := bufio.NewReader(bytes.NewReader(out))
+	for {
+		if _, err := reader.Peek(1); err == io.EOF {
+			return frames, nil
+		}
+		frame, err := png.Decode(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode frame %d: %w", len(frames), err)
+		}
+		frames = append(frames, frame)
+	}
+}
+
+type ffmpegClipper struct{}
+
+// NewFFmpegClipper creates a clipper backed by the ffmpeg binary
+func NewFFmpegClipper() video.Clipper {
+	return &ffmpegClipper{}
+}
+
+func (c *ffmpegClipper) Clip(ctx context.Context, src, dst string, opts video.ClipOptions) error {
+	start := strconv.FormatFloat(opts.Start, 'f', 3, 64)
+	duration := strconv.FormatFloat(opts.End-opts.Start, 'f', 3, 64)
+
+	args := []string{"-v", "error", "-y"}
+	if !opts.Accurate {
+		// Seeking before the input snaps to the preceding keyframe, which the
+		// copied streams need to start decoding
+		args = append(args,
+			"-ss", start,
+			"-i", src,
+			"-t", duration,
+			"-map", "0:v:0", "-map", "0:a?",
+			"-c", "copy",
+			"-avoid_negative_ts", "make_zero",
+		)
+	} else {
+		// Seeking after the input decodes up to the exact start frame
+		args = append(args,
+			"-i", src,
+			"-ss", start,
+			"-t", duration,
+			"-map", "0:v:0", "-map", "0:a?",
+			"-c:v", "libx264", "-preset", "medium", "-crf", "20", "-pix_fmt", "yuv420p",
+			"-c:a", "aac", "-b:a", "128k",
+			"-movflags", "+faststart",
+		)
+		if opts.Width > 0 || opts.Height > 0 {
+			args = append(args, "-vf", fmt.Sprintf("scale=%s:%s", scaleSide(opts.Width), scaleSide(opts.Height)))
+		}
+	}
+	args = append(args, dst)
+
+	_, err := run(ctx, "ffmpeg", args...)
+	return err
+}
+
+// scaleSide formats one side of the scale filter; H.264 needs even sizes and
+// -2 keeps the aspect ratio while rounding to one
+func scaleSide(size int) string {
+	if size <= 0 {
+		return "-2"
+	}
+	return strconv.Itoa(size - size%2)
+}
+
+type noopTools struct{}
+
+// NewNoopProber creates a prober for deployments without FFmpeg; video
+// uploads are rejected as unsupported
+func NewNoopProber() video.Prober {
+	return &noopTools{}
+}
+
+// NewNoopExtractor creates a frame extractor for deployments without FFmpeg
+func NewNoopExtractor() video.FrameExtractor {
+	return &noopTools{}
+}
+
+// NewNoopClipper creates a clipper for deployments without FFmpeg
+func NewNoopClipper() video.Clipper {
+	return &noopTools{}
+}
+
+func (n *noopTools) Probe(ctx context.Context, path string) (*video.Info, error) {
+	return nil, fmt.Errorf("%w: video processing is disabled", video.ErrUnsupported)
+}
+
+func (n *noopTools) ExtractFrame(ctx context.Context, path string, at float64) (image.Image, error) {
+	return nil, fmt.Errorf("video frame extraction is disabled")
+}
+
+func (n *noopTools) ExtractFrames(ctx context.Context, path string, interval float64, width, height int) ([]image.Image, error) {
+	return nil, fmt.Errorf("video frame extraction is disabled")
+}
+
+func (n *noopTools) Clip(ctx context.Context, src, dst string, opts video.ClipOptions) error {
+	return fmt.Errorf("video clipping is disabled")
+}
+
+func run(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS ciede2000(
+    DOUBLE PRECISION, DOUBLE PRECISION, DOUBLE PRECISION,
+    DOUBLE PRECISION, DOUBLE PRECISION, DOUBLE PRECISION
+);
+ALTER TABLE media DROP COLUMN IF EXISTS palette;
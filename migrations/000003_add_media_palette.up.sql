@@ -0,0 +1,96 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS palette JSONB;
+
+-- CIEDE2000 color difference, mirrors imaging.CIEDE2000
+CREATE OR REPLACE FUNCTION ciede2000(
+    l1 DOUBLE PRECISION, a1 DOUBLE PRECISION, b1 DOUBLE PRECISION,
+    l2 DOUBLE PRECISION, a2 DOUBLE PRECISION, b2 DOUBLE PRECISION
+)
+RETURNS DOUBLE PRECISION AS $$
+DECLARE
+    pow25_7 CONSTANT DOUBLE PRECISION := 6103515625.0;
+    cbar7 DOUBLE PRECISION;
+    g DOUBLE PRECISION;
+    a1p DOUBLE PRECISION;
+    a2p DOUBLE PRECISION;
+    c1p DOUBLE PRECISION;
+    c2p DOUBLE PRECISION;
+    h1p DOUBLE PRECISION := 0;
+    h2p DOUBLE PRECISION := 0;
+    dlp DOUBLE PRECISION;
+    dcp DOUBLE PRECISION;
+    dhp DOUBLE PRECISION := 0;
+    dhh DOUBLE PRECISION;
+    lbarp DOUBLE PRECISION;
+    cbarp DOUBLE PRECISION;
+    hbarp DOUBLE PRECISION;
+    t DOUBLE PRECISION;
+    d_theta DOUBLE PRECISION;
+    cbarp7 DOUBLE PRECISION;
+    rc DOUBLE PRECISION;
+    lm50 DOUBLE PRECISION;
+    sl DOUBLE PRECISION;
+    sc DOUBLE PRECISION;
+    sh DOUBLE PRECISION;
+    rt DOUBLE PRECISION;
+BEGIN
+    cbar7 := power((sqrt(a1 * a1 + b1 * b1) + sqrt(a2 * a2 + b2 * b2)) / 2, 7);
+    g := 0.5 * (1 - sqrt(cbar7 / (cbar7 + pow25_7)));
+
+    a1p := (1 + g) * a1;
+    a2p := (1 + g) * a2;
+    c1p := sqrt(a1p * a1p + b1 * b1);
+    c2p := sqrt(a2p * a2p + b2 * b2);
+    IF a1p <> 0 OR b1 <> 0 THEN
+        h1p := degrees(atan2(b1, a1p));
+        IF h1p < 0 THEN h1p := h1p + 360; END IF;
+    END IF;
+    IF a2p <> 0 OR b2 <> 0 THEN
+        h2p := degrees(atan2(b2, a2p));
+        IF h2p < 0 THEN h2p := h2p + 360; END IF;
+    END IF;
+
+    dlp := l2 - l1;
+    dcp := c2p - c1p;
+    IF c1p * c2p <> 0 THEN
+        dhp := h2p - h1p;
+        IF dhp > 180 THEN
+            dhp := dhp - 360;
+        ELSIF dhp < -180 THEN
+            dhp := dhp + 360;
+        END IF;
+    END IF;
+    dhh := 2 * sqrt(c1p * c2p) * sin(radians(dhp / 2));
+
+    lbarp := (l1 + l2) / 2;
+    cbarp := (c1p + c2p) / 2;
+    hbarp := h1p + h2p;
+    IF c1p * c2p <> 0 THEN
+        IF abs(h1p - h2p) <= 180 THEN
+            hbarp := hbarp / 2;
+        ELSIF hbarp < 360 THEN
+            hbarp := (hbarp + 360) / 2;
+        ELSE
+            hbarp := (hbarp - 360) / 2;
+        END IF;
+    END IF;
+
+    t := 1
+        - 0.17 * cos(radians(hbarp - 30))
+        + 0.24 * cos(radians(2 * hbarp))
+        + 0.32 * cos(radians(3 * hbarp + 6))
+        - 0.20 * cos(radians(4 * hbarp - 63));
+    d_theta := 30 * exp(-power((hbarp - 275) / 25, 2));
+    cbarp7 := power(cbarp, 7);
+    rc := 2 * sqrt(cbarp7 / (cbarp7 + pow25_7));
+    lm50 := (lbarp - 50) * (lbarp - 50);
+    sl := 1 + 0.015 * lm50 / sqrt(20 + lm50);
+    sc := 1 + 0.045 * cbarp;
+    sh := 1 + 0.015 * cbarp * t;
+    rt := -sin(radians(2 * d_theta)) * rc;
+
+    RETURN sqrt(
+        power(dlp / sl, 2) + power(dcp / sc, 2) + power(dhh / sh, 2)
+        + rt * (dcp / sc) * (dhh / sh)
+    );
+END;
+$$ LANGUAGE plpgsql IMMUTABLE STRICT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_media_phash;
+ALTER TABLE media DROP COLUMN IF EXISTS phash;
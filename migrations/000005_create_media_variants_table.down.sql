@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS update_media_variants_updated_at ON media_variants;
+DROP TABLE IF EXISTS media_variants;
+ALTER TABLE media DROP COLUMN IF EXISTS focal_y;
+ALTER TABLE media DROP COLUMN IF EXISTS focal_x;
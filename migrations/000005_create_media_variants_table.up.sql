@@ -0,0 +1,20 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS focal_x DOUBLE PRECISION;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS focal_y DOUBLE PRECISION;
+
+CREATE TABLE IF NOT EXISTS media_variants (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    media_id uuid NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    url VARCHAR(500),
+    mime_type VARCHAR(100),
+    width INTEGER,
+    height INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (media_id, name)
+);
+
+CREATE INDEX idx_media_variants_media_id ON media_variants(media_id);
+
+CREATE TRIGGER update_media_variants_updated_at BEFORE UPDATE ON media_variants
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
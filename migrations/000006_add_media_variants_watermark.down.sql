@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_media_variants_name_watermark;
+ALTER TABLE media_variants DROP COLUMN IF EXISTS watermark;
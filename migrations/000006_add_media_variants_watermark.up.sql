@@ -0,0 +1,3 @@
+ALTER TABLE media_variants ADD COLUMN IF NOT EXISTS watermark VARCHAR(100) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_media_variants_name_watermark ON media_variants(name, watermark);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_media_content_tsv;
+ALTER TABLE media DROP COLUMN IF EXISTS content_tsv;
+ALTER TABLE media DROP COLUMN IF EXISTS content;
+ALTER TABLE media DROP COLUMN IF EXISTS page_count;
@@ -0,0 +1,6 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS page_count INTEGER;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS content TEXT;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS content_tsv tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(content, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_media_content_tsv ON media USING GIN (content_tsv);
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_media_reprocess_jobs_updated_at ON media_reprocess_jobs;
+DROP TABLE IF EXISTS media_reprocess_jobs;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS media_reprocess_jobs (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    requested_by VARCHAR(255) NOT NULL,
+    filters JSONB,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    total INTEGER NOT NULL DEFAULT 0,
+    processed INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_media_reprocess_jobs_requested_by ON media_reprocess_jobs(requested_by);
+
+CREATE TRIGGER update_media_reprocess_jobs_updated_at BEFORE UPDATE ON media_reprocess_jobs
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS media_pipeline_runs (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    media_id uuid NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    family VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    steps JSONB NOT NULL DEFAULT '[]',
+    started_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_media_pipeline_runs_media_id ON media_pipeline_runs(media_id, started_at DESC);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_media_misoriented;
+ALTER TABLE media DROP COLUMN IF EXISTS misoriented;
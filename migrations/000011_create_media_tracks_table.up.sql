@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS media_tracks (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    media_id uuid NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    language VARCHAR(35) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    mime_type VARCHAR(100),
+    created_by VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_media_tracks_media_id ON media_tracks(media_id);
+
+CREATE TRIGGER update_media_tracks_updated_at BEFORE UPDATE ON media_tracks
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS media_clip_jobs;
+
+DROP INDEX IF EXISTS idx_media_source_media_id;
+ALTER TABLE media DROP COLUMN IF EXISTS source_media_id;
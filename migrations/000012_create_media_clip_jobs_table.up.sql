@@ -0,0 +1,25 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS source_media_id uuid REFERENCES media(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_media_source_media_id ON media(source_media_id) WHERE source_media_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS media_clip_jobs (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_media_id uuid NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    clip_media_id uuid REFERENCES media(id) ON DELETE SET NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    start_time DOUBLE PRECISION NOT NULL DEFAULT 0,
+    end_time DOUBLE PRECISION NOT NULL DEFAULT 0,
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    mode VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_media_clip_jobs_requested_by ON media_clip_jobs(requested_by);
+
+CREATE TRIGGER update_media_clip_jobs_updated_at BEFORE UPDATE ON media_clip_jobs
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
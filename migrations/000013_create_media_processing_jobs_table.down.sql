@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS media_processing_jobs;
+
+ALTER TABLE media DROP COLUMN IF EXISTS last_error;
+ALTER TABLE media DROP COLUMN IF EXISTS attempts;
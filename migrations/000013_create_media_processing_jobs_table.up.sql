@@ -0,0 +1,16 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS last_error TEXT;
+
+CREATE TABLE IF NOT EXISTS media_processing_jobs (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    media_id uuid NOT NULL REFERENCES media(id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL DEFAULT 0,
+    trigger VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    error TEXT,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP WITH TIME ZONE,
+    duration_ms BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_media_processing_jobs_media_id ON media_processing_jobs(media_id, started_at DESC);
@@ -0,0 +1 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS priority VARCHAR(20) NOT NULL DEFAULT 'interactive';
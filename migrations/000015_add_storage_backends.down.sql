@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS update_media_storage_migrations_updated_at ON media_storage_migrations;
+DROP TABLE IF EXISTS media_storage_migrations;
+
+DROP INDEX IF EXISTS idx_media_tracks_storage_backend;
+DROP INDEX IF EXISTS idx_media_variants_storage_backend;
+DROP INDEX IF EXISTS idx_media_storage_backend;
+
+ALTER TABLE media_tracks DROP COLUMN IF EXISTS storage_backend;
+ALTER TABLE media_variants DROP COLUMN IF EXISTS storage_backend;
+ALTER TABLE media DROP COLUMN IF EXISTS storage_backend;
@@ -0,0 +1,29 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS storage_backend VARCHAR(50) NOT NULL DEFAULT 'local';
+ALTER TABLE media_variants ADD COLUMN IF NOT EXISTS storage_backend VARCHAR(50) NOT NULL DEFAULT 'local';
+ALTER TABLE media_tracks ADD COLUMN IF NOT EXISTS storage_backend VARCHAR(50) NOT NULL DEFAULT 'local';
+
+CREATE INDEX idx_media_storage_backend ON media(storage_backend);
+CREATE INDEX idx_media_variants_storage_backend ON media_variants(storage_backend);
+CREATE INDEX idx_media_tracks_storage_backend ON media_tracks(storage_backend);
+
+CREATE TABLE IF NOT EXISTS media_storage_migrations (
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    requested_by VARCHAR(255) NOT NULL,
+    source VARCHAR(50) NOT NULL,
+    target VARCHAR(50) NOT NULL,
+    delete_source BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    last_media_id VARCHAR(64) NOT NULL DEFAULT '',
+    total INTEGER NOT NULL DEFAULT 0,
+    migrated INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_media_storage_migrations_requested_by ON media_storage_migrations(requested_by);
+
+CREATE TRIGGER update_media_storage_migrations_updated_at BEFORE UPDATE ON media_storage_migrations
+FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_media_last_accessed_at;
+DROP INDEX IF EXISTS idx_media_storage_tier;
+
+ALTER TABLE media DROP COLUMN IF EXISTS last_accessed_at;
+ALTER TABLE media DROP COLUMN IF EXISTS tier_updated_at;
+ALTER TABLE media DROP COLUMN IF EXISTS tier_status;
+ALTER TABLE media DROP COLUMN IF EXISTS storage_tier;
@@ -0,0 +1,7 @@
+ALTER TABLE media ADD COLUMN IF NOT EXISTS storage_tier VARCHAR(50) NOT NULL DEFAULT 'standard';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS tier_status VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE media ADD COLUMN IF NOT EXISTS tier_updated_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS last_accessed_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_media_storage_tier ON media(storage_tier);
+CREATE INDEX idx_media_last_accessed_at ON media(last_accessed_at);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_media_phash ON media(phash);
@@ -0,0 +1,2 @@
+-- A btree index cannot serve the Hamming distance predicate of the similarity search
+DROP INDEX IF EXISTS idx_media_phash;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_media_phash_band3;
+DROP INDEX IF EXISTS idx_media_phash_band2;
+DROP INDEX IF EXISTS idx_media_phash_band1;
+DROP INDEX IF EXISTS idx_media_phash_band0;
+
+ALTER TABLE media DROP COLUMN IF EXISTS phash_band3;
+ALTER TABLE media DROP COLUMN IF EXISTS phash_band2;
+ALTER TABLE media DROP COLUMN IF EXISTS phash_band1;
+ALTER TABLE media DROP COLUMN IF EXISTS phash_band0;
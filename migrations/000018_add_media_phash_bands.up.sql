@@ -0,0 +1,10 @@
+-- 16-bit bands of the perceptual hash, from the lowest, for multi-index search
+ALTER TABLE media ADD COLUMN IF NOT EXISTS phash_band0 INTEGER GENERATED ALWAYS AS (phash & 65535) STORED;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS phash_band1 INTEGER GENERATED ALWAYS AS ((phash >> 16) & 65535) STORED;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS phash_band2 INTEGER GENERATED ALWAYS AS ((phash >> 32) & 65535) STORED;
+ALTER TABLE media ADD COLUMN IF NOT EXISTS phash_band3 INTEGER GENERATED ALWAYS AS ((phash >> 48) & 65535) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_media_phash_band0 ON media(phash_band0);
+CREATE INDEX IF NOT EXISTS idx_media_phash_band1 ON media(phash_band1);
+CREATE INDEX IF NOT EXISTS idx_media_phash_band2 ON media(phash_band2);
+CREATE INDEX IF NOT EXISTS idx_media_phash_band3 ON media(phash_band3);